@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const apiKeyPrefix = "gws_"
+
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// İstekteki API anahtarını X-API-Key veya "Authorization: ApiKey ..." başlığından okur
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+
+	if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "ApiKey ") {
+		return strings.TrimPrefix(authHeader, "ApiKey ")
+	}
+
+	return ""
+}
+
+// API anahtarını doğrular ve anahtarın sahibi adına Claims oluşturur. Kullanıcının rolü ve tenant'ı her istekte veritabanından okunur
+func authenticateAPIKey(rawKey string) (*Claims, error) {
+	key, err := models.GetAPIKeyByHash(hashToken(rawKey))
+	if err != nil {
+		return nil, err
+	}
+
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(time.Now()) {
+		return nil, models.ErrAPIKeyNotFound
+	}
+
+	user, err := models.GetUserByID(key.UserID, models.AllTenants)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := models.TouchAPIKey(key.ID); err != nil {
+		log.Println("API anahtarının son kullanım zamanı güncellenemedi:", err)
+	}
+
+	return &Claims{
+		UserID:   user.ID,
+		TenantID: user.TenantID,
+		Username: user.Username,
+		Role:     user.Role,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// API anahtarları ve oturumlar sadece kullanıcı girişiyle alınan token ile yönetilebilir; API anahtarı,
+// istemci sertifikası, partner imzası veya OAuth istemcisine verilen token ile gelen istekler yeni anahtar oluşturamaz,
+// 2FA ve oturum ayarlarını değiştiremez, çıkış yapamaz
+func rejectMachineAuth(c *gin.Context, claims *Claims) bool {
+	if claims.APIKeyID != 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "BU İŞLEM API ANAHTARI İLE YAPILAMAZ"})
+		return true
+	}
+	if claims.ClientCert != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "BU İŞLEM İSTEMCİ SERTİFİKASI İLE YAPILAMAZ"})
+		return true
+	}
+	if claims.PartnerID != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "BU İŞLEM PARTNER İMZASI İLE YAPILAMAZ"})
+		return true
+	}
+	if claims.ClientID != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "BU İŞLEM OAUTH İSTEMCİSİ İLE YAPILAMAZ"})
+		return true
+	}
+	return false
+}
+
+// Anahtarın kapsamları, isteği yapan token'ın kapsamlarını aşamaz
+func bindAPIKeyRequest(c *gin.Context, claims *Claims) (APIKeyRequest, bool) {
+	var req APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return req, false
+	}
+
+	if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "SON KULLANMA TARİHİ GEÇMİŞTE OLAMAZ"})
+		return req, false
+	}
+
+	scopes, err := narrowScopes(claims.Scopes, req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ SCOPE"})
+		return req, false
+	}
+	req.Scopes = scopes
+
+	return req, true
+}
+
+// @Summary List API keys
+// @Description Lists the API keys of the logged in user. The keys themselves are never shown again after creation
+// @Tags apikey
+// @Produce json
+// @Router /apikey [get]
+func GetAPIKeys(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+
+	keys, err := models.GetAPIKeys(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarları alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": keys})
+}
+
+// @Summary Create an API key
+// @Description Creates a named API key for the logged in user. The key is returned only once; send it in the X-API-Key header or as "Authorization: ApiKey <key>"
+// @Tags apikey
+// @Accept json
+// @Produce json
+// @Param input body APIKeyRequest true "API key"
+// @Router /apikey [post]
+func CreateAPIKey(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	req, ok := bindAPIKeyRequest(c, claims)
+	if !ok {
+		return
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı oluşturulamadı"})
+		return
+	}
+
+	rawKey := apiKeyPrefix + secret
+	key := models.APIKey{
+		UserID:    claims.UserID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    rawKey[:len(apiKeyPrefix)+8],
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	id, err := models.CreateAPIKey(key, hashToken(rawKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı oluşturulamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":         id,
+		"name":       key.Name,
+		"key":        rawKey,
+		"prefix":     key.Prefix,
+		"scopes":     key.Scopes,
+		"expires_at": key.ExpiresAt,
+	})
+}
+
+// @Summary Update an API key
+// @Description Changes the name, scopes or expiry of an API key of the logged in user
+// @Tags apikey
+// @Accept json
+// @Produce json
+// @Param id path int true "API key ID"
+// @Param input body APIKeyRequest true "API key"
+// @Router /apikey/{id} [put]
+func UpdateAPIKey(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz API Anahtarı ID'si"})
+		return
+	}
+
+	req, ok := bindAPIKeyRequest(c, claims)
+	if !ok {
+		return
+	}
+
+	err = models.UpdateAPIKey(models.APIKey{ID: id, UserID: claims.UserID, Name: strings.TrimSpace(req.Name), Scopes: req.Scopes, ExpiresAt: req.ExpiresAt})
+	if err != nil {
+		if err == models.ErrAPIKeyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API Anahtarı Bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı güncellenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API anahtarı güncellendi"})
+}
+
+// @Summary Delete an API key
+// @Description Deletes (revokes) an API key of the logged in user
+// @Tags apikey
+// @Produce json
+// @Param id path int true "API key ID"
+// @Router /apikey/{id} [delete]
+func DeleteAPIKey(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz API Anahtarı ID'si"})
+		return
+	}
+
+	if err := models.DeleteAPIKey(id, claims.UserID); err != nil {
+		if err == models.ErrAPIKeyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API Anahtarı Bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "API anahtarı silinemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API anahtarı silindi"})
+}
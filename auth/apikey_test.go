@@ -0,0 +1,102 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"example.com/webservice/auth"
+)
+
+func TestAPIKeyAuth(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/apikey", auth.TokenAuthMiddleware(), auth.CreateAPIKey)
+	r.DELETE("/apikey/:id", auth.TokenAuthMiddleware(), auth.DeleteAPIKey)
+
+	token := login(t, r, "test", "gizli1234")["token"].(string)
+
+	w, resp := postJSONWithToken(r, "/apikey", token, map[string]interface{}{"name": "batch", "scopes": []string{"person:read"}})
+	key, _ := resp["key"].(string)
+	if w.Code != http.StatusOK || key == "" {
+		t.Fatalf("API anahtarı oluşturulamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	request := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodGet, "/secured", nil)
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request("X-API-Key", key); code != http.StatusOK {
+		t.Errorf("X-API-Key ile erişilemedi. Kod: %d", code)
+	}
+
+	if code := request("Authorization", "ApiKey "+key); code != http.StatusOK {
+		t.Errorf("Authorization: ApiKey ile erişilemedi. Kod: %d", code)
+	}
+
+	if code := request("X-API-Key", key+"x"); code != http.StatusUnauthorized {
+		t.Errorf("Geçersiz anahtar kabul edildi. Kod: %d", code)
+	}
+
+	// Bir API anahtarı yeni anahtar oluşturamaz
+	req := httptest.NewRequest(http.MethodPost, "/apikey", nil)
+	req.Header.Set("X-API-Key", key)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("API anahtarı ile yeni anahtar oluşturulabildi. Kod: %d", w.Code)
+	}
+
+	// Süresi geçmiş anahtar oluşturulamaz
+	if w, _ := postJSONWithToken(r, "/apikey", token, map[string]interface{}{"name": "eski", "expires_at": time.Now().Add(-time.Hour)}); w.Code != http.StatusBadRequest {
+		t.Errorf("Süresi geçmiş anahtar oluşturuldu. Kod: %d", w.Code)
+	}
+
+	// Bir API anahtarı anahtar silemez
+	req = httptest.NewRequest(http.MethodDelete, "/apikey/1", nil)
+	req.Header.Set("X-API-Key", key)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("API anahtarı ile anahtar silinebildi. Kod: %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/apikey/1", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("API anahtarı silinemedi. Kod: %d", w.Code)
+	}
+
+	if code := request("X-API-Key", key); code != http.StatusUnauthorized {
+		t.Errorf("Silinen anahtar kabul edildi. Kod: %d", code)
+	}
+}
+
+func TestInvalidateUserSessionsRevokesAPIKeys(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/apikey", auth.TokenAuthMiddleware(), auth.CreateAPIKey)
+
+	token := login(t, r, "test", "gizli1234")["token"].(string)
+	_, resp := postJSONWithToken(r, "/apikey", token, map[string]interface{}{"name": "batch"})
+	key, _ := resp["key"].(string)
+
+	if err := auth.InvalidateUserSessions(1); err != nil {
+		t.Fatalf("Oturumlar iptal edilemedi: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/secured", nil)
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Oturumları iptal edilen kullanıcının API anahtarı kabul edildi. Kod: %d", w.Code)
+	}
+}
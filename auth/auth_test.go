@@ -0,0 +1,201 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+
+	if err := models.OpenDatabase(":memory:"); err != nil {
+		t.Fatalf("Test veritabanı açılamadı: %v", err)
+	}
+	models.DB.SetMaxOpenConns(1)
+	auth.ResetRevocationCache()
+
+	if _, err := models.CreateUser(models.User{Username: "test", Email: "test@test.com", Password: "gizli1234", TenantID: models.DefaultTenantID}); err != nil {
+		t.Fatalf("Kullanıcı eklenemedi: %v", err)
+	}
+}
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/login", auth.Login)
+	r.POST("/token/refresh", auth.RefreshToken)
+	r.GET("/secured", auth.TokenAuthMiddleware(), auth.SecuredEndpoint)
+	return r
+}
+
+func postJSON(r *gin.Engine, path string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	return postJSONWithToken(r, path, "", body)
+}
+
+func postJSONWithToken(r *gin.Engine, path, token string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func login(t *testing.T, r *gin.Engine, username, password string) map[string]interface{} {
+	t.Helper()
+
+	w, resp := postJSON(r, "/login", map[string]string{"username": username, "password": password})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Giriş yapılamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+	return resp
+}
+
+func TestRefreshTokenRotationAndReuse(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+
+	resp := login(t, r, "test", "gizli1234")
+	first, _ := resp["refresh_token"].(string)
+	if first == "" {
+		t.Fatalf("Login yanıtında refresh token yok: %v", resp)
+	}
+
+	// İlk yenileme başarılı olmalı ve yeni bir refresh token dönmeli
+	w, resp := postJSON(r, "/token/refresh", map[string]string{"refresh_token": first})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Token yenilenemedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	second, _ := resp["refresh_token"].(string)
+	if second == "" || second == first {
+		t.Fatalf("Refresh token döndürülmedi: %v", resp)
+	}
+
+	// Kullanılmış token tekrar gönderildiğinde reddedilmeli
+	w, _ = postJSON(r, "/token/refresh", map[string]string{"refresh_token": first})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Tekrar kullanılan token kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Tekrar kullanım tespit edildiği için aynı ailedeki yeni token da iptal edilmiş olmalı
+	w, _ = postJSON(r, "/token/refresh", map[string]string{"refresh_token": second})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("İptal edilen ailedeki token kabul edildi. Kod: %d", w.Code)
+	}
+}
+
+func getSecured(r *gin.Engine, token string) int {
+	req := httptest.NewRequest(http.MethodGet, "/secured", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestLogoutRevokesToken(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/logout", auth.TokenAuthMiddleware(), auth.Logout)
+
+	resp := login(t, r, "test", "gizli1234")
+	token := resp["token"].(string)
+
+	if code := getSecured(r, token); code != http.StatusOK {
+		t.Fatalf("Geçerli token reddedildi. Kod: %d", code)
+	}
+
+	// Aynı oturumda yenilenen access token
+	_, refreshed := postJSON(r, "/token/refresh", map[string]string{"refresh_token": resp["refresh_token"].(string)})
+	sameSession, _ := refreshed["token"].(string)
+	if code := getSecured(r, sameSession); code != http.StatusOK {
+		t.Fatalf("Yenilenen token reddedildi. Kod: %d", code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Çıkış yapılamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	if code := getSecured(r, token); code != http.StatusUnauthorized {
+		t.Errorf("Çıkış sonrası token kabul edildi. Kod: %d", code)
+	}
+
+	// Oturum sonlandığı için oturumun diğer access token'ları da hemen geçersiz olur
+	if code := getSecured(r, sameSession); code != http.StatusUnauthorized {
+		t.Errorf("Çıkış sonrası aynı oturumun token'ı kabul edildi. Kod: %d", code)
+	}
+}
+
+func TestInvalidateUserSessions(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+
+	resp := login(t, r, "test", "gizli1234")
+	token := resp["token"].(string)
+	refresh := resp["refresh_token"].(string)
+
+	if err := auth.InvalidateUserSessions(1); err != nil {
+		t.Fatalf("Oturumlar iptal edilemedi: %v", err)
+	}
+
+	if code := getSecured(r, token); code != http.StatusUnauthorized {
+		t.Errorf("İptal edilen oturumun token'ı kabul edildi. Kod: %d", code)
+	}
+
+	if w, _ := postJSON(r, "/token/refresh", map[string]string{"refresh_token": refresh}); w.Code != http.StatusUnauthorized {
+		t.Errorf("İptal edilen oturumun refresh token'ı kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Yeni giriş yapılan oturum geçerli olmalı
+	resp = login(t, r, "test", "gizli1234")
+	if code := getSecured(r, resp["token"].(string)); code != http.StatusOK {
+		t.Errorf("Yeni oturumun token'ı reddedildi. Kod: %d", code)
+	}
+}
+
+func TestRevocationFailsClosed(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+
+	token := login(t, r, "test", "gizli1234")["token"].(string)
+
+	// İptal kayıtları yüklenemezse token kabul edilmemeli
+	if _, err := models.DB.Exec("DROP TABLE revoked_token"); err != nil {
+		t.Fatalf("Tablo silinemedi: %v", err)
+	}
+
+	if code := getSecured(r, token); code != http.StatusOK {
+		t.Errorf("TTL içinde yüklenen önbellek kullanılmadı. Kod: %d", code)
+	}
+
+	auth.ExpireRevocationCache()
+	if code := getSecured(r, token); code != http.StatusUnauthorized {
+		t.Errorf("İptal kayıtları yüklenemediği halde token kabul edildi. Kod: %d", code)
+	}
+
+	auth.ResetRevocationCache()
+	if code := getSecured(r, token); code != http.StatusUnauthorized {
+		t.Errorf("Boş önbellekle token kabul edildi. Kod: %d", code)
+	}
+}
@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// Her test yeni bir veritabanı açtığı için önceki testten kalan iptal önbelleği temizlenir
+func ResetRevocationCache() {
+	revocations = &revocationCache{}
+}
+
+// Önbelleğin TTL'i dolmuş gibi bir sonraki istekte yeniden yüklenmesini sağlar
+func ExpireRevocationCache() {
+	revocations.mu.Lock()
+	revocations.loadedAt = time.Time{}
+	revocations.mu.Unlock()
+}
+
+// Başka bir instance'ın iptallerinin görülmesi için eşitleme aralığı geçmiş gibi davranır
+func ExpireRevocationSync() {
+	revocations.mu.Lock()
+	revocations.syncedAt = time.Time{}
+	revocations.mu.Unlock()
+}
+
+// Test sonunda imzalama anahtarlarını testten önceki haline getirir
+func KeepSigningKeys(t *testing.T) {
+	signingKeys.mu.RLock()
+	active, keys := signingKeys.active, signingKeys.keys
+	signingKeys.mu.RUnlock()
+
+	t.Cleanup(func() {
+		signingKeys.mu.Lock()
+		signingKeys.active, signingKeys.keys = active, keys
+		signingKeys.mu.Unlock()
+	})
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const impersonationTTL = 10 * time.Minute
+
+// RFC 8693 "act" claim'i: token'ı kullanıcı adına kullanan admin. Admin'in oturumları iptal edilirse taklit token'ı da geçersiz olur
+type ActorClaim struct {
+	Subject        string `json:"sub"`
+	UserID         int    `json:"user_id"`
+	Username       string `json:"username"`
+	SessionVersion int    `json:"session_version"`
+}
+
+type ImpersonateRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Taklit edilen oturumda yapılabilen işlemler. Listede olmayan her istek (yeni eklenen endpoint'ler dahil) engellenir;
+// kullanıcının gördüklerini incelemek ve kişi kayıtlarını düzeltmek dışında bir işlem yapılamaz
+var impersonationAllowedRoutes = map[string]bool{
+	"GET /secured":                 true,
+	"GET /api/v1/person":           true,
+	"GET /api/v1/person/search":    true,
+	"GET /api/v1/person/:id":       true,
+	"POST /api/v1/person":          true,
+	"PUT /api/v1/person/:id":       true,
+	"GET /api/v1/person/:id/share": true,
+	"GET /api/v1/user":             true,
+	"GET /api/v1/user/:id":         true,
+	"GET /api/v1/group":            true,
+}
+
+// Taklit token'ı ile gelen isteği işler: yanıta taklit başlıklarını ekler, izin verilmeyen işlemleri engeller ve isteği
+// denetim kaydına yazar. Kayıt istek işlenmeden önce yazılır; yazılamazsa istek işlenmez
+func serveImpersonated(c *gin.Context, claims *Claims) {
+	c.Header("X-Impersonated-User", claims.Username)
+	c.Header("X-Impersonator", claims.Act.Username)
+
+	allowed := impersonationAllowedRoutes[c.Request.Method+" "+c.FullPath()]
+
+	// İzin verilen isteklerin durum kodu istek işlenene kadar 0'dır
+	status := 0
+	if !allowed {
+		status = http.StatusForbidden
+	}
+
+	entry := models.ImpersonationAudit{
+		AdminID:   claims.Act.UserID,
+		UserID:    claims.UserID,
+		TokenID:   claims.Id,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Status:    status,
+		IPAddress: c.ClientIP(),
+	}
+
+	auditID, err := models.AddImpersonationAudit(entry)
+	if err != nil {
+		log.Println("Taklit denetim kaydı yazılamadı:", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "DENETİM KAYDI YAZILAMADI"})
+		return
+	}
+
+	if !allowed {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "TAKLİT EDİLEN OTURUMDA BU İŞLEM YAPILAMAZ"})
+		return
+	}
+
+	c.Set("claims", claims)
+	c.Next()
+
+	if err := models.SetImpersonationAuditStatus(auditID, c.Writer.Status()); err != nil {
+		log.Println("Taklit denetim kaydı güncellenemedi:", err)
+	}
+}
+
+// @Summary Impersonate a user
+// @Description Issues a short-lived (10 minutes) access token acting as the user, without a refresh token (admin only). The token carries an act claim with the admin and the admin's scopes; it can only read persons, users and groups and create or update persons, and every request is written to the audit log before it is handled
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param input body ImpersonateRequest true "Reason of the impersonation"
+// @Router /api/v1/user/{id}/impersonate [post]
+func ImpersonateUser(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz Kullanıcı ID'si"})
+		return
+	}
+
+	var req ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "TAKLİT NEDENİ BELİRTİLMELİ"})
+		return
+	}
+
+	user, err := models.GetUserByID(userID, claims.TenantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı Bulunamadı"})
+		return
+	}
+
+	// Admin'ler taklit edilemez, böylece taklit yetkiyi artırmak için kullanılamaz
+	if user.ID == claims.UserID || user.Role == "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "BU KULLANICI TAKLİT EDİLEMEZ"})
+		return
+	}
+
+	now := time.Now()
+	impersonation := &Claims{
+		UserID:         user.ID,
+		TenantID:       user.TenantID,
+		Username:       user.Username,
+		Role:           user.Role,
+		SessionVersion: revocations.sessionVersion(user.ID),
+		// Taklit token'ı admin'in token'ının kapsamlarını aşamaz
+		Scopes: claims.Scopes,
+		Act: &ActorClaim{
+			Subject:        strconv.Itoa(claims.UserID),
+			UserID:         claims.UserID,
+			Username:       claims.Username,
+			SessionVersion: claims.SessionVersion,
+		},
+		StandardClaims: jwt.StandardClaims{
+			Id:        randomID(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(impersonationTTL).Unix(),
+		},
+	}
+
+	token, err := signToken(impersonation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "TOKEN OLUŞTURULAMADI"})
+		return
+	}
+
+	_, err = models.AddImpersonationAudit(models.ImpersonationAudit{
+		AdminID:   claims.UserID,
+		UserID:    user.ID,
+		TokenID:   impersonation.Id,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Status:    http.StatusOK,
+		IPAddress: c.ClientIP(),
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		// Denetim kaydı yazılamıyorsa taklit başlatılmaz
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "DENETİM KAYDI YAZILAMADI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"expires_in": int(impersonationTTL.Seconds()),
+		"user":       user.Username,
+	})
+}
+
+// @Summary Impersonation audit log
+// @Description Lists impersonation starts and every request made with an impersonation token, newest first (platform admin only)
+// @Tags user
+// @Produce json
+// @Param user_id query int false "Impersonated user"
+// @Param admin_id query int false "Admin"
+// @Param page query int false "Page number for pagination (default is 1)"
+// @Param pageSize query int false "Number of items per page (default is 20)"
+// @Router /api/v1/audit/impersonation [get]
+func GetImpersonationAudit(c *gin.Context) {
+	userID, _ := strconv.Atoi(c.Query("user_id"))
+	adminID, _ := strconv.Atoi(c.Query("admin_id"))
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 20
+	}
+
+	entries, err := models.GetImpersonationAudit(userID, adminID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Denetim kayıtları alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": entries})
+}
@@ -0,0 +1,154 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+func TestImpersonation(t *testing.T) {
+	setupTestDB(t)
+	if err := auth.LoadPolicy(); err != nil {
+		t.Fatalf("Politika yüklenemedi: %v", err)
+	}
+
+	r := setupRouter()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	v1 := r.Group("/api/v1")
+	v1.Use(auth.TokenAuthMiddleware(), auth.Authorize())
+	v1.GET("user/:id", ok)
+	v1.POST("user", ok)
+	v1.POST("person", auth.RequireScope(auth.ScopePersonWrite), ok)
+	v1.DELETE("person/:id", ok)
+	v1.POST("person/:id/share", ok)
+	v1.POST("group", ok)
+	v1.POST("group/:id/member", ok)
+	v1.POST("user/:id/impersonate", auth.ImpersonateUser)
+	r.POST("/logout", auth.TokenAuthMiddleware(), auth.Logout)
+
+	adminID, err := models.CreateUser(models.User{Username: "admin", Password: "yonetici1234", TenantID: models.DefaultTenantID})
+	if err != nil {
+		t.Fatalf("Kullanıcı eklenemedi: %v", err)
+	}
+	if _, err := models.DB.Exec("UPDATE user SET role = 'admin' WHERE username = 'admin'"); err != nil {
+		t.Fatalf("Rol güncellenemedi: %v", err)
+	}
+
+	userToken := login(t, r, "test", "gizli1234")["token"].(string)
+	adminToken := login(t, r, "admin", "yonetici1234")["token"].(string)
+
+	reason := map[string]string{"reason": "destek talebi #42"}
+
+	if w, _ := postJSONWithToken(r, "/api/v1/user/1/impersonate", userToken, reason); w.Code != http.StatusForbidden {
+		t.Errorf("Admin olmayan kullanıcı taklit başlattı. Kod: %d", w.Code)
+	}
+
+	if w, _ := postJSONWithToken(r, "/api/v1/user/1/impersonate", adminToken, map[string]string{}); w.Code != http.StatusBadRequest {
+		t.Errorf("Nedensiz taklit başlatıldı. Kod: %d", w.Code)
+	}
+
+	if w, _ := postJSONWithToken(r, "/api/v1/user/2/impersonate", adminToken, reason); w.Code != http.StatusForbidden {
+		t.Errorf("Admin taklit edildi. Kod: %d", w.Code)
+	}
+
+	w, resp := postJSONWithToken(r, "/api/v1/user/1/impersonate", adminToken, reason)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Taklit başlatılamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+	token := resp["token"].(string)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Taklit token'ı kullanıcının yetkileriyle çalışmalı ve yanıtta taklit başlıkları olmalı
+	w = request(http.MethodGet, "/api/v1/user/1")
+	if w.Code != http.StatusOK || w.Header().Get("X-Impersonated-User") != "test" || w.Header().Get("X-Impersonator") != "admin" {
+		t.Errorf("Taklit isteği beklenen gibi değil. Kod: %d, Başlıklar: %v", w.Code, w.Header())
+	}
+
+	if w = request(http.MethodGet, "/api/v1/user/2"); w.Code != http.StatusForbidden {
+		t.Errorf("Taklit token'ı kullanıcının yetkilerini aştı. Kod: %d", w.Code)
+	}
+
+	if w = request(http.MethodDelete, "/api/v1/person/1"); w.Code != http.StatusForbidden {
+		t.Errorf("Taklit edilen oturumda silme yapıldı. Kod: %d", w.Code)
+	}
+
+	if w, _ := postJSONWithToken(r, "/api/v1/user/1/impersonate", token, reason); w.Code != http.StatusForbidden {
+		t.Errorf("Taklit edilen oturumda yeni taklit başlatıldı. Kod: %d", w.Code)
+	}
+
+	// İzin listesinde olmayan işlemler engellenir
+	for _, path := range []string{"/api/v1/user", "/api/v1/group", "/api/v1/group/1/member", "/api/v1/person/1/share", "/logout"} {
+		if w := request(http.MethodPost, path); w.Code != http.StatusForbidden {
+			t.Errorf("Taklit edilen oturumda POST %s yapıldı. Kod: %d", path, w.Code)
+		}
+	}
+
+	if w = request(http.MethodPost, "/api/v1/person"); w.Code != http.StatusOK {
+		t.Errorf("Taklit edilen oturumda kişi eklenemedi. Kod: %d", w.Code)
+	}
+
+	entries, err := models.GetImpersonationAudit(1, int(adminID), 20, 0)
+	if err != nil {
+		t.Fatalf("Denetim kayıtları alınamadı: %v", err)
+	}
+
+	// Başlatma kaydı ve taklit token'ı ile yapılan 10 istek
+	if len(entries) != 11 {
+		t.Fatalf("Beklenen 11 denetim kaydı, alınan %d: %+v", len(entries), entries)
+	}
+
+	if first := entries[len(entries)-1]; first.Reason != "destek talebi #42" || first.Path != "/api/v1/user/1/impersonate" {
+		t.Errorf("Başlatma kaydı yanlış: %+v", first)
+	}
+
+	if latest := entries[0]; latest.Method != http.MethodPost || latest.Path != "/api/v1/person" || latest.Status != http.StatusOK {
+		t.Errorf("Son istek denetim kaydına doğru yazılmadı: %+v", latest)
+	}
+
+	if blocked := entries[1]; blocked.Path != "/logout" || blocked.Status != http.StatusForbidden {
+		t.Errorf("Engellenen istek denetim kaydına doğru yazılmadı: %+v", blocked)
+	}
+
+	// Taklit token'ı admin'in token'ının kapsamlarıyla sınırlıdır
+	w, _ = postJSON(r, "/login", map[string]string{"username": "admin", "password": "yonetici1234", "scope": auth.ScopePersonRead})
+	var readOnly map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &readOnly)
+	if w, resp = postJSONWithToken(r, "/api/v1/user/1/impersonate", readOnly["token"].(string), reason); w.Code != http.StatusOK {
+		t.Fatalf("Taklit başlatılamadı. Kod: %d", w.Code)
+	}
+	readOnlyToken := resp["token"].(string)
+	if w, _ := postJSONWithToken(r, "/api/v1/person", readOnlyToken, nil); w.Code != http.StatusForbidden {
+		t.Errorf("Taklit token'ı admin'in kapsamlarını aştı. Kod: %d", w.Code)
+	}
+
+	// Denetim kaydı yazılamazsa istek işlenmez
+	if _, err := models.DB.Exec("DROP TABLE impersonation_audit"); err != nil {
+		t.Fatalf("Tablo silinemedi: %v", err)
+	}
+	if w = request(http.MethodGet, "/api/v1/user/1"); w.Code != http.StatusInternalServerError {
+		t.Errorf("Denetim kaydı yazılamadan istek işlendi. Kod: %d", w.Code)
+	}
+
+	// Admin'in oturumları iptal edilince taklit token'ı da geçersiz olmalı (iptal kontrolü denetim kaydından önce yapılır)
+	if err := auth.InvalidateUserSessions(int(adminID)); err != nil {
+		t.Fatalf("Oturumlar iptal edilemedi: %v", err)
+	}
+
+	if w = request(http.MethodGet, "/api/v1/user/1"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Admin'in oturumları iptal edildikten sonra taklit token'ı kabul edildi. Kod: %d", w.Code)
+	}
+}
@@ -1,7 +1,10 @@
 package auth
 
 import (
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -10,16 +13,25 @@ import (
 	"example.com/webservice/models"
 )
 
-var jwtKey = []byte("my_secret_key")
-
 type Credentials struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Scope    string `json:"scope"` // İsteğe bağlı, boşlukla ayrılmış kapsam listesi (örn. "person:read")
 }
 
 type Claims struct {
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID         int         `json:"user_id"`
+	TenantID       int         `json:"tenant_id"`
+	Username       string      `json:"username"`
+	Role           string      `json:"role"`
+	SessionVersion int         `json:"session_version"`
+	APIKeyID       int         `json:"api_key_id,omitempty"`
+	Scopes         []string    `json:"scopes,omitempty"`
+	ClientID       string      `json:"client_id,omitempty"` // Token bir OAuth istemcisine verildiyse
+	Act            *ActorClaim `json:"act,omitempty"`       // Token bir admin tarafından taklit için üretildiyse
+	SessionID      string      `json:"sid,omitempty"`       // Token'ın ait olduğu oturum (refresh token ailesi)
+	ClientCert     string      `json:"-"`                   // İstek doğrulanmış bir istemci sertifikası ile geldiyse sertifikanın kimliği
+	PartnerID      string      `json:"-"`                   // İstek bir partnerin HMAC imzası ile geldiyse
 	jwt.StandardClaims
 }
 
@@ -36,56 +48,121 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	user, err := models.GetUserByUsernameAndPassword(creds.Username, creds.Password)
+	scopes, err := parseScopes(creds.Scope)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ SCOPE"})
+		return
+	}
+
+	user, err := checkCredentials(creds.Username, creds.Password, c.ClientIP())
 	if err != nil {
-		if err.Error() == "kullanıcı bulunamadı" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "KULLANICI BULUNAMADI"})
+		if locked, ok := err.(*loginLockedError); ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": locked.Error()})
 			return
-		} else if err.Error() == "şifre yanlış" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "ŞİFRE YANLIŞ"})
+		}
+		if err == errInvalidCredentials {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if err == errEmailNotVerified {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "BAŞARILI GİRİŞ"})
-
-	expirationTime := time.Now().Add(10 * time.Hour)
-	claims := &Claims{
-		Username: creds.Username,
-		Role:     user.Role,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: expirationTime.Unix(),
-		},
+	// 2FA etkinse veya kullanıcının rolü 2FA gerektiriyorsa token yerine challenge döner
+	if startTwoFactor(c, user, scopes) {
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtKey)
+	tokens, err := startSession(c, user, scopes)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "TOKEN OLUŞTURULAMADI"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "BAŞARILI GİRİŞ",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"scope":         strings.Join(scopes, " "),
+	})
+}
+
+func generateAccessToken(user models.User, sessionID string, scopes []string, clientID string) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:         user.ID,
+		TenantID:       user.TenantID,
+		Username:       user.Username,
+		Role:           user.Role,
+		SessionVersion: revocations.sessionVersion(user.ID),
+		Scopes:         scopes,
+		ClientID:       clientID,
+		SessionID:      sessionID,
+		StandardClaims: jwt.StandardClaims{
+			Id:        randomID(),
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(accessTokenTTL).Unix(),
+		},
+	}
+
+	return signToken(claims)
 }
 
 func TokenAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Makine istemcileri JWT yerine API anahtarı gönderebilir
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			claims, err := authenticateAPIKey(rawKey)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ API ANAHTARI"})
+				c.Abort()
+				return
+			}
+
+			c.Set("claims", claims)
+			c.Next()
+			return
+		}
+
+		// Partnerler istekleri paylaşılan anahtarla imzalar
+		if isSignedRequest(c) {
+			serveSignedRequest(c)
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
+
+		// Token gönderilmediyse TLS katmanında doğrulanmış istemci sertifikası kabul edilir
+		if authHeader == "" {
+			if cert := verifiedClientCert(c.Request); cert != nil {
+				serveClientCert(c, cert)
+				return
+			}
+		}
+
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization BAŞLIĞI SAĞLANAMADI"})
 			c.Abort()
 			return
 		}
 
-		tokenString := authHeader[len("Bearer "):]
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ TOKEN"})
+			c.Abort()
+			return
+		}
+
+		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
 		claims := &Claims{}
 
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
+		token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey)
 
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ TOKEN"})
@@ -93,12 +170,30 @@ func TokenAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if (c.Request.Method == "DELETE" || c.Request.Method == "PUT") && claims.Role != "admin" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Yetkisiz İşlem"})
+		// Tenant bilgisi olmayan (tenant'lar eklenmeden önce üretilmiş) token'lar ve
+		// audience içeren (örn. 2FA challenge) token'lar erişim token'ı olarak kabul edilmez
+		if claims.TenantID == models.AllTenants || claims.Audience != "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ TOKEN"})
+			c.Abort()
+			return
+		}
+
+		if revocations.isRevoked(claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "TOKEN İPTAL EDİLDİ"})
 			c.Abort()
 			return
 		}
 
+		if claims.SessionID != "" {
+			sessionActivity.touch(claims.SessionID)
+		}
+
+		if claims.Act != nil {
+			serveImpersonated(c, claims)
+			return
+		}
+
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
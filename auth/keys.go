@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+)
+
+// Token imzalamak veya doğrulamak için kullanılan anahtar. private nil ise anahtar sadece doğrulama için kullanılır
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+type keySet struct {
+	mu     sync.RWMutex
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// LoadKeysFromEnv çağrılana kadar (örn. testlerde) rastgele üretilmiş bir HS256 anahtarı kullanılır. Bu anahtar
+// süreç dışında bilinmez, servis yeniden başladığında üretilen token'lar geçersiz olur
+var signingKeys = newKeySet(randomHMACKey())
+
+func randomHMACKey() *signingKey {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return &signingKey{kid: "default", method: jwt.SigningMethodHS256, private: secret, public: secret}
+}
+
+func newKeySet(active *signingKey) *keySet {
+	return &keySet{active: active, keys: map[string]*signingKey{active.kid: active}}
+}
+
+// Anahtarları ortam değişkenlerinden yükler:
+//
+//	JWT_SECRET       HS256 için paylaşılan gizli anahtar
+//	JWT_PRIVATE_KEY  RS256/ES256 için PEM formatında özel anahtar
+//	JWT_KID          JWT_SECRET veya JWT_PRIVATE_KEY için kid değeri (varsayılan "default")
+//	JWT_KEYS         "kid=dosya,kid=dosya" listesi. Dosya özel anahtar, sadece doğrulama için açık anahtar veya HS256 gizli anahtarı içerebilir
+//	JWT_ACTIVE_KID   İmzalamak için kullanılacak anahtar (varsayılan JWT_KID veya JWT_KEYS içindeki ilk anahtar)
+func LoadKeysFromEnv() error {
+	var keys []*signingKey
+	kid := os.Getenv("JWT_KID")
+	if kid == "" {
+		kid = "default"
+	}
+
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		keys = append(keys, &signingKey{kid: kid, method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)})
+	}
+
+	if pemData := os.Getenv("JWT_PRIVATE_KEY"); pemData != "" {
+		key, err := parseKey(kid, []byte(pemData))
+		if err != nil {
+			return fmt.Errorf("JWT_PRIVATE_KEY okunamadı: %v", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if list := os.Getenv("JWT_KEYS"); list != "" {
+		for _, entry := range strings.Split(list, ",") {
+			parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("geçersiz JWT_KEYS girdisi: %q", entry)
+			}
+
+			data, err := os.ReadFile(parts[1])
+			if err != nil {
+				return err
+			}
+
+			key, err := parseKey(parts[0], data)
+			if err != nil {
+				return fmt.Errorf("%s anahtarı okunamadı: %v", parts[0], err)
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	// Sabit veya rastgele bir anahtara düşülmez: token'lar doğrulanabilir ve yeniden başlatmalardan sonra da geçerli olmalıdır
+	if len(keys) == 0 {
+		return errors.New("JWT anahtarı yapılandırılmadı: JWT_SECRET, JWT_PRIVATE_KEY veya JWT_KEYS tanımlanmalı")
+	}
+
+	activeKid := os.Getenv("JWT_ACTIVE_KID")
+	if activeKid == "" {
+		activeKid = keys[0].kid
+	}
+
+	return setSigningKeys(activeKid, keys...)
+}
+
+func setSigningKeys(activeKid string, keys ...*signingKey) error {
+	byKid := make(map[string]*signingKey)
+	for _, key := range keys {
+		if _, exists := byKid[key.kid]; exists {
+			return fmt.Errorf("aynı kid birden fazla kez tanımlandı: %s", key.kid)
+		}
+		byKid[key.kid] = key
+	}
+
+	active, ok := byKid[activeKid]
+	if !ok {
+		return fmt.Errorf("aktif anahtar bulunamadı: %s", activeKid)
+	}
+
+	if active.private == nil {
+		return fmt.Errorf("aktif anahtarın özel anahtarı yok: %s", activeKid)
+	}
+
+	signingKeys.mu.Lock()
+	signingKeys.active = active
+	signingKeys.keys = byKid
+	signingKeys.mu.Unlock()
+
+	return nil
+}
+
+// PEM verisini veya düz metin HS256 gizli anahtarını okur
+func parseKey(kid string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		secret := strings.TrimSpace(string(data))
+		if secret == "" {
+			return nil, errors.New("anahtar boş")
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodHS256, private: []byte(secret), public: []byte(secret)}, nil
+	}
+
+	var parsed interface{}
+	var err error
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("desteklenmeyen PEM tipi: %s", block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &signingKey{kid: kid, method: jwt.SigningMethodRS256, public: k}, nil
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("ES256 için P-256 eğrisi gerekli")
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodES256, private: k, public: &k.PublicKey}, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("ES256 için P-256 eğrisi gerekli")
+		}
+		return &signingKey{kid: kid, method: jwt.SigningMethodES256, public: k}, nil
+	}
+
+	return nil, errors.New("desteklenmeyen anahtar tipi")
+}
+
+func signToken(claims jwt.Claims) (string, error) {
+	signingKeys.mu.RLock()
+	key := signingKeys.active
+	signingKeys.mu.RUnlock()
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.private)
+}
+
+// jwt.Parse için anahtar fonksiyonu. kid başlığı olmayan eski token'lar aktif anahtarla doğrulanır.
+// Algoritma anahtarın algoritmasıyla aynı olmalı, aksi halde (örn. RS256 açık anahtarı HS256 gizli anahtarı gibi kullanılarak) token reddedilir
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	signingKeys.mu.RLock()
+	defer signingKeys.mu.RUnlock()
+
+	key := signingKeys.active
+	if kid, ok := token.Header["kid"].(string); ok {
+		key, ok = signingKeys.keys[kid]
+		if !ok {
+			return nil, errors.New("bilinmeyen kid")
+		}
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("beklenmeyen imza algoritması")
+	}
+
+	return key.public, nil
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys that can be used to verify tokens issued by this service. Shared HS256 secrets are never published
+// @Produce json
+// @Router /.well-known/jwks.json [get]
+func JWKS(c *gin.Context) {
+	signingKeys.mu.RLock()
+	defer signingKeys.mu.RUnlock()
+
+	kids := make([]string, 0, len(signingKeys.keys))
+	for kid := range signingKeys.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	jwks := make([]gin.H, 0, len(kids))
+
+	for _, kid := range kids {
+		key := signingKeys.keys[kid]
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, gin.H{
+				"kty": "RSA",
+				"kid": key.kid,
+				"alg": key.method.Alg(),
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			jwks = append(jwks, gin.H{
+				"kty": "EC",
+				"kid": key.kid,
+				"alg": key.method.Alg(),
+				"use": "sig",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+				"y":   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": jwks})
+}
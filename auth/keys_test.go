@@ -0,0 +1,118 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/webservice/auth"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("Anahtar dosyası yazılamadı: %v", err)
+	}
+	return path
+}
+
+func TestAsymmetricKeysAndRotation(t *testing.T) {
+	setupTestDB(t)
+	auth.KeepSigningKeys(t)
+	r := setupRouter()
+	r.GET("/.well-known/jwks.json", auth.JWKS)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecDER, _ := x509.MarshalECPrivateKey(ecKey)
+
+	rsaPath := writePEM(t, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+	ecPath := writePEM(t, "ec.pem", "EC PRIVATE KEY", ecDER)
+
+	t.Setenv("JWT_KEYS", "old="+rsaPath+",new="+ecPath)
+	t.Setenv("JWT_ACTIVE_KID", "old")
+	if err := auth.LoadKeysFromEnv(); err != nil {
+		t.Fatalf("Anahtarlar yüklenemedi: %v", err)
+	}
+
+	oldToken := login(t, r, "test", "gizli1234")["token"].(string)
+	if code := getSecured(r, oldToken); code != http.StatusOK {
+		t.Fatalf("RS256 token reddedildi. Kod: %d", code)
+	}
+
+	// Aktif anahtar değiştikten sonra eski anahtarla imzalanmış token hâlâ geçerli olmalı
+	t.Setenv("JWT_ACTIVE_KID", "new")
+	if err := auth.LoadKeysFromEnv(); err != nil {
+		t.Fatalf("Anahtarlar yüklenemedi: %v", err)
+	}
+
+	newToken := login(t, r, "test", "gizli1234")["token"].(string)
+	if !strings.Contains(decodeHeader(t, newToken), `"ES256"`) {
+		t.Errorf("Yeni token ES256 ile imzalanmadı")
+	}
+
+	for _, token := range []string{oldToken, newToken} {
+		if code := getSecured(r, token); code != http.StatusOK {
+			t.Errorf("Token reddedildi. Kod: %d", code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &jwks); err != nil {
+		t.Fatalf("JWKS okunamadı: %v", err)
+	}
+
+	if len(jwks.Keys) != 2 || jwks.Keys[0]["kid"] != "new" || jwks.Keys[0]["kty"] != "EC" || jwks.Keys[1]["kty"] != "RSA" {
+		t.Errorf("Beklenmeyen JWKS: %s", w.Body.String())
+	}
+
+	// Eski anahtar kaldırıldığında o anahtarla imzalanmış token reddedilmeli
+	t.Setenv("JWT_KEYS", "new="+ecPath)
+	if err := auth.LoadKeysFromEnv(); err != nil {
+		t.Fatalf("Anahtarlar yüklenemedi: %v", err)
+	}
+
+	if code := getSecured(r, oldToken); code != http.StatusUnauthorized {
+		t.Errorf("Kaldırılan anahtarla imzalanmış token kabul edildi. Kod: %d", code)
+	}
+}
+
+func TestMissingSigningKeyFailsStartup(t *testing.T) {
+	auth.KeepSigningKeys(t)
+	for _, name := range []string{"JWT_SECRET", "JWT_PRIVATE_KEY", "JWT_KEYS"} {
+		t.Setenv(name, "")
+	}
+
+	if err := auth.LoadKeysFromEnv(); err == nil {
+		t.Error("Anahtar yapılandırılmadan başlatılabildi")
+	}
+}
+
+func decodeHeader(t *testing.T, token string) string {
+	t.Helper()
+
+	header, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+	if err != nil {
+		t.Fatalf("Token başlığı okunamadı: %v", err)
+	}
+	return string(header)
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"example.com/webservice/models"
+)
+
+const (
+	accountFreeAttempts = 3  // Hesap başına bekleme süresi uygulanmadan önce izin verilen hatalı deneme
+	ipFreeAttempts      = 20 // IP başına (aynı NAT arkasındaki kullanıcılar için daha yüksek)
+	loginBaseDelay      = time.Second
+	loginMaxDelay       = 15 * time.Minute
+	loginFailureWindow  = time.Hour // Son hatalı denemeden bu süre sonra sayaç sıfırlanır
+)
+
+var (
+	failedLogins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_failed_logins_total",
+		Help: "Total number of failed login attempts",
+	}, []string{"reason"})
+
+	loginLockouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_login_lockouts_total",
+		Help: "Total number of temporary login lockouts",
+	}, []string{"scope"})
+)
+
+func init() {
+	prometheus.MustRegister(failedLogins)
+	prometheus.MustRegister(loginLockouts)
+}
+
+func accountKey(username string) string {
+	return "user:" + username
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Hatalı deneme sayısı serbest deneme sınırını aştıkça bekleme süresi ikiye katlanır
+func loginBackoff(excess int) time.Duration {
+	if excess >= 20 {
+		return loginMaxDelay
+	}
+
+	delay := loginBaseDelay << uint(excess)
+	if delay > loginMaxDelay {
+		return loginMaxDelay
+	}
+
+	return delay
+}
+
+// Hesap veya IP kilitliyse kalan süreyi döner
+func loginLockedFor(username, ip string) (time.Duration, error) {
+	var wait time.Duration
+	now := time.Now()
+
+	for _, key := range []string{accountKey(username), ipKey(ip)} {
+		attempt, err := models.GetLoginAttempt(key)
+		if err != nil {
+			return 0, err
+		}
+
+		if remaining := attempt.LockedUntil.Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+
+	return wait, nil
+}
+
+// Hatalı denemeyi sayar. Sayaç serbest deneme sınırına ulaştıysa anahtarı kilitler ve true döner
+func countFailure(key string, free int, window time.Duration) (bool, error) {
+	failures, err := models.IncrementLoginAttempt(key, window)
+	if err != nil {
+		return false, err
+	}
+
+	if failures < free {
+		return false, nil
+	}
+
+	return true, models.LockLoginAttempt(key, time.Now().Add(loginBackoff(failures-free)))
+}
+
+// Sayaçların hiçbirinin penceresi loginFailureWindow'dan uzun olmadığından daha eski ve kilidi bitmiş kayıtlar
+// (kullanılmış challenge'lar dahil) silinebilir. Yeni bir sayaç açılmadan önce çağrılır, böylece tablo büyümez
+func deleteExpiredLoginAttempts() {
+	if err := models.DeleteExpiredLoginAttempts(time.Now().Add(-loginFailureWindow)); err != nil {
+		log.Println("Eski deneme kayıtları silinemedi:", err)
+	}
+}
+
+func recordLoginFailure(username, ip string) error {
+	deleteExpiredLoginAttempts()
+
+	limits := []struct {
+		key   string
+		scope string
+		free  int
+	}{
+		{accountKey(username), "account", accountFreeAttempts},
+		{ipKey(ip), "ip", ipFreeAttempts},
+	}
+
+	for _, limit := range limits {
+		locked, err := countFailure(limit.key, limit.free, loginFailureWindow)
+		if err != nil {
+			return err
+		}
+
+		if locked {
+			loginLockouts.WithLabelValues(limit.scope).Inc()
+		}
+	}
+
+	return nil
+}
+
+// Kullanıcı adının var olup olmadığı belli olmasın diye iki durumda da aynı hata döner
+var errInvalidCredentials = errors.New("GEÇERSİZ KULLANICI ADI VEYA ŞİFRE")
+
+// Şifre doğru olsa da e-posta adresi doğrulanmamış hesaplar giriş yapamaz
+var errEmailNotVerified = errors.New("E-POSTA ADRESİ DOĞRULANMAMIŞ")
+
+// Hesap veya IP geçici olarak kilitliyken dönen hata
+type loginLockedError struct {
+	wait time.Duration
+}
+
+func (e *loginLockedError) Error() string {
+	return "ÇOK FAZLA BAŞARISIZ GİRİŞ DENEMESİ, DAHA SONRA TEKRAR DENEYİN"
+}
+
+// Kilit kontrolünden sonra kullanıcı adı ve şifreyi doğrular. Başarısız denemeler kaydedilir, başarılı girişte hesabın sayacı sıfırlanır
+func checkCredentials(username, password, ip string) (models.User, error) {
+	wait, err := loginLockedFor(username, ip)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if wait > 0 {
+		failedLogins.WithLabelValues("locked").Inc()
+		return models.User{}, &loginLockedError{wait: wait}
+	}
+
+	user, err := models.GetUserByUsernameAndPassword(username, password)
+	if err != nil {
+		if err.Error() == "kullanıcı bulunamadı" || err.Error() == "şifre yanlış" {
+			if err := recordLoginFailure(username, ip); err != nil {
+				log.Println("Başarısız giriş kaydedilemedi:", err)
+			}
+			failedLogins.WithLabelValues("invalid_credentials").Inc()
+			return models.User{}, errInvalidCredentials
+		}
+		return models.User{}, err
+	}
+
+	if err := models.ClearLoginAttempts(accountKey(username)); err != nil {
+		log.Println("Başarısız giriş kayıtları temizlenemedi:", err)
+	}
+
+	verified, err := models.IsEmailVerified(user.ID)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	if !verified {
+		return models.User{}, errEmailNotVerified
+	}
+
+	return user, nil
+}
+
+// @Summary Unlock a user's login
+// @Description Clears failed login attempts and the temporary lockout of the user (admin only)
+// @Tags user
+// @Produce json
+// @Param id path int true "User ID"
+// @Router /api/v1/user/{id}/lockout [delete]
+func UnlockUser(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz Kullanıcı ID'si"})
+		return
+	}
+
+	claims := c.MustGet("claims").(*Claims)
+	user, err := models.GetUserByID(userID, claims.TenantID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı Bulunamadı"})
+		return
+	}
+
+	if err := models.ClearLoginAttempts(accountKey(user.Username)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Kilit kaldırılamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kullanıcının giriş kilidi kaldırıldı"})
+}
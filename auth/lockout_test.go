@@ -0,0 +1,83 @@
+package auth_test
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/webservice/models"
+)
+
+func TestLoginLockout(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+
+	// Var olan ve olmayan kullanıcı için yanıt aynıdır
+	w1, resp1 := postJSON(r, "/login", map[string]string{"username": "test", "password": "yanlis"})
+	w2, resp2 := postJSON(r, "/login", map[string]string{"username": "yok", "password": "yanlis"})
+	if w1.Code != http.StatusUnauthorized || w2.Code != http.StatusUnauthorized || resp1["error"] != resp2["error"] {
+		t.Fatalf("Başarısız giriş yanıtları farklı. %d %v / %d %v", w1.Code, resp1, w2.Code, resp2)
+	}
+
+	postJSON(r, "/login", map[string]string{"username": "test", "password": "yanlis"})
+	postJSON(r, "/login", map[string]string{"username": "test", "password": "yanlis"})
+
+	// Hesap geçici olarak kilitlendi, doğru şifre de kabul edilmez
+	w, _ := postJSON(r, "/login", map[string]string{"username": "test", "password": "gizli1234"})
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("Hesap kilitlenmedi. Kod: %d", w.Code)
+	}
+
+	// Admin kilidi kaldırır
+	if err := models.ClearLoginAttempts("user:test"); err != nil {
+		t.Fatalf("Kilit kaldırılamadı: %v", err)
+	}
+
+	login(t, r, "test", "gizli1234")
+}
+
+func TestLoginFailuresCountedConcurrently(t *testing.T) {
+	setupTestDB(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := models.IncrementLoginAttempt("user:test", time.Hour); err != nil {
+				t.Errorf("Deneme sayılamadı: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Eş zamanlı denemelerin hiçbiri kaybolmamalı
+	attempt, err := models.GetLoginAttempt("user:test")
+	if err != nil || attempt.Failures != 20 {
+		t.Errorf("Hatalı denemeler eksik sayıldı: %+v, %v", attempt, err)
+	}
+
+	// Pencere dolduktan sonra sayaç sıfırdan başlar
+	if failures, err := models.IncrementLoginAttempt("user:test", -time.Second); err != nil || failures != 1 {
+		t.Errorf("Sayaç sıfırlanmadı: %d, %v", failures, err)
+	}
+}
+
+func TestExpiredLoginAttemptsDeleted(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+
+	old := time.Now().Add(-2 * time.Hour).Unix()
+	if _, err := models.DB.Exec("INSERT INTO login_attempt (key, failures, last_failure, locked_until) VALUES ('ip:10.0.0.1', 5, ?, ?)", old, old); err != nil {
+		t.Fatalf("Deneme kaydı eklenemedi: %v", err)
+	}
+
+	// Başarısız giriş eski kayıtları temizler
+	postJSON(r, "/login", map[string]string{"username": "test", "password": "yanlis"})
+
+	var count int
+	if err := models.DB.QueryRow("SELECT COUNT(*) FROM login_attempt WHERE key = 'ip:10.0.0.1'").Scan(&count); err != nil || count != 0 {
+		t.Errorf("Süresi dolmuş deneme kaydı silinmedi: %d, %v", count, err)
+	}
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+// Sertifika kimliklerinin türleri. Kimlikler "uri:spiffe://mesh/ns/billing/sa/api" gibi tür önekiyle yazılır
+var certIdentityKinds = []string{"uri", "dns", "email", "cn"}
+
+var mtlsIdentities = struct {
+	sync.RWMutex
+	users map[string]string
+}{}
+
+// İstemci sertifikası kimliklerini kullanıcı adlarına eşler. Eşlenmeyen sertifikalar kabul edilmez
+func ConfigureMTLS(identities map[string]string) error {
+	users := make(map[string]string, len(identities))
+
+	for identity, username := range identities {
+		kind, value, ok := strings.Cut(identity, ":")
+		if !ok || value == "" || !containsScope(certIdentityKinds, strings.ToLower(kind)) || username == "" {
+			return fmt.Errorf("geçersiz sertifika kimliği eşlemesi: %s=%s", identity, username)
+		}
+
+		users[strings.ToLower(kind)+":"+value] = username
+	}
+
+	mtlsIdentities.Lock()
+	mtlsIdentities.users = users
+	mtlsIdentities.Unlock()
+
+	return nil
+}
+
+// MTLS_IDENTITY_MAP virgülle ayrılmış kimlik=kullanıcı adı listesidir,
+// örn. "uri:spiffe://mesh/ns/billing/sa/api=billing-svc,cn:reporting=reporting-svc"
+func LoadMTLSFromEnv() error {
+	list := os.Getenv("MTLS_IDENTITY_MAP")
+	if list == "" {
+		return nil
+	}
+
+	identities := make(map[string]string)
+	for _, entry := range strings.Split(list, ",") {
+		identity, username, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return fmt.Errorf("geçersiz sertifika kimliği eşlemesi: %s", entry)
+		}
+		identities[strings.TrimSpace(identity)] = strings.TrimSpace(username)
+	}
+
+	return ConfigureMTLS(identities)
+}
+
+// Sunucu sertifikasıyla TLS ayarlarını oluşturur. clientCAFile verilirse bu CA tarafından imzalanmış istemci sertifikaları
+// doğrulanır; sertifika zorunlu değildir, böylece sertifikası olmayan istemciler token ile devam edebilir
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		pemData, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pemData) {
+			return nil, errors.New("istemci CA sertifikası okunamadı")
+		}
+
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return config, nil
+}
+
+// TLS_CERT_FILE ve TLS_KEY_FILE tanımlı değilse nil döner ve servis düz HTTP ile çalışır
+func LoadTLSFromEnv() (*tls.Config, error) {
+	certFile, keyFile := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE")
+	if certFile == "" && keyFile == "" {
+		return nil, nil
+	}
+
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("TLS_CERT_FILE ve TLS_KEY_FILE birlikte tanımlanmalı")
+	}
+
+	return ServerTLSConfig(certFile, keyFile, os.Getenv("TLS_CLIENT_CA_FILE"))
+}
+
+// Sertifikanın kimlikleri öncelik sırasıyla: URI SAN'lar (SPIFFE ID'leri), DNS SAN'lar, e-posta SAN'lar ve subject CN
+func certIdentities(cert *x509.Certificate) []string {
+	var identities []string
+
+	for _, uri := range cert.URIs {
+		identities = append(identities, "uri:"+uri.String())
+	}
+	for _, name := range cert.DNSNames {
+		identities = append(identities, "dns:"+name)
+	}
+	for _, email := range cert.EmailAddresses {
+		identities = append(identities, "email:"+email)
+	}
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, "cn:"+cert.Subject.CommonName)
+	}
+
+	return identities
+}
+
+// TLS katmanında doğrulanmış istemci sertifikası varsa döner
+func verifiedClientCert(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return r.TLS.VerifiedChains[0][0]
+}
+
+var errCertIdentityNotMapped = errors.New("sertifika kimliği bir kullanıcıya eşlenmemiş")
+
+// Doğrulanmış sertifikanın eşlendiği kullanıcı adına Claims oluşturur. Kullanıcının rolü ve tenant'ı her istekte veritabanından okunur
+func authenticateClientCert(cert *x509.Certificate) (*Claims, error) {
+	mtlsIdentities.RLock()
+	var identity, username string
+	for _, candidate := range certIdentities(cert) {
+		if mapped, ok := mtlsIdentities.users[candidate]; ok {
+			identity, username = candidate, mapped
+			break
+		}
+	}
+	mtlsIdentities.RUnlock()
+
+	if username == "" {
+		return nil, errCertIdentityNotMapped
+	}
+
+	user, err := models.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Claims{
+		UserID:     user.ID,
+		TenantID:   user.TenantID,
+		Username:   user.Username,
+		Role:       user.Role,
+		ClientCert: identity,
+	}, nil
+}
+
+// Authorization başlığı olmayan ve doğrulanmış bir istemci sertifikası ile gelen isteği sertifikanın kimliğiyle doğrular
+func serveClientCert(c *gin.Context, cert *x509.Certificate) {
+	claims, err := authenticateClientCert(cert)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "İSTEMCİ SERTİFİKASI BİR KULLANICIYA EŞLENMEMİŞ"})
+		c.Abort()
+		return
+	}
+
+	c.Set("claims", claims)
+	c.Next()
+}
@@ -0,0 +1,158 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// Verilen şablonu parent ile imzalar. parent nil ise sertifika kendi kendini imzalar (CA)
+func issueCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Anahtar üretilemedi: %v", err)
+	}
+
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("Sertifika oluşturulamadı: %v", err)
+	}
+
+	cert, _ := x509.ParseCertificate(der)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (tc *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{tc.der}, PrivateKey: tc.key}
+}
+
+func TestClientCertificateAuth(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/logout", auth.TokenAuthMiddleware(), auth.Logout)
+
+	if _, err := models.CreateUser(models.User{Username: "billing-svc", Password: "servis-hesabi-1", TenantID: models.DefaultTenantID}); err != nil {
+		t.Fatalf("Kullanıcı eklenemedi: %v", err)
+	}
+
+	if err := auth.ConfigureMTLS(map[string]string{"uri:spiffe://mesh/ns/billing/sa/api": "billing-svc", "cn:reporting": "test"}); err != nil {
+		t.Fatalf("Sertifika eşlemeleri ayarlanamadı: %v", err)
+	}
+	defer auth.ConfigureMTLS(nil)
+
+	if err := auth.ConfigureMTLS(map[string]string{"spiffe://mesh": "test"}); err == nil {
+		t.Errorf("Türü belirtilmeyen kimlik kabul edildi")
+	}
+
+	ca := issueCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "Test CA"}, IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil)
+	otherCA := issueCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "Other CA"}, IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil)
+
+	server := issueCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "localhost"}, IPAddresses: []net.IP{net.ParseIP("127.0.0.1")}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, ca)
+
+	spiffeID, _ := url.Parse("spiffe://mesh/ns/billing/sa/api")
+	clientUsage := []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	billing := issueCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}, URIs: []*url.URL{spiffeID}, ExtKeyUsage: clientUsage}, ca)
+	reporting := issueCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "reporting"}, ExtKeyUsage: clientUsage}, ca)
+	unmapped := issueCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}, ExtKeyUsage: clientUsage}, ca)
+	foreign := issueCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "reporting"}, ExtKeyUsage: clientUsage}, otherCA)
+
+	serverKey, _ := x509.MarshalECPrivateKey(server.key)
+	config, err := auth.ServerTLSConfig(
+		writePEM(t, "server.pem", "CERTIFICATE", server.der),
+		writePEM(t, "server-key.pem", "EC PRIVATE KEY", serverKey),
+		writePEM(t, "ca.pem", "CERTIFICATE", ca.der),
+	)
+	if err != nil {
+		t.Fatalf("TLS ayarları oluşturulamadı: %v", err)
+	}
+
+	ts := httptest.NewUnstartedServer(r)
+	ts.TLS = config
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	request := func(method, path string, client *testCert, token string) int {
+		tlsConfig := &tls.Config{RootCAs: roots}
+		if client != nil {
+			tlsConfig.Certificates = []tls.Certificate{client.tlsCertificate()}
+		}
+		httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+		req, _ := http.NewRequest(method, ts.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		name     string
+		client   *testCert
+		expected int
+	}{
+		{"SPIFFE URI SAN", billing, http.StatusOK},
+		{"subject CN", reporting, http.StatusOK},
+		{"eşlenmemiş sertifika", unmapped, http.StatusUnauthorized},
+		{"sertifikasız istek", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		if code := request(http.MethodGet, "/secured", tt.client, ""); code != tt.expected {
+			t.Errorf("%s için beklenen kod %d, alınan %d", tt.name, tt.expected, code)
+		}
+	}
+
+	// Başka bir CA tarafından imzalanmış sertifika kimlik olarak kabul edilmemeli (TLS katmanında reddedilir veya hiç gönderilmez)
+	if code := request(http.MethodGet, "/secured", foreign, ""); code == http.StatusOK {
+		t.Errorf("Güvenilmeyen CA'nın sertifikası kabul edildi. Kod: %d", code)
+	}
+
+	// Sertifikası olmayan istemciler token ile devam edebilmeli
+	token := login(t, r, "test", "gizli1234")["token"].(string)
+	if code := request(http.MethodGet, "/secured", nil, token); code != http.StatusOK {
+		t.Errorf("Token ile istek reddedildi. Kod: %d", code)
+	}
+
+	if code := request(http.MethodPost, "/logout", billing, ""); code != http.StatusForbidden {
+		t.Errorf("İstemci sertifikası ile çıkış yapıldı. Kod: %d", code)
+	}
+}
@@ -0,0 +1,675 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const oauthCodeTTL = 5 * time.Minute
+
+type OAuthClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	Confidential bool     `json:"confidential"` // false ise public istemci (secret yok, PKCE zorunlu)
+	UserID       *int     `json:"user_id"`      // client_credentials ile alınan token'ların adına işlem yapacağı kullanıcı
+}
+
+// /oauth/authorize isteğinin parametreleri. Onay sayfası bunları gizli alanlarda geri gönderir
+type authorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+type consentPageData struct {
+	Request    authorizeRequest
+	ClientName string
+	Scopes     []string
+	Error      string
+}
+
+var consentPage = template.Must(template.New("consent").Parse(`<!DOCTYPE html>
+<html lang="tr">
+<head><meta charset="utf-8"><title>Yetkilendirme</title></head>
+<body>
+<h1>{{.ClientName}} hesabınıza erişmek istiyor</h1>
+{{if .Scopes}}<p>İstenen izinler:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{else}}<p>İstenen izinler: hesabınızın tüm yetkileri</p>{{end}}
+{{if .Error}}<p style="color:red">{{.Error}}</p>{{end}}
+<form method="post" action="/oauth/authorize">
+<input type="hidden" name="response_type" value="{{.Request.ResponseType}}">
+<input type="hidden" name="client_id" value="{{.Request.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.Request.RedirectURI}}">
+<input type="hidden" name="scope" value="{{.Request.Scope}}">
+<input type="hidden" name="state" value="{{.Request.State}}">
+<input type="hidden" name="code_challenge" value="{{.Request.CodeChallenge}}">
+<input type="hidden" name="code_challenge_method" value="{{.Request.CodeChallengeMethod}}">
+<p><label>Kullanıcı adı <input name="username" autocomplete="username"></label></p>
+<p><label>Şifre <input type="password" name="password" autocomplete="current-password"></label></p>
+<p><label>2FA kodu (etkinse) <input name="code" autocomplete="one-time-code"></label></p>
+<button type="submit" name="action" value="allow">İzin ver</button>
+<button type="submit" name="action" value="deny">Reddet</button>
+</form>
+</body>
+</html>`))
+
+var oauthErrorPage = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html lang="tr">
+<head><meta charset="utf-8"><title>Yetkilendirme hatası</title></head>
+<body><h1>Yetkilendirme hatası</h1><p>{{.}}</p></body>
+</html>`))
+
+func readAuthorizeRequest(c *gin.Context) authorizeRequest {
+	value := c.Query
+	if c.Request.Method == http.MethodPost {
+		value = c.PostForm
+	}
+
+	return authorizeRequest{
+		ResponseType:        value("response_type"),
+		ClientID:            value("client_id"),
+		RedirectURI:         value("redirect_uri"),
+		Scope:               value("scope"),
+		State:               value("state"),
+		CodeChallenge:       value("code_challenge"),
+		CodeChallengeMethod: value("code_challenge_method"),
+	}
+}
+
+func renderOAuthPage(c *gin.Context, status int, page *template.Template, data interface{}) {
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	page.Execute(c.Writer, data)
+}
+
+// İstemciyi ve yönlendirme adresini doğrular. Bunlar geçersizse kullanıcı yönlendirilmez, hata sayfası gösterilir (RFC 6749 4.1.2.1).
+// Diğer hatalar istemcinin yönlendirme adresine error parametresiyle bildirilir
+func validateAuthorizeRequest(c *gin.Context, req authorizeRequest) (models.OAuthClient, []string, bool) {
+	client, err := models.GetOAuthClient(req.ClientID)
+	if err != nil {
+		renderOAuthPage(c, http.StatusBadRequest, oauthErrorPage, "Geçersiz istemci")
+		return client, nil, false
+	}
+
+	if !containsScope(client.RedirectURIs, req.RedirectURI) {
+		renderOAuthPage(c, http.StatusBadRequest, oauthErrorPage, "Geçersiz yönlendirme adresi")
+		return client, nil, false
+	}
+
+	if req.ResponseType != "code" {
+		redirectWithParams(c, req.RedirectURI, url.Values{"error": {"unsupported_response_type"}, "state": {req.State}})
+		return client, nil, false
+	}
+
+	// PKCE tüm istemciler için zorunludur, sadece S256 desteklenir
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		redirectWithParams(c, req.RedirectURI, url.Values{"error": {"invalid_request"}, "error_description": {"code_challenge (S256) gerekli"}, "state": {req.State}})
+		return client, nil, false
+	}
+
+	scopes, err := narrowScopes(client.Scopes, strings.Fields(req.Scope))
+	if err != nil {
+		redirectWithParams(c, req.RedirectURI, url.Values{"error": {"invalid_scope"}, "state": {req.State}})
+		return client, nil, false
+	}
+
+	return client, scopes, true
+}
+
+func redirectWithParams(c *gin.Context, redirectURI string, params url.Values) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		renderOAuthPage(c, http.StatusBadRequest, oauthErrorPage, "Geçersiz yönlendirme adresi")
+		return
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	c.Redirect(http.StatusFound, u.String())
+}
+
+// @Summary OAuth2 authorization endpoint
+// @Description Shows the consent page of the authorization code flow. PKCE (code_challenge_method=S256) is required
+// @Tags oauth
+// @Produce html
+// @Param response_type query string true "code"
+// @Param client_id query string true "Client ID"
+// @Param redirect_uri query string true "Registered redirect URI"
+// @Param scope query string false "Space separated scopes"
+// @Param state query string false "Opaque value returned to the client"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "S256"
+// @Router /oauth/authorize [get]
+func OAuthAuthorize(c *gin.Context) {
+	req := readAuthorizeRequest(c)
+
+	client, scopes, ok := validateAuthorizeRequest(c, req)
+	if !ok {
+		return
+	}
+
+	renderOAuthPage(c, http.StatusOK, consentPage, consentPageData{Request: req, ClientName: client.Name, Scopes: scopes})
+}
+
+// @Summary OAuth2 consent form
+// @Description Authenticates the user on the consent page and redirects back to the client with an authorization code
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Router /oauth/authorize [post]
+func OAuthAuthorizeSubmit(c *gin.Context) {
+	req := readAuthorizeRequest(c)
+
+	client, scopes, ok := validateAuthorizeRequest(c, req)
+	if !ok {
+		return
+	}
+
+	if c.PostForm("action") != "allow" {
+		redirectWithParams(c, req.RedirectURI, url.Values{"error": {"access_denied"}, "state": {req.State}})
+		return
+	}
+
+	page := consentPageData{Request: req, ClientName: client.Name, Scopes: scopes}
+
+	user, err := checkCredentials(c.PostForm("username"), c.PostForm("password"), c.ClientIP())
+	if err != nil {
+		page.Error = "BİLİNMEYEN HATA"
+		if _, locked := err.(*loginLockedError); locked || err == errInvalidCredentials || err == errEmailNotVerified {
+			page.Error = err.Error()
+		}
+		renderOAuthPage(c, http.StatusUnauthorized, consentPage, page)
+		return
+	}
+
+	if message := verifyConsentSecondFactor(user, c.PostForm("code")); message != "" {
+		page.Error = message
+		renderOAuthPage(c, http.StatusUnauthorized, consentPage, page)
+		return
+	}
+
+	code, err := randomToken()
+	if err == nil {
+		err = models.CreateOAuthCode(hashToken(code), models.OAuthCode{
+			ClientID:      client.ID,
+			UserID:        user.ID,
+			RedirectURI:   req.RedirectURI,
+			Scopes:        scopes,
+			CodeChallenge: req.CodeChallenge,
+			ExpiresAt:     time.Now().Add(oauthCodeTTL),
+		})
+	}
+	if err != nil {
+		redirectWithParams(c, req.RedirectURI, url.Values{"error": {"server_error"}, "state": {req.State}})
+		return
+	}
+
+	redirectWithParams(c, req.RedirectURI, url.Values{"code": {code}, "state": {req.State}})
+}
+
+// 2FA etkin kullanıcılar onay sayfasında da kod girmelidir. Rolü 2FA gerektirip kurulum yapmamış kullanıcılar önce /login ile kurulum yapmalıdır
+func verifyConsentSecondFactor(user models.User, code string) string {
+	totp, err := models.GetUserTOTP(user.ID)
+	if err != nil && err != models.ErrTOTPNotFound {
+		return "BİLİNMEYEN HATA"
+	}
+
+	if err == nil && totp.Enabled {
+		wait, err := twoFactorLockedFor(user.ID)
+		if err != nil {
+			return "BİLİNMEYEN HATA"
+		}
+		if wait > 0 {
+			return errTooManyCodeAttempts
+		}
+
+		ok, err := checkSecondFactor(totp, code, "")
+		if err != nil {
+			return "BİLİNMEYEN HATA"
+		}
+		if !ok {
+			recordCodeFailure(user.ID, nil)
+			return "GEÇERSİZ 2FA KODU"
+		}
+		clearCodeFailures(user.ID)
+		return ""
+	}
+
+	required, err := models.IsTwoFactorRequired(user.Role)
+	if err != nil {
+		return "BİLİNMEYEN HATA"
+	}
+
+	if required {
+		return "2FA KURULUMU GEREKLİ"
+	}
+
+	return ""
+}
+
+// RFC 6749 5.2 hata yanıtı
+func oauthError(c *gin.Context, status int, code, description string) {
+	body := gin.H{"error": code}
+	if description != "" {
+		body["error_description"] = description
+	}
+
+	if status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, body)
+}
+
+// İstemciyi HTTP Basic veya form alanları (client_id, client_secret) ile doğrular. Public istemciler secret göndermez
+func authenticateClient(c *gin.Context) (models.OAuthClient, bool) {
+	clientID, secret, basic := c.Request.BasicAuth()
+	if basic {
+		// RFC 6749 2.3.1: Basic kimlik bilgileri form-urlencoded olarak kodlanır
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID = c.PostForm("client_id")
+		secret = c.PostForm("client_secret")
+	}
+
+	client, err := models.GetOAuthClient(clientID)
+	if err != nil {
+		oauthError(c, http.StatusUnauthorized, "invalid_client", "")
+		return client, false
+	}
+
+	if client.Confidential() {
+		if subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(client.SecretHash)) != 1 {
+			oauthError(c, http.StatusUnauthorized, "invalid_client", "")
+			return client, false
+		}
+	} else if secret != "" {
+		oauthError(c, http.StatusUnauthorized, "invalid_client", "")
+		return client, false
+	}
+
+	return client, true
+}
+
+func verifyPKCE(verifier, challenge string) bool {
+	if verifier == "" {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func writeTokenResponse(c *gin.Context, accessToken, refreshToken string, scopes []string) {
+	body := gin.H{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(accessTokenTTL.Seconds()),
+		"scope":        strings.Join(scopes, " "),
+	}
+	if refreshToken != "" {
+		body["refresh_token"] = refreshToken
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, body)
+}
+
+// @Summary OAuth2 token endpoint
+// @Description Supports the client_credentials, authorization_code (with PKCE) and refresh_token grants. Clients authenticate with HTTP Basic or client_id/client_secret form fields
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "client_credentials, authorization_code or refresh_token"
+// @Router /oauth/token [post]
+func OAuthToken(c *gin.Context) {
+	client, ok := authenticateClient(c)
+	if !ok {
+		return
+	}
+
+	switch c.PostForm("grant_type") {
+	case "client_credentials":
+		clientCredentialsGrant(c, client)
+	case "authorization_code":
+		authorizationCodeGrant(c, client)
+	case "refresh_token":
+		tokens, err := rotateRefreshToken(c.PostForm("refresh_token"), client.ID, strings.Fields(c.PostForm("scope")))
+		if err != nil {
+			switch err {
+			case errInvalidRefreshToken, errRefreshTokenExpired, errRefreshUserNotFound:
+				oauthError(c, http.StatusBadRequest, "invalid_grant", "")
+			case errInvalidScope:
+				oauthError(c, http.StatusBadRequest, "invalid_scope", "")
+			default:
+				oauthError(c, http.StatusInternalServerError, "server_error", "")
+			}
+			return
+		}
+		writeTokenResponse(c, tokens.AccessToken, tokens.RefreshToken, tokens.Scopes)
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "")
+	}
+}
+
+// client_credentials ile alınan token istemcinin servis kullanıcısı adına işlem yapar, refresh token verilmez
+func clientCredentialsGrant(c *gin.Context, client models.OAuthClient) {
+	if !client.Confidential() || client.UserID == nil {
+		oauthError(c, http.StatusBadRequest, "unauthorized_client", "")
+		return
+	}
+
+	scopes, err := narrowScopes(client.Scopes, strings.Fields(c.PostForm("scope")))
+	if err != nil {
+		oauthError(c, http.StatusBadRequest, "invalid_scope", "")
+		return
+	}
+
+	user, err := models.GetUserByID(*client.UserID, models.AllTenants)
+	if err != nil {
+		oauthError(c, http.StatusBadRequest, "unauthorized_client", "")
+		return
+	}
+
+	accessToken, err := generateAccessToken(user, "", scopes, client.ID)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	writeTokenResponse(c, accessToken, "", scopes)
+}
+
+func authorizationCodeGrant(c *gin.Context, client models.OAuthClient) {
+	codeHash := hashToken(c.PostForm("code"))
+
+	code, err := models.GetOAuthCode(codeHash)
+	if err != nil {
+		if err == models.ErrOAuthCodeNotFound {
+			oauthError(c, http.StatusBadRequest, "invalid_grant", "")
+			return
+		}
+		oauthError(c, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	// Kod ikinci kez kullanıldıysa çalınmış kabul edilir ve ilk kullanımda verilen token'lar iptal edilir (RFC 6749 4.1.2)
+	if code.UsedAt != nil {
+		if code.FamilyID != "" {
+			revokeReusedFamily(models.RefreshToken{UserID: code.UserID, FamilyID: code.FamilyID})
+		}
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "")
+		return
+	}
+
+	// Kod sadece doğrulamalar geçerse kullanılmış sayılır; başka istemci veya yanlış verifier ile yapılan istek kodu yakamaz
+	if code.ClientID != client.ID || code.RedirectURI != c.PostForm("redirect_uri") || time.Now().After(code.ExpiresAt) ||
+		!verifyPKCE(c.PostForm("code_verifier"), code.CodeChallenge) {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "")
+		return
+	}
+
+	user, err := models.GetUserByID(code.UserID, models.AllTenants)
+	if err != nil {
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "")
+		return
+	}
+
+	familyID := randomID()
+
+	consumed, err := models.ConsumeOAuthCode(codeHash, familyID)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	// Eş zamanlı başka bir istek kodu kullandı, o istekte verilen token'lar da iptal edilir
+	if !consumed {
+		if used, err := models.GetOAuthCode(codeHash); err == nil && used.FamilyID != "" {
+			revokeReusedFamily(models.RefreshToken{UserID: used.UserID, FamilyID: used.FamilyID})
+		}
+		oauthError(c, http.StatusBadRequest, "invalid_grant", "")
+		return
+	}
+
+	// İstemciye verilen yetki kullanıcının oturumları arasında görünür ve /me/sessions üzerinden iptal edilebilir
+	err = models.CreateSession(models.Session{
+		ID:        familyID,
+		UserID:    user.ID,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+		ClientID:  client.ID,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	tokens, err := issueClientTokens(user, familyID, code.Scopes, client.ID)
+	if err != nil {
+		oauthError(c, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+
+	writeTokenResponse(c, tokens.AccessToken, tokens.RefreshToken, tokens.Scopes)
+}
+
+// @Summary OAuth2 token introspection
+// @Description Returns whether an access or refresh token is active, with its metadata (RFC 7662). Only confidential clients may call it
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Token to inspect"
+// @Router /oauth/introspect [post]
+func OAuthIntrospect(c *gin.Context) {
+	client, ok := authenticateClient(c)
+	if !ok {
+		return
+	}
+
+	if !client.Confidential() {
+		oauthError(c, http.StatusUnauthorized, "invalid_client", "")
+		return
+	}
+
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+
+	if response := introspectAccessToken(token); response != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	if response := introspectRefreshToken(token); response != nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"active": false})
+}
+
+func introspectAccessToken(tokenString string) gin.H {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey)
+	if err != nil || !token.Valid || claims.Audience != "" || claims.TenantID == models.AllTenants || revocations.isRevoked(claims) {
+		return nil
+	}
+
+	return gin.H{
+		"active":     true,
+		"token_type": "access_token",
+		"scope":      strings.Join(claims.Scopes, " "),
+		"client_id":  claims.ClientID,
+		"username":   claims.Username,
+		"sub":        claims.UserID,
+		"exp":        claims.ExpiresAt,
+		"iat":        claims.IssuedAt,
+		"jti":        claims.Id,
+		"tenant_id":  claims.TenantID,
+		"role":       claims.Role,
+	}
+}
+
+func introspectRefreshToken(token string) gin.H {
+	stored, err := models.GetRefreshTokenByHash(hashToken(token))
+	if err != nil || stored.RevokedAt != nil || stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil
+	}
+
+	user, err := models.GetUserByID(stored.UserID, models.AllTenants)
+	if err != nil {
+		return nil
+	}
+
+	return gin.H{
+		"active":     true,
+		"token_type": "refresh_token",
+		"scope":      strings.Join(stored.Scopes, " "),
+		"client_id":  stored.ClientID,
+		"username":   user.Username,
+		"sub":        user.ID,
+		"exp":        stored.ExpiresAt.Unix(),
+		"iat":        stored.CreatedAt.Unix(),
+		"tenant_id":  user.TenantID,
+	}
+}
+
+// @Summary List OAuth2 clients
+// @Description Lists the registered OAuth2 clients (platform admin only)
+// @Tags oauth
+// @Produce json
+// @Router /api/v1/oauth/client [get]
+func GetOAuthClients(c *gin.Context) {
+	clients, err := models.GetOAuthClients()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstemciler alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": clients})
+}
+
+// @Summary Register an OAuth2 client
+// @Description Registers a new OAuth2 client (platform admin only). The secret of a confidential client is returned only once. client_credentials tokens act as the user given in user_id
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Param input body OAuthClientRequest true "Client"
+// @Router /api/v1/oauth/client [post]
+func CreateOAuthClient(c *gin.Context) {
+	var req OAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz giriş verisi"})
+		return
+	}
+
+	for _, redirectURI := range req.RedirectURIs {
+		u, err := url.Parse(redirectURI)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.Fragment != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz yönlendirme adresi: " + redirectURI})
+			return
+		}
+	}
+
+	if !req.Confidential && len(req.RedirectURIs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Public istemci için yönlendirme adresi gerekli"})
+		return
+	}
+
+	scopes, err := validateScopes(req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ SCOPE"})
+		return
+	}
+
+	if req.UserID != nil {
+		claims := c.MustGet("claims").(*Claims)
+		if _, err := models.GetUserByID(*req.UserID, claims.TenantID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Kullanıcı Bulunamadı"})
+			return
+		}
+	}
+
+	client := models.OAuthClient{
+		ID:           randomID(),
+		Name:         strings.TrimSpace(req.Name),
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       scopes,
+		UserID:       req.UserID,
+	}
+
+	var secret string
+	if req.Confidential {
+		if secret, err = randomToken(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "İstemci oluşturulamadı"})
+			return
+		}
+		client.SecretHash = hashToken(secret)
+	}
+
+	if err := models.CreateOAuthClient(client); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstemci oluşturulamadı"})
+		return
+	}
+
+	response := gin.H{"message": "İstemci oluşturuldu", "client_id": client.ID}
+	if secret != "" {
+		response["client_secret"] = secret
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Delete an OAuth2 client
+// @Description Deletes an OAuth2 client and revokes its refresh tokens and the sessions granted to it (platform admin only)
+// @Tags oauth
+// @Produce json
+// @Param id path string true "Client ID"
+// @Router /api/v1/oauth/client/{id} [delete]
+func DeleteOAuthClient(c *gin.Context) {
+	if err := models.DeleteOAuthClient(c.Param("id")); err != nil {
+		if err == models.ErrOAuthClientNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "İstemci bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "İstemci silinemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "İstemci silindi"})
+}
@@ -0,0 +1,309 @@
+package auth_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+func postForm(r *gin.Engine, path string, form url.Values, clientID, secret string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if secret != "" {
+		req.SetBasicAuth(clientID, secret)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func setupOAuthRouter() *gin.Engine {
+	r := setupRouter()
+	r.GET("/oauth/authorize", auth.OAuthAuthorize)
+	r.POST("/oauth/authorize", auth.OAuthAuthorizeSubmit)
+	r.POST("/oauth/token", auth.OAuthToken)
+	r.POST("/oauth/introspect", auth.OAuthIntrospect)
+	return r
+}
+
+func TestOAuthAuthorizationCodeWithPKCE(t *testing.T) {
+	setupTestDB(t)
+	r := setupOAuthRouter()
+
+	if err := models.CreateOAuthClient(models.OAuthClient{ID: "spa", Name: "Dashboard", RedirectURIs: []string{"https://app.test/callback"}, Scopes: []string{auth.ScopePersonRead}}); err != nil {
+		t.Fatalf("İstemci oluşturulamadı: %v", err)
+	}
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"spa"},
+		"redirect_uri":          {"https://app.test/callback"},
+		"state":                 {"xyz"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+params.Encode(), nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Dashboard") {
+		t.Fatalf("Onay sayfası gösterilmedi. Kod: %d", w.Code)
+	}
+
+	// Kayıtlı olmayan yönlendirme adresine yönlendirme yapılmaz
+	bad := url.Values{"response_type": {"code"}, "client_id": {"spa"}, "redirect_uri": {"https://evil.test/"}}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oauth/authorize?"+bad.Encode(), nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Geçersiz yönlendirme adresi kabul edildi. Kod: %d", w.Code)
+	}
+
+	form := url.Values{}
+	for k, v := range params {
+		form[k] = v
+	}
+	form.Set("username", "test")
+	form.Set("password", "gizli1234")
+	form.Set("action", "allow")
+
+	w, _ = postForm(r, "/oauth/authorize", form, "", "")
+	location, err := url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusFound || err != nil || location.Query().Get("state") != "xyz" || location.Query().Get("code") == "" {
+		t.Fatalf("Yetkilendirme kodu alınamadı. Kod: %d, Location: %s", w.Code, w.Header().Get("Location"))
+	}
+	code := location.Query().Get("code")
+
+	exchange := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {"spa"},
+		"code":          {code},
+		"redirect_uri":  {"https://app.test/callback"},
+		"code_verifier": {"yanlis-verifier"},
+	}
+	if w, resp := postForm(r, "/oauth/token", exchange, "", ""); w.Code != http.StatusBadRequest || resp["error"] != "invalid_grant" {
+		t.Errorf("Yanlış code_verifier kabul edildi. Kod: %d, Yanıt: %v", w.Code, resp)
+	}
+
+	// Başarısız deneme kodu yakmaz, aynı kod doğru verifier ile kullanılabilir
+	exchange.Set("code_verifier", verifier)
+
+	w, resp := postForm(r, "/oauth/token", exchange, "", "")
+	if w.Code != http.StatusOK || resp["access_token"] == nil || resp["refresh_token"] == nil || resp["scope"] != auth.ScopePersonRead {
+		t.Fatalf("Kod token ile değiştirilemedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	if code := getSecured(r, resp["access_token"].(string)); code != http.StatusOK {
+		t.Errorf("OAuth access token ile erişilemedi. Kod: %d", code)
+	}
+
+	// İstemciye verilen refresh token /token/refresh ile kullanılamaz, /oauth/token ile yenilenir
+	if w, _ := postJSON(r, "/token/refresh", map[string]string{"refresh_token": resp["refresh_token"].(string)}); w.Code != http.StatusUnauthorized {
+		t.Errorf("İstemci refresh token'ı /token/refresh ile kullanıldı. Kod: %d", w.Code)
+	}
+
+	w, resp = postForm(r, "/oauth/token", url.Values{"grant_type": {"refresh_token"}, "client_id": {"spa"}, "refresh_token": {resp["refresh_token"].(string)}}, "", "")
+	if w.Code != http.StatusOK || resp["access_token"] == nil {
+		t.Errorf("refresh_token grant başarısız. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	// Kullanılmış kod tekrar gönderilirse ilk kullanımda verilen token'lar iptal edilir
+	if w, resp := postForm(r, "/oauth/token", exchange, "", ""); w.Code != http.StatusBadRequest || resp["error"] != "invalid_grant" {
+		t.Errorf("Kullanılmış kod kabul edildi. Kod: %d, Yanıt: %v", w.Code, resp)
+	}
+	if code := getSecured(r, resp["access_token"].(string)); code != http.StatusUnauthorized {
+		t.Errorf("Kod tekrar kullanıldıktan sonra access token geçerli kaldı. Kod: %d", code)
+	}
+}
+
+// Yetkilendirme kodu akışıyla "spa" istemcisine verilen token'ları döner
+func authorizationCodeTokens(t *testing.T, r *gin.Engine) map[string]interface{} {
+	t.Helper()
+
+	if err := models.CreateOAuthClient(models.OAuthClient{ID: "spa", Name: "Dashboard", RedirectURIs: []string{"https://app.test/callback"}, Scopes: []string{auth.ScopePersonRead}}); err != nil {
+		t.Fatalf("İstemci oluşturulamadı: %v", err)
+	}
+
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+
+	form := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"spa"},
+		"redirect_uri":          {"https://app.test/callback"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+		"username":              {"test"},
+		"password":              {"gizli1234"},
+		"action":                {"allow"},
+	}
+
+	w, _ := postForm(r, "/oauth/authorize", form, "", "")
+	location, err := url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusFound || err != nil || location.Query().Get("code") == "" {
+		t.Fatalf("Yetkilendirme kodu alınamadı. Kod: %d, Location: %s", w.Code, w.Header().Get("Location"))
+	}
+
+	exchange := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {"spa"},
+		"code":          {location.Query().Get("code")},
+		"redirect_uri":  {"https://app.test/callback"},
+		"code_verifier": {verifier},
+	}
+
+	w, resp := postForm(r, "/oauth/token", exchange, "", "")
+	if w.Code != http.StatusOK || resp["access_token"] == nil {
+		t.Fatalf("Kod token ile değiştirilemedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+	return resp
+}
+
+func TestOAuthGrantListedAsSession(t *testing.T) {
+	setupTestDB(t)
+	r := setupOAuthRouter()
+	r.GET("/me/sessions", auth.TokenAuthMiddleware(), auth.GetMySessions)
+	r.DELETE("/me/sessions/:id", auth.TokenAuthMiddleware(), auth.RevokeMySession)
+
+	tokens := authorizationCodeTokens(t, r)
+	userToken := login(t, r, "test", "gizli1234")["token"].(string)
+
+	request := func(method, path string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	_, resp := request(http.MethodGet, "/me/sessions")
+	sessions, _ := resp["data"].([]interface{})
+
+	grantID := ""
+	for _, s := range sessions {
+		if session := s.(map[string]interface{}); session["client_id"] == "spa" {
+			grantID = session["id"].(string)
+		}
+	}
+	if len(sessions) != 2 || grantID == "" {
+		t.Fatalf("İstemciye verilen yetki oturumlarda listelenmedi: %v", resp)
+	}
+
+	// Yetki iptal edilince istemcinin access ve refresh token'ları geçersiz olur
+	if code, _ := request(http.MethodDelete, "/me/sessions/"+grantID); code != http.StatusOK {
+		t.Fatalf("İstemci oturumu sonlandırılamadı. Kod: %d", code)
+	}
+	if code := getSecured(r, tokens["access_token"].(string)); code != http.StatusUnauthorized {
+		t.Errorf("İptal edilen yetkinin access token'ı geçerli kaldı. Kod: %d", code)
+	}
+
+	refresh := url.Values{"grant_type": {"refresh_token"}, "client_id": {"spa"}, "refresh_token": {tokens["refresh_token"].(string)}}
+	if w, _ := postForm(r, "/oauth/token", refresh, "", ""); w.Code == http.StatusOK {
+		t.Errorf("İptal edilen yetkinin refresh token'ı kullanıldı")
+	}
+}
+
+// OAuth istemcisine verilen token ile hesap ayarları değiştirilemez, yeni kimlik bilgisi alınamaz
+func TestOAuthClientTokenCannotManageAccount(t *testing.T) {
+	setupTestDB(t)
+	r := setupOAuthRouter()
+	r.POST("/logout", auth.TokenAuthMiddleware(), auth.Logout)
+	r.POST("/apikey", auth.TokenAuthMiddleware(), auth.CreateAPIKey)
+	r.POST("/2fa/enroll", auth.TokenAuthMiddleware(), auth.EnrollTwoFactor)
+	r.POST("/2fa/confirm", auth.TokenAuthMiddleware(), auth.ConfirmTwoFactor)
+	r.POST("/2fa/disable", auth.TokenAuthMiddleware(), auth.DisableTwoFactor)
+	r.POST("/oidc/link", auth.TokenAuthMiddleware(), auth.OIDCLink)
+	r.DELETE("/me/sessions", auth.TokenAuthMiddleware(), auth.RevokeMyOtherSessions)
+	r.DELETE("/me/sessions/:id", auth.TokenAuthMiddleware(), auth.RevokeMySession)
+	r.POST("/api/v1/user/:id/impersonate", auth.TokenAuthMiddleware(), auth.ImpersonateUser)
+	r.POST("/api/v1/partner", auth.TokenAuthMiddleware(), auth.CreatePartner)
+	r.POST("/api/v1/partner/:id/rotate", auth.TokenAuthMiddleware(), auth.RotatePartnerSecret)
+
+	token := authorizationCodeTokens(t, r)["access_token"].(string)
+
+	routes := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{http.MethodPost, "/logout", nil},
+		{http.MethodPost, "/apikey", map[string]interface{}{"name": "kalıcı"}},
+		{http.MethodPost, "/2fa/enroll", nil},
+		{http.MethodPost, "/2fa/confirm", map[string]string{"code": "123456"}},
+		{http.MethodPost, "/2fa/disable", map[string]string{"code": "123456"}},
+		{http.MethodPost, "/oidc/link", nil},
+		{http.MethodDelete, "/me/sessions", nil},
+		{http.MethodDelete, "/me/sessions/diger", nil},
+		{http.MethodPost, "/api/v1/user/1/impersonate", map[string]string{"reason": "destek"}},
+		{http.MethodPost, "/api/v1/partner", map[string]interface{}{"name": "partner", "user_id": 1}},
+		{http.MethodPost, "/api/v1/partner/p1/rotate", nil},
+	}
+
+	for _, route := range routes {
+		payload, _ := json.Marshal(route.body)
+		req := httptest.NewRequest(route.method, route.path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s OAuth istemci token'ı ile yapıldı. Kod: %d, Yanıt: %s", route.method, route.path, w.Code, w.Body.String())
+		}
+	}
+
+	// Token istemcinin izin verilen kapsamlarında kullanılmaya devam eder
+	if code := getSecured(r, token); code != http.StatusOK {
+		t.Errorf("OAuth access token ile erişilemedi. Kod: %d", code)
+	}
+}
+
+func TestOAuthClientCredentialsAndIntrospection(t *testing.T) {
+	setupTestDB(t)
+	r := setupOAuthRouter()
+
+	userID := 1
+	secretHash := sha256.Sum256([]byte("gizli"))
+	err := models.CreateOAuthClient(models.OAuthClient{ID: "batch", Name: "Batch", SecretHash: hex.EncodeToString(secretHash[:]), UserID: &userID})
+	if err != nil {
+		t.Fatalf("İstemci oluşturulamadı: %v", err)
+	}
+
+	if w, _ := postForm(r, "/oauth/token", url.Values{"grant_type": {"client_credentials"}}, "batch", "yanlis"); w.Code != http.StatusUnauthorized {
+		t.Errorf("Yanlış secret kabul edildi. Kod: %d", w.Code)
+	}
+
+	w, resp := postForm(r, "/oauth/token", url.Values{"grant_type": {"client_credentials"}, "scope": {"person:read"}}, "batch", "gizli")
+	if w.Code != http.StatusOK || resp["access_token"] == nil || resp["refresh_token"] != nil {
+		t.Fatalf("client_credentials başarısız. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	w, info := postForm(r, "/oauth/introspect", url.Values{"token": {resp["access_token"].(string)}}, "batch", "gizli")
+	if w.Code != http.StatusOK || info["active"] != true || info["client_id"] != "batch" || info["scope"] != "person:read" || info["username"] != "test" {
+		t.Errorf("Introspection hatalı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	if _, info := postForm(r, "/oauth/introspect", url.Values{"token": {"gecersiz"}}, "batch", "gizli"); info["active"] != false {
+		t.Errorf("Geçersiz token aktif gösterildi: %v", info)
+	}
+}
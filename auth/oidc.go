@@ -0,0 +1,694 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const (
+	oidcStateTTL = 10 * time.Minute
+	// Aynı anda bekleyebilecek (callback'i gelmemiş) giriş isteği sayısı
+	oidcPendingLimit = 10000
+	// Bir IP'nin oidcStateTTL içinde başlatabileceği en fazla giriş, böylece tek bir istemci genel sınırı tüketemez
+	oidcPendingPerIP = 50
+	// State, girişi başlatan tarayıcıya bu cookie ile bağlanır; callback'te cookie'deki state ile
+	// sağlayıcıdan dönen state aynı olmalıdır (login CSRF'e karşı)
+	oidcStateCookie = "oidc_state"
+)
+
+// Harici OpenID Connect kimlik sağlayıcısı ayarları
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string            // Sağlayıcıya kayıtlı /oidc/callback adresi
+	Scopes       []string          // Varsayılan: openid email profile
+	RoleClaim    string            // Rolün okunacağı ID token claim'i (örn. "groups")
+	RoleMap      map[string]string // Claim değeri -> yerel rol
+	DefaultRole  string            // Eşleşme yoksa verilen rol (varsayılan: user)
+	TenantID     int               // Yeni kullanıcıların tenant'ı (varsayılan: default tenant)
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcProvider struct {
+	mu        sync.Mutex
+	config    OIDCConfig
+	discovery *oidcDiscovery
+	keys      map[string]interface{}
+	client    *http.Client
+}
+
+var oidc struct {
+	sync.RWMutex
+	provider *oidcProvider
+}
+
+// OIDC girişini etkinleştirir. Discovery belgesi ilk kullanımda okunur
+func ConfigureOIDC(config OIDCConfig) {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	if config.DefaultRole == "" {
+		config.DefaultRole = "user"
+	}
+	if config.TenantID == 0 {
+		config.TenantID = models.DefaultTenantID
+	}
+
+	oidc.Lock()
+	oidc.provider = &oidcProvider{
+		config: config,
+		keys:   map[string]interface{}{},
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	oidc.Unlock()
+}
+
+// OIDC_ISSUER tanımlıysa OIDC girişini ortam değişkenlerinden yapılandırır
+func LoadOIDCFromEnv() error {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil
+	}
+
+	config := OIDCConfig{
+		Issuer:       issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		Scopes:       strings.Fields(os.Getenv("OIDC_SCOPES")),
+		RoleClaim:    os.Getenv("OIDC_ROLE_CLAIM"),
+		RoleMap:      map[string]string{},
+		DefaultRole:  os.Getenv("OIDC_DEFAULT_ROLE"),
+	}
+
+	if config.ClientID == "" || config.RedirectURL == "" {
+		return errors.New("OIDC_CLIENT_ID ve OIDC_REDIRECT_URL gerekli")
+	}
+
+	if list := os.Getenv("OIDC_ROLE_MAP"); list != "" {
+		for _, entry := range strings.Split(list, ",") {
+			parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("geçersiz OIDC_ROLE_MAP girdisi: %q", entry)
+			}
+			config.RoleMap[parts[0]] = parts[1]
+		}
+	}
+
+	if tenant := os.Getenv("OIDC_TENANT_ID"); tenant != "" {
+		id, err := strconv.Atoi(tenant)
+		if err != nil {
+			return fmt.Errorf("geçersiz OIDC_TENANT_ID: %v", err)
+		}
+		config.TenantID = id
+	}
+
+	ConfigureOIDC(config)
+	return nil
+}
+
+func currentOIDCProvider() *oidcProvider {
+	oidc.RLock()
+	defer oidc.RUnlock()
+	return oidc.provider
+}
+
+func (p *oidcProvider) getJSON(endpoint string, target interface{}) error {
+	resp, err := p.client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: beklenmeyen durum kodu %d", endpoint, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func (p *oidcProvider) loadDiscovery() (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+
+	if discovery.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("discovery issuer uyuşmuyor: %s", discovery.Issuer)
+	}
+
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("desteklenmeyen eğri: %s", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+
+	return nil, fmt.Errorf("desteklenmeyen anahtar tipi: %s", k.Kty)
+}
+
+// Sağlayıcının JWKS'ini okur. Bilinmeyen bir kid geldiğinde anahtar rotasyonu için yeniden okunur
+func (p *oidcProvider) key(kid string) (interface{}, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	discovery, err := p.loadDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(discovery.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		public, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("OIDC anahtarı atlandı (kid: %s): %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = public
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("bilinmeyen anahtar: %s", kid)
+}
+
+// Sağlayıcının yetkilendirme adresini state, nonce ve PKCE ile oluşturur. İstek veritabanına kaydedilir, dönen state
+// tarayıcıya cookie olarak verilmelidir
+func (p *oidcProvider) authorizationURL(linkUserID int) (string, string, error) {
+	discovery, err := p.loadDiscovery()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	pending := models.OIDCState{Nonce: nonce, Verifier: verifier, LinkUserID: linkUserID, ExpiresAt: time.Now().Add(oidcStateTTL)}
+	if err := models.SaveOIDCState(hashToken(state), pending, oidcPendingLimit); err != nil {
+		return "", "", err
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(sum[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), state, nil
+}
+
+// Girişi başlatan isteğe state cookie'sini ekler ve yetkilendirme adresini döner. Hata durumunda yanıtı yazıp false döner
+func startOIDCAuthorization(c *gin.Context, provider *oidcProvider, linkUserID int) (string, bool) {
+	// IP sayacı giriş denemeleriyle aynı tabloda (login_attempt) tutulur ve state'lerle aynı sürede sıfırlanır
+	deleteExpiredLoginAttempts()
+	count, err := models.IncrementLoginAttempt("oidc-ip:"+c.ClientIP(), oidcStateTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return "", false
+	}
+
+	if count > oidcPendingPerIP {
+		c.Header("Retry-After", strconv.Itoa(int(oidcStateTTL.Seconds())))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "ÇOK FAZLA İSTEK, DAHA SONRA TEKRAR DENEYİN"})
+		return "", false
+	}
+
+	authURL, state, err := provider.authorizationURL(linkUserID)
+	if err != nil {
+		if err == models.ErrTooManyOIDCStates {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ÇOK FAZLA BEKLEYEN GİRİŞ, DAHA SONRA TEKRAR DENEYİN"})
+			return "", false
+		}
+		log.Println("OIDC yetkilendirme adresi oluşturulamadı:", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "KİMLİK SAĞLAYICIYA ULAŞILAMADI"})
+		return "", false
+	}
+
+	// Sağlayıcıdan dönüş üst seviye bir GET yönlendirmesi olduğu için SameSite=Lax cookie gönderilir
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, state, int(oidcStateTTL.Seconds()), "/oidc", "", true, true)
+
+	return authURL, true
+}
+
+// Callback'teki state'in bu tarayıcıda başlatılan girişe ait olduğunu doğrulayıp bekleyen isteği döner. State tek kullanımlıktır
+func takeOIDCState(c *gin.Context) (models.OIDCState, bool) {
+	state := c.Query("state")
+	cookie, err := c.Cookie(oidcStateCookie)
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, "", -1, "/oidc", "", true, true)
+
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		return models.OIDCState{}, false
+	}
+
+	pending, err := models.TakeOIDCState(hashToken(state))
+	if err != nil {
+		if err != models.ErrOIDCStateNotFound {
+			log.Println("OIDC state okunamadı:", err)
+		}
+		return models.OIDCState{}, false
+	}
+
+	return pending, true
+}
+
+// Yetkilendirme kodunu sağlayıcının token endpoint'inde ID token ile değiştirir
+func (p *oidcProvider) exchangeCode(code, verifier string) (string, error) {
+	discovery, err := p.loadDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK || body.IDToken == "" {
+		return "", fmt.Errorf("token isteği başarısız: %d %s", resp.StatusCode, body.Error)
+	}
+
+	return body.IDToken, nil
+}
+
+// ID token'ın imzasını sağlayıcının JWKS'i ile, ardından issuer, audience, süre ve nonce değerlerini doğrular
+func (p *oidcProvider) verifyIDToken(rawToken, nonce string) (jwt.MapClaims, error) {
+	discovery, err := p.loadDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.key(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key.(type) {
+		case *rsa.PublicKey:
+			if token.Method != jwt.SigningMethodRS256 {
+				return nil, errors.New("beklenmeyen imza algoritması")
+			}
+		case *ecdsa.PublicKey:
+			if token.Method != jwt.SigningMethodES256 {
+				return nil, errors.New("beklenmeyen imza algoritması")
+			}
+		}
+
+		return key, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("geçersiz ID token: %v", err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != discovery.Issuer {
+		return nil, errors.New("ID token issuer uyuşmuyor")
+	}
+
+	if !audienceContains(claims["aud"], p.config.ClientID) {
+		return nil, errors.New("ID token audience uyuşmuyor")
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, errors.New("ID token süresi belirtilmemiş")
+	}
+
+	if n, _ := claims["nonce"].(string); n == "" || n != nonce {
+		return nil, errors.New("ID token nonce uyuşmuyor")
+	}
+
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("ID token subject içermiyor")
+	}
+
+	return claims, nil
+}
+
+// aud claim'i tek bir değer veya liste olabilir
+func audienceContains(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ID token'daki rol claim'ini (tek değer veya liste) yerel role eşler
+func (p *oidcProvider) mapRole(claims jwt.MapClaims) string {
+	if p.config.RoleClaim == "" {
+		return p.config.DefaultRole
+	}
+
+	var values []string
+	switch v := claims[p.config.RoleClaim].(type) {
+	case string:
+		values = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	// Birden fazla eşleşme varsa admin rolü önceliklidir
+	role := ""
+	for _, value := range values {
+		if mapped, ok := p.config.RoleMap[value]; ok {
+			if mapped == "admin" {
+				return mapped
+			}
+			if role == "" {
+				role = mapped
+			}
+		}
+	}
+
+	if role == "" {
+		return p.config.DefaultRole
+	}
+	return role
+}
+
+// İlk girişte kullanıcıyı oluşturur; sonraki girişlerde rolü sağlayıcıdaki rol eşlemesiyle günceller
+func (p *oidcProvider) userForClaims(claims jwt.MapClaims) (models.User, error) {
+	issuer, _ := claims["iss"].(string)
+	subject, _ := claims["sub"].(string)
+	role := p.mapRole(claims)
+
+	if exists, err := models.RoleExists(role); err != nil {
+		return models.User{}, err
+	} else if !exists {
+		log.Printf("OIDC rol eşlemesi tanımsız bir role işaret ediyor: %s", role)
+		role = "user"
+	}
+
+	user, err := models.GetUserByIdentity(issuer, subject)
+	if err == nil {
+		if p.config.RoleClaim != "" && user.Role != role {
+			if err := models.SetUserRole(user.ID, role); err != nil {
+				return models.User{}, err
+			}
+			user.Role = role
+		}
+		return user, nil
+	}
+
+	if err != models.ErrIdentityNotFound {
+		return models.User{}, err
+	}
+
+	email, _ := claims["email"].(string)
+	username, _ := claims["preferred_username"].(string)
+	if username == "" {
+		username = strings.SplitN(email, "@", 2)[0]
+	}
+	if username == "" {
+		username = "oidc-" + subject
+	}
+
+	// Hesaba sadece kimlik sağlayıcı üzerinden girilebilmesi için şifre rastgele ve bilinmeyen bir değerdir
+	password, err := randomToken()
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return models.ProvisionExternalUser(models.User{
+		Username: username,
+		Email:    email,
+		Password: password,
+		Role:     role,
+		TenantID: p.config.TenantID,
+	}, issuer, subject)
+}
+
+// @Summary Log in with the OpenID Connect provider
+// @Description Redirects to the configured OIDC provider. After the user signs in, the provider redirects to /oidc/callback which returns the tokens
+// @Tags oidc
+// @Router /oidc/login [get]
+func OIDCLogin(c *gin.Context) {
+	provider := currentOIDCProvider()
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "OIDC YAPILANDIRILMAMIŞ"})
+		return
+	}
+
+	authURL, ok := startOIDCAuthorization(c, provider, 0)
+	if !ok {
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary Link the OpenID Connect account
+// @Description Returns the provider URL that links the logged in local account to the user's OIDC identity. After linking, the user can log in through /oidc/login
+// @Tags oidc
+// @Produce json
+// @Router /oidc/link [post]
+func OIDCLink(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	provider := currentOIDCProvider()
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "OIDC YAPILANDIRILMAMIŞ"})
+		return
+	}
+
+	authURL, ok := startOIDCAuthorization(c, provider, claims.UserID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"authorization_url": authURL})
+}
+
+// @Summary OpenID Connect callback
+// @Description Exchanges the authorization code, verifies the ID token and returns tokens, or a 2FA challenge like /login when the account requires a second factor. Users signing in for the first time are created with the role mapped from the provider claims
+// @Tags oidc
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State"
+// @Router /oidc/callback [get]
+func OIDCCallback(c *gin.Context) {
+	provider := currentOIDCProvider()
+	if provider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "OIDC YAPILANDIRILMAMIŞ"})
+		return
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "KİMLİK SAĞLAYICI GİRİŞİ REDDETTİ", "provider_error": errCode})
+		return
+	}
+
+	pending, ok := takeOIDCState(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ STATE"})
+		return
+	}
+
+	idToken, err := provider.exchangeCode(c.Query("code"), pending.Verifier)
+	if err != nil {
+		log.Println("OIDC kod değişimi başarısız:", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "KİMLİK SAĞLAYICI GİRİŞİ BAŞARISIZ"})
+		return
+	}
+
+	claims, err := provider.verifyIDToken(idToken, pending.Nonce)
+	if err != nil {
+		log.Println("OIDC ID token doğrulanamadı:", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ ID TOKEN"})
+		return
+	}
+
+	if pending.LinkUserID != 0 {
+		issuer, _ := claims["iss"].(string)
+		subject, _ := claims["sub"].(string)
+		if err := models.LinkIdentity(pending.LinkUserID, issuer, subject); err != nil {
+			if err == models.ErrIdentityLinked {
+				c.JSON(http.StatusConflict, gin.H{"error": "BU KİMLİK BAŞKA BİR HESABA BAĞLI"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "HESAP BAĞLANAMADI"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "HESAP BAĞLANDI"})
+		return
+	}
+
+	user, err := provider.userForClaims(claims)
+	if err != nil {
+		log.Println("OIDC kullanıcısı oluşturulamadı:", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "KULLANICI OLUŞTURULAMADI"})
+		return
+	}
+
+	// Şifreyle girişte olduğu gibi 2FA etkinse veya rolü 2FA gerektiriyorsa token yerine challenge döner
+	if startTwoFactor(c, user, nil) {
+		return
+	}
+
+	tokens, err := startSession(c, user, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "TOKEN OLUŞTURULAMADI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "BAŞARILI GİRİŞ",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
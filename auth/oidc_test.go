@@ -0,0 +1,292 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+// Testler için yerel OpenID Connect sağlayıcısı. Yetkilendirme kodu olarak "mock-code" kabul eder
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims // Bir sonraki ID token'ın claim'leri
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("RSA anahtarı üretilemedi: %v", err)
+	}
+
+	m := &mockOIDCProvider{key: key}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "mock",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if r.PostFormValue("code") != "mock-code" || r.PostFormValue("code_verifier") == "" || clientID != "webservice" || secret != "gizli" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, m.claims)
+		token.Header["kid"] = "mock"
+		signed, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "access_token": "x", "token_type": "Bearer"})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// /oidc/login veya /oidc/link'in döndüğü sağlayıcı adresinden state ve nonce'u alıp ID token claim'lerini hazırlar
+func (m *mockOIDCProvider) authorize(t *testing.T, authURL string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	if err != nil || u.Query().Get("code_challenge") == "" {
+		t.Fatalf("Geçersiz yetkilendirme adresi: %s", authURL)
+	}
+
+	claims["iss"] = m.server.URL
+	claims["aud"] = "webservice"
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+	claims["nonce"] = u.Query().Get("nonce")
+	m.claims = claims
+
+	return u.Query().Get("state")
+}
+
+func TestOIDCLogin(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.GET("/oidc/login", auth.OIDCLogin)
+	r.GET("/oidc/callback", auth.OIDCCallback)
+	r.POST("/oidc/link", auth.TokenAuthMiddleware(), auth.OIDCLink)
+
+	if err := models.CreateRole(models.Role{Name: "admin"}); err != nil {
+		t.Fatalf("Rol eklenemedi: %v", err)
+	}
+
+	provider := newMockOIDCProvider(t)
+	auth.ConfigureOIDC(auth.OIDCConfig{
+		Issuer:       provider.server.URL,
+		ClientID:     "webservice",
+		ClientSecret: "gizli",
+		RedirectURL:  "http://localhost:8080/oidc/callback",
+		RoleClaim:    "groups",
+		RoleMap:      map[string]string{"yoneticiler": "admin"},
+	})
+
+	// Sağlayıcıdan dönüşte tarayıcı girişi başlatan yanıttaki cookie'leri gönderir
+	callback := func(state string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/oidc/callback?code=mock-code&state="+url.QueryEscape(state), nil)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	startLogin := func(claims jwt.MapClaims) (string, []*http.Cookie) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+		if w.Code != http.StatusFound {
+			t.Fatalf("Sağlayıcıya yönlendirilmedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+		}
+
+		cookies := w.Result().Cookies()
+		if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure || cookies[0].SameSite != http.SameSiteLaxMode {
+			t.Fatalf("State cookie'si beklendiği gibi verilmedi: %v", cookies)
+		}
+
+		return provider.authorize(t, w.Header().Get("Location"), claims), cookies
+	}
+
+	oidcLogin := func(claims jwt.MapClaims) (int, map[string]interface{}) {
+		w := callback(startLogin(claims))
+
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	// İlk girişte kullanıcı rol eşlemesiyle oluşturulur
+	code, resp := oidcLogin(jwt.MapClaims{"sub": "ayse-1", "email": "ayse@sirket.com", "preferred_username": "ayse", "groups": []string{"yoneticiler"}})
+	if code != http.StatusOK || resp["token"] == nil {
+		t.Fatalf("OIDC girişi başarısız. Kod: %d, Yanıt: %v", code, resp)
+	}
+
+	user, err := models.GetUserByIdentity(provider.server.URL, "ayse-1")
+	if err != nil || user.Username != "ayse" || user.Role != "admin" {
+		t.Fatalf("Kullanıcı beklendiği gibi oluşturulmadı. Kullanıcı: %+v, Hata: %v", user, err)
+	}
+
+	// Sonraki girişte aynı kullanıcı kullanılır, rol sağlayıcıdan güncellenir
+	if code, _ := oidcLogin(jwt.MapClaims{"sub": "ayse-1", "preferred_username": "ayse", "groups": []string{}}); code != http.StatusOK {
+		t.Fatalf("İkinci OIDC girişi başarısız. Kod: %d", code)
+	}
+
+	if updated, _ := models.GetUserByIdentity(provider.server.URL, "ayse-1"); updated.ID != user.ID || updated.Role != "user" {
+		t.Errorf("Mevcut kullanıcı güncellenmedi: %+v", updated)
+	}
+
+	// Nonce'u farklı (başka bir istek için üretilmiş) ID token reddedilir
+	state, cookies := startLogin(jwt.MapClaims{"sub": "ayse-1"})
+	provider.claims["nonce"] = "baska"
+	if w := callback(state, cookies); w.Code != http.StatusUnauthorized {
+		t.Errorf("Yanlış nonce kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Başka bir tarayıcıda başlatılan girişin state'i cookie olmadan veya başka bir girişin cookie'si ile kullanılamaz (login CSRF)
+	state, _ = startLogin(jwt.MapClaims{"sub": "saldirgan"})
+	_, otherCookies := startLogin(jwt.MapClaims{"sub": "saldirgan"})
+	if w := callback(state, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("State cookie'si olmadan giriş tamamlandı. Kod: %d", w.Code)
+	}
+	if w := callback(state, otherCookies); w.Code != http.StatusBadRequest {
+		t.Errorf("Başka girişin cookie'si kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Bekleyen giriş veritabanında tutulur, sağlayıcı yeniden yapılandırılan (başka bir instance) sunucuda da tamamlanır
+	state, cookies = startLogin(jwt.MapClaims{"sub": "ayse-1"})
+	auth.ConfigureOIDC(auth.OIDCConfig{Issuer: provider.server.URL, ClientID: "webservice", ClientSecret: "gizli", RedirectURL: "http://localhost:8080/oidc/callback"})
+	if w := callback(state, cookies); w.Code != http.StatusOK {
+		t.Errorf("Giriş başka bir instance'ta tamamlanamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	// State tek kullanımlıktır
+	if w := callback(state, cookies); w.Code != http.StatusBadRequest {
+		t.Errorf("Kullanılmış state kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Yerel hesap bağlama
+	token := login(t, r, "test", "gizli1234")["token"].(string)
+	linkW, linkResp := postJSONWithToken(r, "/oidc/link", token, nil)
+	authURL, _ := linkResp["authorization_url"].(string)
+	state = provider.authorize(t, authURL, jwt.MapClaims{"sub": "test-1"})
+
+	// Bağlama isteğini başlatmayan bir tarayıcı (örn. saldırganın gönderdiği bağlantı) hesabı bağlayamaz
+	if w := callback(state, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Hesap bağlama cookie olmadan tamamlandı. Kod: %d", w.Code)
+	}
+
+	linkW, linkResp = postJSONWithToken(r, "/oidc/link", token, nil)
+	authURL, _ = linkResp["authorization_url"].(string)
+	state = provider.authorize(t, authURL, jwt.MapClaims{"sub": "test-1"})
+
+	w := callback(state, linkW.Result().Cookies())
+	if w.Code != http.StatusOK {
+		t.Fatalf("Hesap bağlanamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	if linked, err := models.GetUserByIdentity(provider.server.URL, "test-1"); err != nil || linked.Username != "test" {
+		t.Errorf("Kimlik yerel hesaba bağlanmadı: %+v, %v", linked, err)
+	}
+}
+
+func TestOIDCLoginRequiresTwoFactor(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.GET("/oidc/login", auth.OIDCLogin)
+	r.GET("/oidc/callback", auth.OIDCCallback)
+
+	if err := models.CreateRole(models.Role{Name: "admin"}); err != nil {
+		t.Fatalf("Rol eklenemedi: %v", err)
+	}
+	if err := models.SetTwoFactorRequired("admin", true); err != nil {
+		t.Fatalf("Rol güncellenemedi: %v", err)
+	}
+
+	provider := newMockOIDCProvider(t)
+	auth.ConfigureOIDC(auth.OIDCConfig{
+		Issuer:       provider.server.URL,
+		ClientID:     "webservice",
+		ClientSecret: "gizli",
+		RedirectURL:  "http://localhost:8080/oidc/callback",
+		RoleClaim:    "groups",
+		RoleMap:      map[string]string{"yoneticiler": "admin"},
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+	state := provider.authorize(t, w.Header().Get("Location"), jwt.MapClaims{"sub": "ayse-1", "preferred_username": "ayse", "groups": []string{"yoneticiler"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/oidc/callback?code=mock-code&state="+url.QueryEscape(state), nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// Sağlayıcıdan admin rolü eşlenen kullanıcı 2FA kurmadan token alamaz
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if w.Code != http.StatusOK || resp["token"] != nil || resp["challenge"] == nil || resp["setup_required"] != true {
+		t.Errorf("2FA zorunlu role OIDC ile token verildi. Kod: %d, Yanıt: %v", w.Code, resp)
+	}
+}
+
+func TestOIDCLoginLimitedPerIP(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.GET("/oidc/login", auth.OIDCLogin)
+
+	provider := newMockOIDCProvider(t)
+	auth.ConfigureOIDC(auth.OIDCConfig{Issuer: provider.server.URL, ClientID: "webservice", ClientSecret: "gizli", RedirectURL: "http://localhost:8080/oidc/callback"})
+
+	start := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/oidc/login", nil))
+		return w
+	}
+
+	for i := 0; i < 50; i++ {
+		if w := start(); w.Code != http.StatusFound {
+			t.Fatalf("Sağlayıcıya yönlendirilmedi. Kod: %d", w.Code)
+		}
+	}
+
+	// Tek bir IP bekleyen giriş sınırını tüketemez
+	if w := start(); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("IP başına bekleyen giriş sınırı uygulanmadı. Kod: %d", w.Code)
+	}
+}
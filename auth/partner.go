@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+// İmzalı istek başlıkları. İmza HMAC-SHA256(secret, stringToSign) değerinin hex kodlamasıdır, stringToSign satır satır:
+//
+//	HMAC-SHA256
+//	<X-Timestamp>
+//	<X-Nonce>
+//	<METHOD>
+//	<path ve query, örn. /api/v1/person?x=1>
+//	<gövdenin SHA-256 hash'i, hex>
+const (
+	partnerIDHeader        = "X-Partner-Id"
+	partnerTimestampHeader = "X-Timestamp"
+	partnerNonceHeader     = "X-Nonce"
+	partnerSignatureHeader = "X-Signature"
+	partnerSignAlgorithm   = "HMAC-SHA256"
+)
+
+const (
+	// İstek zamanı sunucu saatinden en fazla bu kadar farklı olabilir
+	partnerClockSkew = 5 * time.Minute
+	// İmzalanacak gövdenin üst sınırı
+	partnerMaxBody = 1 << 20
+	// Anahtar yenilendiğinde eski anahtar bu süre boyunca kabul edilir
+	partnerSecretGrace = 24 * time.Hour
+)
+
+// İstemcilerin de kullanabileceği imza hesaplaması
+func partnerSignature(secret, timestamp, nonce, method, requestURI string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	stringToSign := strings.Join([]string{
+		partnerSignAlgorithm,
+		timestamp,
+		nonce,
+		strings.ToUpper(method),
+		requestURI,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isSignedRequest(c *gin.Context) bool {
+	return c.GetHeader(partnerSignatureHeader) != ""
+}
+
+// İmzalı isteği doğrular, gövdeyi handler'lar için geri koyar ve partnerin kullanıcısı adına Claims oluşturur
+func serveSignedRequest(c *gin.Context) {
+	fail := func(status int, message string) {
+		c.JSON(status, gin.H{"error": message})
+		c.Abort()
+	}
+
+	partnerID := c.GetHeader(partnerIDHeader)
+	timestamp := c.GetHeader(partnerTimestampHeader)
+	nonce := c.GetHeader(partnerNonceHeader)
+	signature := strings.ToLower(c.GetHeader(partnerSignatureHeader))
+
+	if partnerID == "" || timestamp == "" || len(nonce) < 16 || len(nonce) > 128 {
+		fail(http.StatusUnauthorized, "EKSİK VEYA GEÇERSİZ İMZA BAŞLIKLARI")
+		return
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		fail(http.StatusUnauthorized, "EKSİK VEYA GEÇERSİZ İMZA BAŞLIKLARI")
+		return
+	}
+
+	if skew := time.Since(time.Unix(unix, 0)); skew > partnerClockSkew || skew < -partnerClockSkew {
+		fail(http.StatusUnauthorized, "İSTEK ZAMANI KABUL EDİLEN ARALIĞIN DIŞINDA")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, partnerMaxBody+1))
+	if err != nil {
+		fail(http.StatusBadRequest, "İSTEK GÖVDESİ OKUNAMADI")
+		return
+	}
+	if len(body) > partnerMaxBody {
+		fail(http.StatusRequestEntityTooLarge, "İSTEK GÖVDESİ ÇOK BÜYÜK")
+		return
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Bilinmeyen partner ile yanlış imza aynı yanıtı alır
+	partner, err := models.GetPartner(partnerID)
+	if err != nil || !partnerSignatureValid(partner, signature, timestamp, nonce, c.Request.Method, c.Request.URL.RequestURI(), body) {
+		fail(http.StatusUnauthorized, "GEÇERSİZ İMZA")
+		return
+	}
+
+	// Nonce imza doğrulandıktan sonra kaydedilir, böylece imzası olmayan istekler partnerin nonce'larını tüketemez.
+	// Nonce, isteğin zaman damgası kabul aralığından çıkana kadar veritabanında saklanır
+	fresh, err := models.UsePartnerNonce(partner.ID, nonce, time.Unix(unix, 0).Add(partnerClockSkew))
+	if err != nil {
+		fail(http.StatusInternalServerError, "NONCE KAYDEDİLEMEDİ")
+		return
+	}
+	if !fresh {
+		fail(http.StatusUnauthorized, "NONCE DAHA ÖNCE KULLANILDI")
+		return
+	}
+
+	user, err := models.GetUserByID(partner.UserID, models.AllTenants)
+	if err != nil {
+		fail(http.StatusUnauthorized, "GEÇERSİZ İMZA")
+		return
+	}
+
+	if err := models.TouchPartner(partner.ID); err != nil {
+		log.Println("Partnerin son kullanım zamanı güncellenemedi:", err)
+	}
+
+	c.Set("claims", &Claims{
+		UserID:    user.ID,
+		TenantID:  user.TenantID,
+		Username:  user.Username,
+		Role:      user.Role,
+		Scopes:    partner.Scopes,
+		PartnerID: partner.ID,
+	})
+	c.Next()
+}
+
+// Yenilenen anahtarın eskisi de grace süresi dolana kadar kabul edilir
+func partnerSignatureValid(partner models.Partner, signature, timestamp, nonce, method, requestURI string, body []byte) bool {
+	secrets := []string{partner.Secret}
+	if partner.PreviousSecret != "" && partner.PreviousExpiresAt != nil && time.Now().Before(*partner.PreviousExpiresAt) {
+		secrets = append(secrets, partner.PreviousSecret)
+	}
+
+	for _, secret := range secrets {
+		expected := partnerSignature(secret, timestamp, nonce, method, requestURI, body)
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type PartnerRequest struct {
+	Name   string   `json:"name"`
+	UserID int      `json:"user_id"`
+	Scopes []string `json:"scopes"`
+}
+
+// @Summary List partners
+// @Description Lists the partners that sign their requests with a shared secret (platform admin only). Secrets are never shown again after creation
+// @Tags partner
+// @Produce json
+// @Router /api/v1/partner [get]
+func GetPartners(c *gin.Context) {
+	partners, err := models.GetPartners()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Partnerler alınamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": partners})
+}
+
+// @Summary Register a partner
+// @Description Registers a partner that signs requests with HMAC-SHA256 instead of a JWT (platform admin only). Signed requests act as the user given in user_id; scopes default to person:write. The secret is returned only once
+// @Tags partner
+// @Accept json
+// @Produce json
+// @Param input body PartnerRequest true "Partner"
+// @Router /api/v1/partner [post]
+func CreatePartner(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	var req PartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" || req.UserID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz giriş verisi"})
+		return
+	}
+
+	scopes, err := validateScopes(req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ SCOPE"})
+		return
+	}
+	if len(scopes) == 0 {
+		scopes = []string{ScopePersonWrite}
+	}
+
+	// Platform admin'i partneri herhangi bir tenant'ın kullanıcısına bağlayabilir
+	if _, err := models.GetUserByID(req.UserID, models.AllTenants); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kullanıcı Bulunamadı"})
+		return
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Partner oluşturulamadı"})
+		return
+	}
+
+	partner := models.Partner{
+		ID:     randomID(),
+		Name:   strings.TrimSpace(req.Name),
+		UserID: req.UserID,
+		Secret: secret,
+		Scopes: scopes,
+	}
+
+	if err := models.CreatePartner(partner); err != nil {
+		if err == models.ErrPartnerKeyMissing {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "PARTNER ANAHTARI YAPILANDIRILMADI"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Partner oluşturulamadı"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Partner oluşturuldu",
+		"id":      partner.ID,
+		"secret":  secret,
+		"scopes":  partner.Scopes,
+	})
+}
+
+// @Summary Rotate a partner secret
+// @Description Issues a new secret for the partner (platform admin only). The previous secret keeps working for 24 hours so the partner can switch without downtime
+// @Tags partner
+// @Produce json
+// @Param id path string true "Partner ID"
+// @Router /api/v1/partner/{id}/rotate [post]
+func RotatePartnerSecret(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Partner anahtarı yenilenemedi"})
+		return
+	}
+
+	if err := models.RotatePartnerSecret(c.Param("id"), secret, partnerSecretGrace); err != nil {
+		if err == models.ErrPartnerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Partner bulunamadı"})
+			return
+		}
+		if err == models.ErrPartnerKeyMissing {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "PARTNER ANAHTARI YAPILANDIRILMADI"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Partner anahtarı yenilenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":                         c.Param("id"),
+		"secret":                     secret,
+		"previous_secret_expires_at": time.Now().Add(partnerSecretGrace),
+	})
+}
+
+// @Summary Delete a partner
+// @Description Deletes a partner; its signed requests are refused immediately (platform admin only)
+// @Tags partner
+// @Produce json
+// @Param id path string true "Partner ID"
+// @Router /api/v1/partner/{id} [delete]
+func DeletePartner(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	if err := models.DeletePartner(c.Param("id")); err != nil {
+		if err == models.ErrPartnerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Partner bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Partner silinemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Partner silindi"})
+}
@@ -0,0 +1,194 @@
+package auth_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+// Partnerin tarafında yapılan imzalama
+func signPartnerRequest(req *http.Request, partnerID, secret, nonce string, at time.Time, body []byte) {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("HMAC-SHA256\n" + timestamp + "\n" + nonce + "\n" + req.Method + "\n" + req.URL.RequestURI() + "\n" + hex.EncodeToString(bodyHash[:])))
+
+	req.Header.Set("X-Partner-Id", partnerID)
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Nonce", nonce)
+	req.Header.Set("X-Signature", hex.EncodeToString(mac.Sum(nil)))
+}
+
+func usePartnerKey(t *testing.T) {
+	t.Helper()
+
+	if err := models.SetPartnerSecretKey(bytes.Repeat([]byte{7}, 32)); err != nil {
+		t.Fatalf("Partner anahtarı ayarlanamadı: %v", err)
+	}
+	t.Cleanup(func() { models.SetPartnerSecretKey(nil) })
+}
+
+func TestPartnerSignedRequests(t *testing.T) {
+	setupTestDB(t)
+	usePartnerKey(t)
+	r := setupRouter()
+
+	// Handler imza doğrulamasından sonra gövdeyi olduğu gibi okuyabilmeli
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, c.MustGet("claims").(*auth.Claims).Username+":"+string(body))
+	}
+
+	v1 := r.Group("/api/v1")
+	v1.Use(auth.TokenAuthMiddleware())
+	v1.POST("person", auth.RequireScope(auth.ScopePersonWrite), echo)
+	v1.GET("person", auth.RequireScope(auth.ScopePersonRead), echo)
+	v1.POST("partner", auth.PlatformAdminOnly(), auth.CreatePartner)
+	v1.POST("partner/:id/rotate", auth.PlatformAdminOnly(), auth.RotatePartnerSecret)
+	v1.DELETE("partner/:id", auth.PlatformAdminOnly(), auth.DeletePartner)
+
+	if _, err := models.CreateUser(models.User{Username: "admin", Password: "yonetici1234", TenantID: models.DefaultTenantID}); err != nil {
+		t.Fatalf("Kullanıcı eklenemedi: %v", err)
+	}
+	if _, err := models.DB.Exec("UPDATE user SET role = 'admin' WHERE username = 'admin'"); err != nil {
+		t.Fatalf("Rol güncellenemedi: %v", err)
+	}
+
+	userToken := login(t, r, "test", "gizli1234")["token"].(string)
+	adminToken := login(t, r, "admin", "yonetici1234")["token"].(string)
+
+	if w, _ := postJSONWithToken(r, "/api/v1/partner", userToken, map[string]interface{}{"name": "crm", "user_id": 1}); w.Code != http.StatusForbidden {
+		t.Errorf("Admin olmayan kullanıcı partner oluşturdu. Kod: %d", w.Code)
+	}
+
+	w, resp := postJSONWithToken(r, "/api/v1/partner", adminToken, map[string]interface{}{"name": "crm", "user_id": 1})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Partner oluşturulamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+	partnerID, secret := resp["id"].(string), resp["secret"].(string)
+
+	// Anahtar veritabanında düz haliyle tutulmaz
+	var stored string
+	if err := models.DB.QueryRow("SELECT secret FROM partner WHERE id = ?", partnerID).Scan(&stored); err != nil || strings.Contains(stored, secret) {
+		t.Errorf("Partner anahtarı şifrelenmeden kaydedildi: %q, %v", stored, err)
+	}
+
+	nonces := 0
+	send := func(method, path, secret string, at time.Time, body []byte, tamper func(*http.Request)) *httptest.ResponseRecorder {
+		nonces++
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		signPartnerRequest(req, partnerID, secret, "nonce-0000000000"+strconv.Itoa(nonces), at, body)
+		if tamper != nil {
+			tamper(req)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	body := []byte(`{"firstName":"Ayşe"}`)
+
+	if w := send(http.MethodPost, "/api/v1/person", secret, time.Now(), body, nil); w.Code != http.StatusOK || w.Body.String() != "test:"+string(body) {
+		t.Fatalf("İmzalı istek kabul edilmedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	// Aynı nonce ile tekrar gönderilen istek reddedilmeli
+	replay := httptest.NewRequest(http.MethodPost, "/api/v1/person", bytes.NewReader(body))
+	signPartnerRequest(replay, partnerID, secret, "tekrar-nonce-0001", time.Now(), body)
+	for i, want := range []int{http.StatusOK, http.StatusUnauthorized} {
+		replay.Body = io.NopCloser(bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, replay)
+		if w.Code != want {
+			t.Errorf("%d. gönderimde beklenmeyen kod: %d", i+1, w.Code)
+		}
+	}
+
+	// Nonce veritabanında tutulduğu için yeniden başlayan veya başka bir instance da tekrarı reddeder
+	var expiresAt int64
+	if err := models.DB.QueryRow("SELECT expires_at FROM partner_nonce WHERE partner_id = ? AND nonce = ?", partnerID, "tekrar-nonce-0001").Scan(&expiresAt); err != nil {
+		t.Fatalf("Nonce veritabanına kaydedilmedi: %v", err)
+	}
+	if remaining := time.Until(time.Unix(expiresAt, 0)); remaining > 5*time.Minute || remaining < 4*time.Minute {
+		t.Errorf("Nonce saklama süresi zaman aralığıyla aynı değil: %v", remaining)
+	}
+
+	tampered := func(req *http.Request) { req.Body = io.NopCloser(bytes.NewReader([]byte(`{"firstName":"Mehmet"}`))) }
+	if w := send(http.MethodPost, "/api/v1/person", secret, time.Now(), body, tampered); w.Code != http.StatusUnauthorized {
+		t.Errorf("Gövdesi değiştirilmiş istek kabul edildi. Kod: %d", w.Code)
+	}
+
+	if w := send(http.MethodPost, "/api/v1/person", secret, time.Now().Add(-10*time.Minute), body, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Zaman aralığı dışındaki istek kabul edildi. Kod: %d", w.Code)
+	}
+
+	if w := send(http.MethodPost, "/api/v1/person", "yanlis-anahtar", time.Now(), body, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Yanlış anahtarla imzalanmış istek kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Partnerin varsayılan kapsamı sadece person:write
+	if w := send(http.MethodGet, "/api/v1/person", secret, time.Now(), nil, nil); w.Code != http.StatusForbidden {
+		t.Errorf("Partner kapsamı dışındaki işlemi yaptı. Kod: %d", w.Code)
+	}
+
+	// Makine istemcisi partner yönetemez
+	if w := send(http.MethodPost, "/api/v1/partner/"+partnerID+"/rotate", secret, time.Now(), nil, nil); w.Code != http.StatusForbidden {
+		t.Errorf("Partner imzasıyla anahtar yenilendi. Kod: %d", w.Code)
+	}
+
+	// Yenilemeden sonra yeni anahtar ve grace süresi boyunca eski anahtar geçerli
+	w, resp = postJSONWithToken(r, "/api/v1/partner/"+partnerID+"/rotate", adminToken, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Partner anahtarı yenilenemedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+	newSecret := resp["secret"].(string)
+
+	for _, s := range []string{newSecret, secret} {
+		if w := send(http.MethodPost, "/api/v1/person", s, time.Now(), body, nil); w.Code != http.StatusOK {
+			t.Errorf("Yenilemeden sonra imzalı istek reddedildi. Kod: %d", w.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/partner/"+partnerID, nil)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Partner silinemedi. Kod: %d", w.Code)
+	}
+
+	if w := send(http.MethodPost, "/api/v1/person", newSecret, time.Now(), body, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Silinen partnerin isteği kabul edildi. Kod: %d", w.Code)
+	}
+}
+
+func TestPartnerRequiresSecretKey(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/api/v1/partner", auth.TokenAuthMiddleware(), auth.PlatformAdminOnly(), auth.CreatePartner)
+
+	if _, err := models.DB.Exec("UPDATE user SET role = 'admin' WHERE id = 1"); err != nil {
+		t.Fatalf("Rol güncellenemedi: %v", err)
+	}
+	token := login(t, r, "test", "gizli1234")["token"].(string)
+
+	// Sunucu anahtarı olmadan anahtar düz haliyle kaydedilmez
+	w, _ := postJSONWithToken(r, "/api/v1/partner", token, map[string]interface{}{"name": "crm", "user_id": 1})
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Anahtar olmadan partner oluşturuldu. Kod: %d", w.Code)
+	}
+}
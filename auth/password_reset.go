@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const (
+	passwordResetTTL      = time.Hour
+	passwordResetPerEmail = 3  // Aynı adrese pencere başına gönderilen en fazla e-posta
+	passwordResetPerIP    = 10 // Aynı IP'den pencere başına kabul edilen en fazla istek
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" form:"token"`
+	Password string `json:"password" form:"password"`
+}
+
+// @Summary Forgot password
+// @Description Sends a single-use password reset link to every account registered with the e-mail address. The response is the same whether the address is known or not
+// @Tags password
+// @Accept json
+// @Produce json
+// @Param input body ForgotPasswordRequest true "E-mail address"
+// @Router /password/forgot [post]
+func ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	wait, err := mailRequestThrottle("reset-ip:"+c.ClientIP(), passwordResetPerIP)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return
+	}
+
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "ÇOK FAZLA İSTEK, DAHA SONRA TEKRAR DENEYİN"})
+		return
+	}
+
+	// Adres başına sınır aşıldığında da aynı yanıt döner, böylece adresin kayıtlı olup olmadığı anlaşılmaz
+	if wait, err := mailRequestThrottle("reset-email:"+email, passwordResetPerEmail); err != nil {
+		log.Println("Şifre sıfırlama sayacı güncellenemedi:", err)
+	} else if wait == 0 {
+		sendPasswordResetLinks(email)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "ADRES KAYITLIYSA ŞİFRE SIFIRLAMA BAĞLANTISI GÖNDERİLDİ"})
+}
+
+func sendPasswordResetLinks(email string) {
+	users, err := models.GetUsersByEmail(email)
+	if err != nil {
+		log.Println("Şifre sıfırlama için kullanıcılar alınamadı:", err)
+		return
+	}
+
+	for _, user := range users {
+		token, err := randomToken()
+		if err != nil {
+			log.Println("Şifre sıfırlama token'ı üretilemedi:", err)
+			return
+		}
+
+		if err := models.CreatePasswordReset(user.ID, hashToken(token), time.Now().Add(passwordResetTTL)); err != nil {
+			log.Println("Şifre sıfırlama token'ı kaydedilemedi:", err)
+			return
+		}
+
+		link := appURL("/password/reset", url.Values{"token": {token}})
+		err = sendMail(user.Email, "Şifre sıfırlama",
+			fmt.Sprintf("Merhaba %s,\n\nŞifrenizi sıfırlamak için aşağıdaki bağlantıyı açın (1 saat geçerlidir, bir kez kullanılabilir):\n\n%s\n\nBu isteği siz yapmadıysanız bu e-postayı dikkate almayın.\n", user.Username, link))
+		if err != nil {
+			log.Println("Şifre sıfırlama e-postası gönderilemedi:", err)
+		}
+	}
+}
+
+var resetPasswordPage = template.Must(template.New("reset").Parse(`<!DOCTYPE html>
+<html lang="tr">
+<head><meta charset="utf-8"><title>Şifre sıfırlama</title></head>
+<body>
+<h1>Yeni şifrenizi belirleyin</h1>
+<form method="post" action="/password/reset">
+<input type="hidden" name="token" value="{{.}}">
+<p><label>Yeni şifre <input type="password" name="password" autocomplete="new-password"></label></p>
+<button type="submit">Şifreyi değiştir</button>
+</form>
+</body>
+</html>`))
+
+// @Summary Password reset form
+// @Description The page opened from the reset link; posts the new password to /password/reset
+// @Tags password
+// @Produce html
+// @Param token query string true "Reset token"
+// @Router /password/reset [get]
+func ResetPasswordForm(c *gin.Context) {
+	c.Header("Referrer-Policy", "no-referrer")
+	renderOAuthPage(c, http.StatusOK, resetPasswordPage, c.Query("token"))
+}
+
+// @Summary Reset password
+// @Description Sets a new password with a token from /password/forgot. The token can be used once; every session and refresh token of the user is revoked
+// @Tags password
+// @Accept json
+// @Produce json
+// @Param input body ResetPasswordRequest true "Reset token and new password"
+// @Router /password/reset [post]
+func ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBind(&req); err != nil || req.Token == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	userID, err := models.ResetPassword(hashToken(req.Token), req.Password)
+	if err != nil {
+		if rejectPasswordPolicy(c, err) {
+			return
+		}
+		if err == models.ErrPasswordResetNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ VEYA SÜRESİ DOLMUŞ ŞİFRE SIFIRLAMA BAĞLANTISI"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ŞİFRE DEĞİŞTİRİLEMEDİ"})
+		return
+	}
+
+	if err := InvalidateUserSessions(userID); err != nil {
+		log.Println("Şifre sıfırlandıktan sonra oturumlar iptal edilemedi:", err)
+	}
+
+	if user, err := models.GetUserByID(userID, models.AllTenants); err == nil {
+		if err := models.ClearLoginAttempts(accountKey(user.Username)); err != nil {
+			log.Println("Başarısız giriş kayıtları temizlenemedi:", err)
+		}
+
+		err = sendMail(user.Email, "Şifreniz değiştirildi",
+			fmt.Sprintf("Merhaba %s,\n\nHesabınızın şifresi sıfırlandı ve tüm oturumlarınız kapatıldı. Bu işlemi siz yapmadıysanız hemen yöneticinize başvurun.\n", user.Username))
+		if err != nil {
+			log.Println("Şifre değişikliği bildirimi gönderilemedi:", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ŞİFRE DEĞİŞTİRİLDİ, YENİ ŞİFRENİZLE GİRİŞ YAPABİLİRSİNİZ"})
+}
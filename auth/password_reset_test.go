@@ -0,0 +1,91 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/mailer"
+)
+
+var resetTokenPattern = regexp.MustCompile(`/password/reset\?token=(\S+)`)
+
+func TestPasswordReset(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/password/forgot", auth.ForgotPassword)
+	r.POST("/password/reset", auth.ResetPassword)
+
+	outbox := &captureMailer{}
+	auth.ConfigureMail(outbox, "http://example.test")
+	defer auth.ConfigureMail(mailer.LogMailer{}, "")
+
+	resp := login(t, r, "test", "gizli1234")
+	accessToken := resp["token"].(string)
+	refreshToken := resp["refresh_token"].(string)
+
+	// Kayıtlı olmayan adres için de aynı yanıt dönmeli ama e-posta gönderilmemeli
+	w, _ := postJSON(r, "/password/forgot", map[string]string{"email": "yok@test.com"})
+	if w.Code != http.StatusAccepted || len(outbox.sent) != 0 {
+		t.Fatalf("Bilinmeyen adres için beklenmeyen yanıt. Kod: %d, E-posta: %d", w.Code, len(outbox.sent))
+	}
+
+	w, _ = postJSON(r, "/password/forgot", map[string]string{"email": "TEST@test.com"})
+	if w.Code != http.StatusAccepted || len(outbox.sent) != 1 {
+		t.Fatalf("Şifre sıfırlama e-postası gönderilmedi. Kod: %d, E-posta: %d", w.Code, len(outbox.sent))
+	}
+
+	match := resetTokenPattern.FindStringSubmatch(outbox.sent[0].Body)
+	if match == nil {
+		t.Fatalf("E-postada sıfırlama bağlantısı yok: %s", outbox.sent[0].Body)
+	}
+	token, _ := url.QueryUnescape(match[1])
+
+	w, _ = postJSON(r, "/password/reset", map[string]string{"token": "bozuk", "password": "yeni12345"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Geçersiz token kabul edildi. Kod: %d", w.Code)
+	}
+
+	w, _ = postJSON(r, "/password/reset", map[string]string{"token": token, "password": "yeni12345"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Şifre sıfırlanamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	// Token tek kullanımlık olmalı
+	w, _ = postJSON(r, "/password/reset", map[string]string{"token": token, "password": "baska12345"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Kullanılmış token kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Sıfırlamadan önceki oturumlar iptal edilmiş olmalı
+	if code := getSecured(r, accessToken); code != http.StatusUnauthorized {
+		t.Errorf("Sıfırlama öncesi access token kabul edildi. Kod: %d", code)
+	}
+
+	w, _ = postJSON(r, "/token/refresh", map[string]string{"refresh_token": refreshToken})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Sıfırlama öncesi refresh token kabul edildi. Kod: %d", w.Code)
+	}
+
+	w, _ = postJSON(r, "/login", map[string]string{"username": "test", "password": "gizli1234"})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Eski şifre ile giriş yapıldı. Kod: %d", w.Code)
+	}
+
+	login(t, r, "test", "yeni12345")
+
+	// Adres başına sınır aşıldıktan sonra yanıt değişmemeli ama e-posta gönderilmemeli
+	before := len(outbox.sent)
+	for i := 0; i < 5; i++ {
+		w, _ = postJSON(r, "/password/forgot", map[string]string{"email": "test@test.com"})
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Şifre sıfırlama isteği reddedildi. Kod: %d", w.Code)
+		}
+	}
+
+	if sent := len(outbox.sent) - before; sent != 2 {
+		t.Errorf("Sınır aşıldığı halde e-posta gönderildi. Gönderilen: %d", sent)
+	}
+}
@@ -0,0 +1,342 @@
+package auth
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const policyCacheTTL = time.Minute
+
+// Yetki kuralındaki koşulu değerlendiren fonksiyon
+type PolicyCondition func(c *gin.Context, claims *Claims) bool
+
+var (
+	conditionsMu sync.RWMutex
+	conditions   = map[string]PolicyCondition{
+		// Kullanıcı sadece :id parametresi kendi ID'si olan kayda erişebilir
+		"self": func(c *gin.Context, claims *Claims) bool {
+			return c.Param("id") == strconv.Itoa(claims.UserID)
+		},
+	}
+)
+
+// Yetki kurallarında kullanılabilecek yeni bir koşul tanımlar
+func RegisterCondition(name string, condition PolicyCondition) {
+	conditionsMu.Lock()
+	conditions[name] = condition
+	conditionsMu.Unlock()
+}
+
+type policyFile struct {
+	Roles       []models.Role       `json:"roles"`
+	Permissions []models.Permission `json:"permissions"`
+}
+
+// Veritabanında yetki tanımı yoksa kullanılan varsayılan politika
+var defaultPolicy = policyFile{
+	Roles: []models.Role{
+		{Name: "admin", Description: "Tüm işlemler"},
+		{Name: "user", Description: "Kendi kişilerini ve kendisiyle paylaşılan kişileri yönetme, kendi kullanıcı kaydını görüntüleme"},
+	},
+	Permissions: []models.Permission{
+		{Role: "admin", Route: "*", Method: "*"},
+		{Role: "user", Route: "/api/v1/person", Method: "GET"},
+		{Role: "user", Route: "/api/v1/person", Method: "POST"},
+		{Role: "user", Route: "/api/v1/person", Method: "OPTIONS"},
+		{Role: "user", Route: "/api/v1/person/search", Method: "GET"},
+		{Role: "user", Route: "/api/v1/person/:id", Method: "GET"},
+		{Role: "user", Route: "/api/v1/person/:id", Method: "PUT"},
+		{Role: "user", Route: "/api/v1/person/:id", Method: "DELETE"},
+		{Role: "user", Route: "/api/v1/person/:id/share", Method: "*"},
+		{Role: "user", Route: "/api/v1/person/:id/share/:shareId", Method: "DELETE"},
+		{Role: "user", Route: "/api/v1/user/:id", Method: "GET", Condition: "self"},
+	},
+}
+
+type policyCache struct {
+	mu          sync.RWMutex
+	permissions map[string][]models.Permission
+	loadedAt    time.Time
+}
+
+var policies = &policyCache{}
+
+// RBAC_POLICY_FILE ortam değişkeni ile verilen JSON dosyasını (yoksa varsayılan politikayı) veritabanına uygular. Tablo doluysa
+// sadece daha önce eklenmemiş yetkiler eklenir (bkz. models.SyncPolicy)
+func LoadPolicy() error {
+	policy := defaultPolicy
+
+	if path := os.Getenv("RBAC_POLICY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		policy = policyFile{}
+		if err := json.Unmarshal(data, &policy); err != nil {
+			return err
+		}
+	}
+
+	if err := models.SyncPolicy(policy.Roles, policy.Permissions); err != nil {
+		return err
+	}
+
+	policies.invalidate()
+	return nil
+}
+
+func (pc *policyCache) invalidate() {
+	pc.mu.Lock()
+	pc.permissions = nil
+	pc.mu.Unlock()
+}
+
+func (pc *policyCache) forRole(role string) []models.Permission {
+	pc.mu.RLock()
+	fresh := pc.permissions != nil && time.Since(pc.loadedAt) < policyCacheTTL
+	pc.mu.RUnlock()
+
+	if !fresh {
+		permissions, err := models.GetPermissions()
+		if err != nil {
+			log.Println("Yetkiler yüklenemedi:", err)
+		} else {
+			byRole := make(map[string][]models.Permission)
+			for _, p := range permissions {
+				byRole[p.Role] = append(byRole[p.Role], p)
+			}
+
+			pc.mu.Lock()
+			pc.permissions = byRole
+			pc.loadedAt = time.Now()
+			pc.mu.Unlock()
+		}
+	}
+
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+
+	return pc.permissions[role]
+}
+
+func matchesRule(rule, value string) bool {
+	return rule == "*" || strings.EqualFold(rule, value)
+}
+
+func isAllowed(c *gin.Context, claims *Claims) bool {
+	route := c.FullPath()
+	method := c.Request.Method
+
+	for _, p := range policies.forRole(claims.Role) {
+		if !matchesRule(p.Route, route) || !matchesRule(p.Method, method) {
+			continue
+		}
+
+		if p.Condition == "" {
+			return true
+		}
+
+		conditionsMu.RLock()
+		condition, ok := conditions[p.Condition]
+		conditionsMu.RUnlock()
+
+		if ok && condition(c, claims) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TokenAuthMiddleware'den sonra çalışır ve isteği rolün yetki kurallarına göre değerlendirir
+func Authorize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := c.Get("claims")
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization BAŞLIĞI SAĞLANAMADI"})
+			c.Abort()
+			return
+		}
+
+		if !isAllowed(c, claims.(*Claims)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Yetkisiz İşlem"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Sadece varsayılan tenant'ın admin'lerine izin verir. Roller ve yetkiler tüm tenant'lar için ortak olduğundan
+// bunları ve tenant'ları sadece platform yöneticileri değiştirebilir
+func PlatformAdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("claims").(*Claims)
+
+		if claims.Role != "admin" || claims.TenantID != models.DefaultTenantID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Yetkisiz İşlem"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// @Summary List roles
+// @Description Lists roles together with their permissions (platform admin only)
+// @Tags rbac
+// @Produce json
+// @Router /api/v1/role [get]
+func GetRoles(c *gin.Context) {
+	roles, err := models.GetRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Roller alınamadı"})
+		return
+	}
+
+	permissions, err := models.GetPermissions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Yetkiler alınamadı"})
+		return
+	}
+
+	byRole := make(map[string][]models.Permission)
+	for _, p := range permissions {
+		byRole[p.Role] = append(byRole[p.Role], p)
+	}
+
+	data := make([]gin.H, 0, len(roles))
+	for _, role := range roles {
+		rolePermissions := byRole[role.Name]
+		if rolePermissions == nil {
+			rolePermissions = []models.Permission{}
+		}
+		data = append(data, gin.H{"name": role.Name, "description": role.Description, "require_2fa": role.RequireTwoFactor, "permissions": rolePermissions})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+// @Summary Create a role
+// @Description Creates a new role without permissions (platform admin only)
+// @Tags rbac
+// @Accept json
+// @Produce json
+// @Param role body models.Role true "New role"
+// @Router /api/v1/role [post]
+func CreateRole(c *gin.Context) {
+	var role models.Role
+	if err := c.ShouldBindJSON(&role); err != nil || role.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz rol"})
+		return
+	}
+
+	if err := models.CreateRole(role); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rol eklenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rol başarıyla eklendi"})
+}
+
+// @Summary Delete a role
+// @Description Deletes a role and all of its permissions (platform admin only). The admin role cannot be deleted
+// @Tags rbac
+// @Produce json
+// @Param name path string true "Role name"
+// @Router /api/v1/role/{name} [delete]
+func DeleteRole(c *gin.Context) {
+	name := c.Param("name")
+	if name == "admin" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "admin rolü silinemez"})
+		return
+	}
+
+	if err := models.DeleteRole(name); err != nil {
+		if err == models.ErrRoleNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rol bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rol silinemedi"})
+		return
+	}
+
+	policies.invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Rol başarıyla silindi"})
+}
+
+// @Summary Add a permission
+// @Description Grants a role access to a route and method. Route and method may be "*". Condition may be empty or "self" (platform admin only)
+// @Tags rbac
+// @Accept json
+// @Produce json
+// @Param permission body models.Permission true "New permission"
+// @Router /api/v1/permission [post]
+func AddPermission(c *gin.Context) {
+	var p models.Permission
+	if err := c.ShouldBindJSON(&p); err != nil || p.Role == "" || p.Route == "" || p.Method == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz yetki"})
+		return
+	}
+
+	p.Method = strings.ToUpper(p.Method)
+
+	if p.Condition != "" {
+		conditionsMu.RLock()
+		_, ok := conditions[p.Condition]
+		conditionsMu.RUnlock()
+
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Bilinmeyen koşul: " + p.Condition})
+			return
+		}
+	}
+
+	id, err := models.AddPermission(p)
+	if err != nil {
+		if err == models.ErrRoleNotFound {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Rol bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Yetki eklenemedi"})
+		return
+	}
+
+	policies.invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Yetki başarıyla eklendi", "id": id})
+}
+
+// @Summary Delete a permission
+// @Description Removes a permission by its ID (platform admin only)
+// @Tags rbac
+// @Produce json
+// @Param id path int true "Permission ID"
+// @Router /api/v1/permission/{id} [delete]
+func DeletePermission(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz yetki ID'si"})
+		return
+	}
+
+	if err := models.DeletePermission(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Yetki silinemedi"})
+		return
+	}
+
+	policies.invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Yetki başarıyla silindi"})
+}
@@ -0,0 +1,120 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+func TestDefaultPolicy(t *testing.T) {
+	setupTestDB(t)
+	if err := auth.LoadPolicy(); err != nil {
+		t.Fatalf("Politika yüklenemedi: %v", err)
+	}
+
+	r := setupRouter()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	v1 := r.Group("/api/v1")
+	v1.Use(auth.TokenAuthMiddleware(), auth.Authorize())
+	v1.GET("person", ok)
+	v1.DELETE("person/:id", ok)
+	v1.DELETE("user/:id", ok)
+	v1.GET("user", ok)
+	v1.GET("user/:id", ok)
+	v1.POST("permission", auth.AddPermission)
+
+	if _, err := models.CreateUser(models.User{Username: "admin", Password: "yonetici1234", TenantID: models.DefaultTenantID}); err != nil {
+		t.Fatalf("Kullanıcı eklenemedi: %v", err)
+	}
+	if _, err := models.DB.Exec("UPDATE user SET role = 'admin' WHERE username = 'admin'"); err != nil {
+		t.Fatalf("Rol güncellenemedi: %v", err)
+	}
+
+	userToken := login(t, r, "test", "gizli1234")["token"].(string)
+	adminToken := login(t, r, "admin", "yonetici1234")["token"].(string)
+
+	request := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	tests := []struct {
+		method, path, token string
+		expected            int
+	}{
+		{http.MethodGet, "/api/v1/person", userToken, http.StatusOK},
+		{http.MethodDelete, "/api/v1/person/1", userToken, http.StatusOK},
+		{http.MethodDelete, "/api/v1/user/1", userToken, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/user", userToken, http.StatusForbidden},
+		{http.MethodGet, "/api/v1/user/1", userToken, http.StatusOK},
+		{http.MethodGet, "/api/v1/user/2", userToken, http.StatusForbidden},
+		{http.MethodDelete, "/api/v1/user/1", adminToken, http.StatusOK},
+		{http.MethodGet, "/api/v1/user", adminToken, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		if code := request(tt.method, tt.path, tt.token); code != tt.expected {
+			t.Errorf("%s %s için beklenen kod %d, alınan %d", tt.method, tt.path, tt.expected, code)
+		}
+	}
+
+	// Admin yeni yetki ekledikten sonra kullanıcı tüm kullanıcıları listeleyebilmeli
+	w, _ := postJSONWithToken(r, "/api/v1/permission", adminToken, map[string]string{"role": "user", "route": "/api/v1/user", "method": "get"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Yetki eklenemedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	if code := request(http.MethodGet, "/api/v1/user", userToken); code != http.StatusOK {
+		t.Errorf("Eklenen yetki uygulanmadı. Kod: %d", code)
+	}
+}
+
+func TestPolicySyncAddsNewDefaults(t *testing.T) {
+	setupTestDB(t)
+
+	roles := []models.Role{{Name: "user"}}
+	permissions := []models.Permission{
+		{Role: "user", Route: "/api/v1/person", Method: "GET"},
+		{Role: "user", Route: "/api/v1/person/:id", Method: "GET"},
+	}
+
+	if err := models.SyncPolicy(roles, permissions); err != nil {
+		t.Fatalf("Politika yüklenemedi: %v", err)
+	}
+
+	// Admin'in sildiği varsayılan yetki geri eklenmemeli, yeni sürümün varsayılan yetkisi eklenmeli
+	stored, _ := models.GetPermissions()
+	for _, p := range stored {
+		if p.Route == "/api/v1/person/:id" {
+			if err := models.DeletePermission(p.ID); err != nil {
+				t.Fatalf("Yetki silinemedi: %v", err)
+			}
+		}
+	}
+
+	permissions = append(permissions,
+		models.Permission{Role: "user", Route: "/api/v1/person/search", Method: "GET"},
+		models.Permission{Role: "auditor", Route: "/api/v1/person", Method: "GET"})
+	if err := models.SyncPolicy(roles, permissions); err != nil {
+		t.Fatalf("Politika güncellenemedi: %v", err)
+	}
+
+	routes := make(map[string]bool)
+	stored, _ = models.GetPermissions()
+	for _, p := range stored {
+		routes[p.Role+" "+p.Route] = true
+	}
+
+	if len(stored) != 2 || !routes["user /api/v1/person"] || !routes["user /api/v1/person/search"] {
+		t.Errorf("Beklenmeyen yetkiler: %+v", stored)
+	}
+}
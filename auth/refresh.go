@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"` // İsteğe bağlı, kapsam sadece daraltılabilir
+}
+
+type tokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int
+	Scopes       []string
+}
+
+// Kısa ömürlü access token ile aynı aileye ait yeni bir refresh token üretir
+func issueTokens(user models.User, familyID string, scopes []string) (tokenPair, error) {
+	return issueClientTokens(user, familyID, scopes, "")
+}
+
+// OAuth istemcisine verilen token'lar istemciye bağlıdır; refresh token sadece aynı istemci tarafından yenilenebilir
+func issueClientTokens(user models.User, familyID string, scopes []string, clientID string) (tokenPair, error) {
+	accessToken, err := generateAccessToken(user, familyID, scopes, clientID)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	err = models.CreateRefreshToken(models.RefreshToken{
+		TokenHash: hashToken(refreshToken),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		Scopes:    scopes,
+		ClientID:  clientID,
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	return tokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+		Scopes:       scopes,
+	}, nil
+}
+
+var (
+	errInvalidRefreshToken = errors.New("GEÇERSİZ REFRESH TOKEN")
+	errRefreshTokenExpired = errors.New("REFRESH TOKEN SÜRESİ DOLDU")
+	errRefreshUserNotFound = errors.New("KULLANICI BULUNAMADI")
+)
+
+// Refresh token'ı kullanıldı olarak işaretleyip yerine yeni bir token çifti üretir. Token başka bir istemciye aitse,
+// daha önce kullanıldıysa veya iptal edildiyse hata döner; tekrar kullanımda tüm aile iptal edilir
+func rotateRefreshToken(rawToken, clientID string, requestedScopes []string) (tokenPair, error) {
+	stored, err := models.GetRefreshTokenByHash(hashToken(rawToken))
+	if err != nil {
+		if err == models.ErrRefreshTokenNotFound {
+			return tokenPair{}, errInvalidRefreshToken
+		}
+		return tokenPair{}, err
+	}
+
+	if stored.RevokedAt != nil || stored.ClientID != clientID {
+		return tokenPair{}, errInvalidRefreshToken
+	}
+
+	if stored.UsedAt != nil {
+		revokeReusedFamily(stored)
+		return tokenPair{}, errInvalidRefreshToken
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return tokenPair{}, errRefreshTokenExpired
+	}
+
+	scopes, err := narrowScopes(stored.Scopes, requestedScopes)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	marked, err := models.MarkRefreshTokenUsed(stored.ID)
+	if err != nil {
+		return tokenPair{}, err
+	}
+
+	if !marked {
+		// Token bu istekle eş zamanlı olarak başka bir istekte kullanıldı
+		revokeReusedFamily(stored)
+		return tokenPair{}, errInvalidRefreshToken
+	}
+
+	user, err := models.GetUserByID(stored.UserID, models.AllTenants)
+	if err != nil {
+		return tokenPair{}, errRefreshUserNotFound
+	}
+
+	if err := models.ExtendSession(stored.FamilyID, time.Now().Add(refreshTokenTTL)); err != nil {
+		log.Println("Oturum süresi uzatılamadı:", err)
+	}
+
+	return issueClientTokens(user, stored.FamilyID, scopes, clientID)
+}
+
+// @Summary Refresh access token
+// @Description Exchanges a refresh token for a new access token and a new refresh token. A refresh token can only be used once; reusing it revokes every token issued from the same login
+// @Accept json
+// @Produce json
+// @Param input body RefreshRequest true "Refresh token"
+// @Router /token/refresh [post]
+func RefreshToken(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "REFRESH TOKEN SAĞLANAMADI"})
+		return
+	}
+
+	tokens, err := rotateRefreshToken(req.RefreshToken, "", strings.Fields(req.Scope))
+	if err != nil {
+		switch err {
+		case errInvalidRefreshToken, errRefreshTokenExpired, errRefreshUserNotFound:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errInvalidScope:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ SCOPE"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"scope":         strings.Join(tokens.Scopes, " "),
+	})
+}
+
+// Kullanılmış bir refresh token tekrar gönderildiğinde token çalınmış kabul edilir ve tüm aile iptal edilir
+func revokeReusedFamily(stored models.RefreshToken) {
+	log.Printf("Refresh token tekrar kullanıldı, token ailesi iptal ediliyor. user_id: %d, family: %s", stored.UserID, stored.FamilyID)
+
+	if err := models.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+		log.Println("Token ailesi iptal edilemedi:", err)
+		return
+	}
+
+	// Aile ID'si oturum ID'sidir, ailenin access token'ları da hemen geçersiz olur
+	revocations.revokeSession(stored.FamilyID)
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,291 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/mailer"
+	"example.com/webservice/models"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	emailVerificationAud = "email-verification"
+	// Bu süre içinde doğrulanmayan hesaplar silinir ve kullanıcı adı tekrar alınabilir
+	unverifiedAccountTTL = 7 * 24 * time.Hour
+	resendPerEmail       = 3  // Aynı adrese pencere başına gönderilen en fazla doğrulama e-postası
+	resendPerIP          = 10 // Aynı IP'den pencere başına kabul edilen en fazla istek
+	registerPerEmail     = 3  // Aynı adresle pencere başına açılabilecek en fazla hesap
+	registerPerIP        = 10 // Aynı IP'den pencere başına kabul edilen en fazla kayıt
+	mailRequestWindow    = time.Hour
+)
+
+var mailConfig = struct {
+	sync.RWMutex
+	mailer  mailer.Mailer
+	baseURL string
+}{mailer: mailer.LogMailer{}, baseURL: "http://localhost:8080"}
+
+// Kullanıcılara gönderilen e-postalar için mailer'ı ve bağlantılarda kullanılan uygulama adresini ayarlar
+func ConfigureMail(m mailer.Mailer, baseURL string) {
+	mailConfig.Lock()
+	defer mailConfig.Unlock()
+
+	mailConfig.mailer = m
+	if baseURL != "" {
+		mailConfig.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+func sendMail(to, subject, body string) error {
+	mailConfig.RLock()
+	defer mailConfig.RUnlock()
+
+	return mailConfig.mailer.Send(mailer.Message{To: to, Subject: subject, Body: body})
+}
+
+func appURL(path string, params url.Values) string {
+	mailConfig.RLock()
+	defer mailConfig.RUnlock()
+
+	return mailConfig.baseURL + path + "?" + params.Encode()
+}
+
+// E-posta gönderen istekler giriş denemeleriyle aynı sayaçla (login_attempt) sayılır. Sınır aşıldıysa tekrar denemeden
+// önce beklenmesi gereken süreyi döner; sayaç son istekten mailRequestWindow sonra sıfırlanır
+func mailRequestThrottle(key string, limit int) (time.Duration, error) {
+	deleteExpiredLoginAttempts()
+
+	count, err := models.IncrementLoginAttempt(key, mailRequestWindow)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > limit {
+		return mailRequestWindow, nil
+	}
+
+	return 0, nil
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+type RegisterRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Şifre politikaya uymuyorsa ihlal edilen kurallarla birlikte 400 döner
+func rejectPasswordPolicy(c *gin.Context, err error) bool {
+	var policyErr *models.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": "ŞİFRE POLİTİKAYA UYMUYOR", "violations": policyErr.Violations})
+	return true
+}
+
+// Doğrulama bağlantısındaki imzalı token. Bağlantı sadece kayıt sırasındaki e-posta adresi için geçerlidir
+type emailVerificationClaims struct {
+	Email string `json:"email"`
+	jwt.StandardClaims
+}
+
+// @Summary Register
+// @Description Creates an inactive user and sends a verification link to the e-mail address. The account can log in after the link is opened; accounts that are not verified within 7 days are deleted
+// @Tags register
+// @Accept json
+// @Produce json
+// @Param input body RegisterRequest true "New account"
+// @Router /register [post]
+func Register(c *gin.Context) {
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Username) == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	if addr, err := mail.ParseAddress(req.Email); err != nil || addr.Address != req.Email {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ E-POSTA ADRESİ"})
+		return
+	}
+
+	// Her kayıt doğrulama e-postası gönderdiğinden kayıtlar da IP ve adres başına sınırlanır
+	limits := []struct {
+		key   string
+		limit int
+	}{
+		{"register-ip:" + c.ClientIP(), registerPerIP},
+		{"register-email:" + strings.ToLower(req.Email), registerPerEmail},
+	}
+
+	for _, limit := range limits {
+		wait, err := mailRequestThrottle(limit.key, limit.limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+			return
+		}
+
+		if wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "ÇOK FAZLA İSTEK, DAHA SONRA TEKRAR DENEYİN"})
+			return
+		}
+	}
+
+	// Süresi dolan doğrulanmamış hesapların kullanıcı adları serbest kalır
+	if _, err := models.DeleteExpiredRegistrations(time.Now().Add(-unverifiedAccountTTL)); err != nil {
+		log.Println("Doğrulanmamış hesaplar silinemedi:", err)
+	}
+
+	user := models.User{Username: strings.TrimSpace(req.Username), Email: req.Email, Password: req.Password, TenantID: models.DefaultTenantID}
+
+	id, err := models.RegisterUser(user)
+	if err != nil {
+		if rejectPasswordPolicy(c, err) {
+			return
+		}
+		if err == models.ErrUsernameTaken {
+			c.JSON(http.StatusConflict, gin.H{"error": "BU KULLANICI ADI ALINMIŞ"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "KAYIT OLUŞTURULAMADI"})
+		return
+	}
+
+	sendVerificationLink(user.Username, user.Email, id)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "KAYIT OLUŞTURULDU, E-POSTA ADRESİNİZE GÖNDERİLEN BAĞLANTI İLE HESABINIZI ETKİNLEŞTİRİN", "id": id})
+}
+
+func sendVerificationLink(username, email string, userID int64) {
+	token, err := signToken(&emailVerificationClaims{
+		Email: email,
+		StandardClaims: jwt.StandardClaims{
+			Id:        randomID(),
+			Subject:   strconv.FormatInt(userID, 10),
+			Audience:  emailVerificationAud,
+			ExpiresAt: time.Now().Add(emailVerificationTTL).Unix(),
+		},
+	})
+	if err == nil {
+		link := appURL("/register/verify", url.Values{"token": {token}})
+		err = sendMail(email, "Hesabınızı doğrulayın",
+			fmt.Sprintf("Merhaba %s,\n\nHesabınızı etkinleştirmek için aşağıdaki bağlantıyı açın (24 saat geçerlidir):\n\n%s\n", username, link))
+	}
+	if err != nil {
+		log.Println("Doğrulama e-postası gönderilemedi:", err)
+	}
+}
+
+// @Summary Resend the verification link
+// @Description Sends a new verification link to every unverified account registered with the e-mail address. The response is the same whether the address is known or not. Accounts that are not verified within 7 days of registration are deleted
+// @Tags register
+// @Accept json
+// @Produce json
+// @Param input body ResendVerificationRequest true "E-mail address"
+// @Router /register/resend [post]
+func ResendVerification(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Email) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	wait, err := mailRequestThrottle("verify-ip:"+c.ClientIP(), resendPerIP)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return
+	}
+
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "ÇOK FAZLA İSTEK, DAHA SONRA TEKRAR DENEYİN"})
+		return
+	}
+
+	// Adres başına sınır aşıldığında da aynı yanıt döner, böylece adresin kayıtlı olup olmadığı anlaşılmaz
+	if wait, err := mailRequestThrottle("verify-email:"+email, resendPerEmail); err != nil {
+		log.Println("Doğrulama e-postası sayacı güncellenemedi:", err)
+	} else if wait == 0 {
+		resendVerificationLinks(email)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "ADRES DOĞRULANMAMIŞ BİR HESABA AİTSE DOĞRULAMA BAĞLANTISI GÖNDERİLDİ"})
+}
+
+func resendVerificationLinks(email string) {
+	if _, err := models.DeleteExpiredRegistrations(time.Now().Add(-unverifiedAccountTTL)); err != nil {
+		log.Println("Doğrulanmamış hesaplar silinemedi:", err)
+	}
+
+	users, err := models.GetUsersByEmail(email)
+	if err != nil {
+		log.Println("Doğrulama için kullanıcılar alınamadı:", err)
+		return
+	}
+
+	for _, user := range users {
+		verified, err := models.IsEmailVerified(user.ID)
+		if err != nil {
+			log.Println("Doğrulama durumu alınamadı:", err)
+			return
+		}
+
+		if !verified {
+			sendVerificationLink(user.Username, user.Email, int64(user.ID))
+		}
+	}
+}
+
+// @Summary Verify e-mail address
+// @Description Activates the account with the signed link sent by /register
+// @Tags register
+// @Produce json
+// @Param token query string true "Verification token"
+// @Router /register/verify [get]
+func VerifyEmail(c *gin.Context) {
+	claims := &emailVerificationClaims{}
+
+	token, err := jwt.ParseWithClaims(c.Query("token"), claims, verificationKey)
+	if err != nil || !token.Valid || !claims.VerifyAudience(emailVerificationAud, true) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ VEYA SÜRESİ DOLMUŞ DOĞRULAMA BAĞLANTISI"})
+		return
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ VEYA SÜRESİ DOLMUŞ DOĞRULAMA BAĞLANTISI"})
+		return
+	}
+
+	verified, err := models.VerifyUserEmail(userID, claims.Email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return
+	}
+
+	if !verified {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ VEYA SÜRESİ DOLMUŞ DOĞRULAMA BAĞLANTISI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "E-POSTA ADRESİ DOĞRULANDI, GİRİŞ YAPABİLİRSİNİZ"})
+}
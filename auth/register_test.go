@@ -0,0 +1,229 @@
+package auth_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/mailer"
+	"example.com/webservice/models"
+)
+
+// Gönderilen e-postaları bellekte tutan test mailer'ı
+type captureMailer struct {
+	sent []mailer.Message
+}
+
+func (m *captureMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+var linkPattern = regexp.MustCompile(`http://example\.test(/\S+)`)
+
+func getPath(r *gin.Engine, path string) int {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func TestRegisterAndVerifyEmail(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/register", auth.Register)
+	r.GET("/register/verify", auth.VerifyEmail)
+
+	outbox := &captureMailer{}
+	auth.ConfigureMail(outbox, "http://example.test/")
+	defer auth.ConfigureMail(mailer.LogMailer{}, "")
+
+	w, _ := postJSON(r, "/register", map[string]string{"username": "yeni", "email": "gecersiz", "password": "kayit2024x"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Geçersiz e-posta adresi kabul edildi. Kod: %d", w.Code)
+	}
+
+	w, resp := postJSON(r, "/register", map[string]string{"username": "yeni", "email": "yeni@test.com", "password": "yeni2024"})
+	if w.Code != http.StatusBadRequest || resp["violations"] == nil {
+		t.Errorf("Politikaya uymayan şifre kabul edildi. Kod: %d, Yanıt: %v", w.Code, resp)
+	}
+
+	w, _ = postJSON(r, "/register", map[string]string{"username": "test", "email": "baska@test.com", "password": "kayit2024x"})
+	if w.Code != http.StatusConflict {
+		t.Errorf("Alınmış kullanıcı adı kabul edildi. Kod: %d", w.Code)
+	}
+
+	w, _ = postJSON(r, "/register", map[string]string{"username": "yeni", "email": "yeni@test.com", "password": "kayit2024x"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Kayıt oluşturulamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	if len(outbox.sent) != 1 || outbox.sent[0].To != "yeni@test.com" {
+		t.Fatalf("Doğrulama e-postası gönderilmedi: %v", outbox.sent)
+	}
+
+	match := linkPattern.FindStringSubmatch(outbox.sent[0].Body)
+	if match == nil {
+		t.Fatalf("E-postada doğrulama bağlantısı yok: %s", outbox.sent[0].Body)
+	}
+
+	// Doğrulanmamış hesap giriş yapamamalı
+	w, _ = postJSON(r, "/login", map[string]string{"username": "yeni", "password": "kayit2024x"})
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Doğrulanmamış hesap giriş yaptı. Kod: %d", w.Code)
+	}
+
+	if code := getPath(r, "/register/verify?token=bozuk"); code != http.StatusBadRequest {
+		t.Errorf("Geçersiz doğrulama bağlantısı kabul edildi. Kod: %d", code)
+	}
+
+	// Doğrulama token'ı access token olarak kullanılamamalı
+	token := match[1][len("/register/verify?token="):]
+	if code := getSecured(r, token); code != http.StatusUnauthorized {
+		t.Errorf("Doğrulama token'ı access token olarak kabul edildi. Kod: %d", code)
+	}
+
+	if code := getPath(r, match[1]); code != http.StatusOK {
+		t.Fatalf("E-posta adresi doğrulanamadı. Kod: %d", code)
+	}
+
+	login(t, r, "yeni", "kayit2024x")
+}
+
+func TestResendVerificationAndExpiry(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/register", auth.Register)
+	r.POST("/register/resend", auth.ResendVerification)
+	r.GET("/register/verify", auth.VerifyEmail)
+
+	outbox := &captureMailer{}
+	auth.ConfigureMail(outbox, "http://example.test/")
+	defer auth.ConfigureMail(mailer.LogMailer{}, "")
+
+	if w, _ := postJSON(r, "/register", map[string]string{"username": "yeni", "email": "yeni@test.com", "password": "kayit2024x"}); w.Code != http.StatusCreated {
+		t.Fatalf("Kayıt oluşturulamadı. Kod: %d", w.Code)
+	}
+
+	// Doğrulanmış hesaplara ve kayıtlı olmayan adreslere e-posta gönderilmez, yanıt aynıdır
+	for _, email := range []string{"test@test.com", "yok@test.com"} {
+		if w, _ := postJSON(r, "/register/resend", map[string]string{"email": email}); w.Code != http.StatusAccepted {
+			t.Errorf("Beklenmeyen yanıt (%s). Kod: %d", email, w.Code)
+		}
+	}
+	if len(outbox.sent) != 1 {
+		t.Fatalf("Doğrulanmamış hesap dışındaki adrese e-posta gönderildi: %v", outbox.sent)
+	}
+
+	if w, _ := postJSON(r, "/register/resend", map[string]string{"email": "YENI@test.com"}); w.Code != http.StatusAccepted {
+		t.Fatalf("Doğrulama bağlantısı tekrar istenemedi. Kod: %d", w.Code)
+	}
+	if len(outbox.sent) != 2 || outbox.sent[1].To != "yeni@test.com" {
+		t.Fatalf("Yeni doğrulama bağlantısı gönderilmedi: %v", outbox.sent)
+	}
+
+	// Adres başına gönderim sınırlıdır
+	for i := 0; i < 3; i++ {
+		postJSON(r, "/register/resend", map[string]string{"email": "yeni@test.com"})
+	}
+	if len(outbox.sent) != 4 {
+		t.Errorf("Beklenen 4 e-posta, gönderilen %d", len(outbox.sent))
+	}
+
+	// Süresi içinde doğrulanmayan hesap silinir ve kullanıcı adı tekrar alınabilir
+	if w, _ := postJSON(r, "/register", map[string]string{"username": "yeni", "email": "baska@test.com", "password": "kayit2024x"}); w.Code != http.StatusConflict {
+		t.Errorf("Doğrulanmamış hesabın kullanıcı adı süresi dolmadan alındı. Kod: %d", w.Code)
+	}
+
+	if _, err := models.DB.Exec("UPDATE user SET created_at = ? WHERE username = 'yeni'", time.Now().Add(-8*24*time.Hour).Unix()); err != nil {
+		t.Fatalf("Kayıt zamanı değiştirilemedi: %v", err)
+	}
+
+	if w, _ := postJSON(r, "/register", map[string]string{"username": "yeni", "email": "baska@test.com", "password": "kayit2024x"}); w.Code != http.StatusCreated {
+		t.Errorf("Süresi dolan hesabın kullanıcı adı alınamadı. Kod: %d", w.Code)
+	}
+
+	// Silinen hesabın bağlantısı yeni hesabı etkinleştirmez
+	match := linkPattern.FindStringSubmatch(outbox.sent[1].Body)
+	if code := getPath(r, match[1]); code != http.StatusBadRequest {
+		t.Errorf("Silinen hesabın doğrulama bağlantısı kabul edildi. Kod: %d", code)
+	}
+
+	// Doğrulanmış hesaplar silinmez
+	if user, err := models.GetUserByID(1, models.AllTenants); err != nil || user.Username != "test" {
+		t.Errorf("Doğrulanmış hesap silindi: %v", err)
+	}
+}
+
+func TestConcurrentRegistrationConflict(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/register", auth.Register)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 10)
+
+	// Adres başına kayıt sınırına takılmamak için her istek farklı bir adres kullanır
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w, _ := postJSON(r, "/register", map[string]string{"username": "ayni", "email": fmt.Sprintf("ayni%d@test.com", i), "password": "kayit2024x"})
+			codes <- w.Code
+		}(i)
+	}
+
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+		default:
+			t.Errorf("Eş zamanlı kayıt beklenmeyen yanıt döndü. Kod: %d", code)
+		}
+	}
+
+	if created != 1 {
+		t.Errorf("Beklenen 1 kayıt, oluşturulan %d", created)
+	}
+}
+
+func TestRegisterThrottled(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/register", auth.Register)
+
+	// Aynı adresle açılan hesaplar sınırlıdır
+	for i := 0; i < 3; i++ {
+		if w, _ := postJSON(r, "/register", map[string]string{"username": fmt.Sprintf("yeni%d", i), "email": "yeni@test.com", "password": "kayit2024x"}); w.Code != http.StatusCreated {
+			t.Fatalf("Kayıt oluşturulamadı. Kod: %d", w.Code)
+		}
+	}
+
+	w, _ := postJSON(r, "/register", map[string]string{"username": "yeni3", "email": "YENI@test.com", "password": "kayit2024x"})
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("Adres başına kayıt sınırı uygulanmadı. Kod: %d", w.Code)
+	}
+	var count int
+	if err := models.DB.QueryRow("SELECT COUNT(*) FROM user WHERE username = 'yeni3'").Scan(&count); err != nil || count != 0 {
+		t.Errorf("Sınır aşıldığında hesap oluşturuldu: %d, %v", count, err)
+	}
+
+	// IP başına sınır farklı adreslerle de aşılamaz
+	for i := 4; i < 10; i++ {
+		postJSON(r, "/register", map[string]string{"username": fmt.Sprintf("yeni%d", i), "email": fmt.Sprintf("yeni%d@test.com", i), "password": "kayit2024x"})
+	}
+	if w, _ := postJSON(r, "/register", map[string]string{"username": "son", "email": "son@test.com", "password": "kayit2024x"}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("IP başına kayıt sınırı uygulanmadı. Kod: %d", w.Code)
+	}
+}
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const (
+	// Veritabanındaki iptal kayıtları bu süreden sonra tamamen yeniden yüklenir
+	revocationCacheTTL = time.Minute
+	// Birden fazla instance çalıştığında diğerlerinin iptalleri en geç bu süre içinde görülür: önbellek bu aralıkla
+	// son eşitlemeden sonraki iptallerle güncellenir
+	revocationSyncInterval = 2 * time.Second
+)
+
+type revocationCache struct {
+	mu       sync.RWMutex
+	tokens   map[string]int64
+	sessions map[string]int64
+	versions map[int]int
+	loadedAt time.Time
+	syncedAt time.Time
+}
+
+var revocations = &revocationCache{}
+
+// Önbellek TTL içinde yüklenmediyse veritabanından yükler, eşitleme aralığı geçtiyse son eşitlemeden sonraki iptalleri
+// ekler. Yükleme başarısız olursa hata döner ve eski önbellek kullanılmaz: veritabanına erişilemediği sürece iptal
+// durumu bilinemez
+func (rc *revocationCache) ensureLoaded() error {
+	rc.mu.RLock()
+	loaded := rc.tokens != nil && time.Since(rc.loadedAt) < revocationCacheTTL
+	synced := time.Since(rc.syncedAt) < revocationSyncInterval
+	syncedAt := rc.syncedAt
+	rc.mu.RUnlock()
+
+	if loaded && synced {
+		return nil
+	}
+
+	if loaded {
+		return rc.sync(syncedAt)
+	}
+
+	return rc.load()
+}
+
+func (rc *revocationCache) load() error {
+	start := time.Now()
+
+	tokens, err := models.GetRevokedTokens()
+	if err != nil {
+		log.Println("İptal edilen token'lar yüklenemedi:", err)
+		return err
+	}
+
+	versions, err := models.GetSessionVersions()
+	if err != nil {
+		log.Println("Oturum versiyonları yüklenemedi:", err)
+		return err
+	}
+
+	// Daha önce iptal edilen oturumlardan üretilmiş access token'ların süresi dolmuştur
+	sessions, err := models.GetRevokedSessions(start.Add(-accessTokenTTL))
+	if err != nil {
+		log.Println("İptal edilen oturumlar yüklenemedi:", err)
+		return err
+	}
+
+	rc.mu.Lock()
+	rc.tokens = tokens
+	rc.sessions = sessions
+	rc.versions = versions
+	rc.loadedAt = start
+	rc.syncedAt = start
+	rc.mu.Unlock()
+
+	return nil
+}
+
+// Kayıt zamanları saniye hassasiyetinde olduğu için son eşitlemeyle aynı saniyedeki iptaller de tekrar okunur
+func (rc *revocationCache) sync(syncedAt time.Time) error {
+	start := time.Now()
+	since := syncedAt.Add(-time.Second)
+
+	tokens, err := models.GetRevokedTokensSince(since)
+	if err != nil {
+		log.Println("İptal edilen token'lar eşitlenemedi:", err)
+		return err
+	}
+
+	versions, err := models.GetSessionVersionsSince(since)
+	if err != nil {
+		log.Println("Oturum versiyonları eşitlenemedi:", err)
+		return err
+	}
+
+	sessions, err := models.GetRevokedSessions(since)
+	if err != nil {
+		log.Println("İptal edilen oturumlar eşitlenemedi:", err)
+		return err
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	// Bu arada tam yükleme yapıldıysa önbellek zaten günceldir
+	if rc.tokens == nil || rc.syncedAt.After(start) {
+		return nil
+	}
+
+	for jti, expiresAt := range tokens {
+		rc.tokens[jti] = expiresAt
+	}
+	for id, revokedAt := range sessions {
+		rc.sessions[id] = revokedAt
+	}
+	for userID, version := range versions {
+		if version > rc.versions[userID] {
+			rc.versions[userID] = version
+		}
+	}
+	rc.syncedAt = start
+
+	return nil
+}
+
+// Token iptal edildiyse veya iptal kayıtları yüklenemediyse true döner (fail closed)
+func (rc *revocationCache) isRevoked(claims *Claims) bool {
+	if err := rc.ensureLoaded(); err != nil {
+		return true
+	}
+
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	if _, ok := rc.tokens[claims.Id]; ok {
+		return true
+	}
+
+	if _, ok := rc.sessions[claims.SessionID]; ok && claims.SessionID != "" {
+		return true
+	}
+
+	if claims.Act != nil && claims.Act.SessionVersion < rc.versions[claims.Act.UserID] {
+		return true
+	}
+
+	return claims.SessionVersion < rc.versions[claims.UserID]
+}
+
+func (rc *revocationCache) sessionVersion(userID int) int {
+	if err := rc.ensureLoaded(); err != nil {
+		// Versiyon bilinmiyorsa token yine de üretilir; önbellek yüklendiğinde eski versiyonlu token reddedilir
+		return 0
+	}
+
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	return rc.versions[userID]
+}
+
+func (rc *revocationCache) revokeToken(jti string, expiresAt int64) {
+	rc.mu.Lock()
+	if rc.tokens != nil {
+		rc.tokens[jti] = expiresAt
+	}
+	rc.mu.Unlock()
+}
+
+func (rc *revocationCache) revokeSession(sessionID string) {
+	rc.mu.Lock()
+	if rc.sessions != nil {
+		rc.sessions[sessionID] = time.Now().Unix()
+	}
+	rc.mu.Unlock()
+}
+
+func (rc *revocationCache) setVersion(userID, version int) {
+	rc.mu.Lock()
+	if rc.versions != nil {
+		rc.versions[userID] = version
+	}
+	rc.mu.Unlock()
+}
+
+// Kullanıcının tüm access ve refresh token'larını geçersiz kılar ve API anahtarlarını siler. Kullanıcı silindiğinde, şifresi
+// veya rolü değiştiğinde de çağrılır
+func InvalidateUserSessions(userID int) error {
+	version, err := models.IncrementSessionVersion(userID)
+	if err != nil {
+		return err
+	}
+
+	revocations.setVersion(userID, version)
+
+	if err := models.RevokeUserRefreshTokens(userID); err != nil {
+		return err
+	}
+
+	// API anahtarları oturum versiyonu taşımaz, her istekte veritabanından doğrulanır
+	return models.DeleteUserAPIKeys(userID)
+}
+
+// @Summary Logout
+// @Description Revokes the access token used for this request and ends its session, so every token issued from the same login is revoked as well. A refresh token can be given to end its session too
+// @Accept json
+// @Produce json
+// @Param input body RefreshRequest false "Refresh token to revoke"
+// @Router /logout [post]
+func Logout(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	if err := models.RevokeToken(claims.Id, time.Unix(claims.ExpiresAt, 0)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ÇIKIŞ YAPILAMADI"})
+		return
+	}
+
+	revocations.revokeToken(claims.Id, claims.ExpiresAt)
+
+	// Token bir oturuma aitse oturum da sonlandırılır, oturumun diğer access token'ları da hemen geçersiz olur
+	if claims.SessionID != "" {
+		if err := models.RevokeRefreshTokenFamily(claims.SessionID); err != nil {
+			log.Println("Oturum sonlandırılamadı:", err)
+		} else {
+			revocations.revokeSession(claims.SessionID)
+		}
+	}
+
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err == nil && req.RefreshToken != "" {
+		stored, err := models.GetRefreshTokenByHash(hashToken(req.RefreshToken))
+		if err == nil && stored.UserID == claims.UserID {
+			if err := models.RevokeRefreshTokenFamily(stored.FamilyID); err != nil {
+				log.Println("Token ailesi iptal edilemedi:", err)
+			} else {
+				revocations.revokeSession(stored.FamilyID)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "BAŞARILI ÇIKIŞ"})
+}
+
+// @Summary Revoke all sessions of a user
+// @Description Invalidates every access and refresh token issued to the user (admin only)
+// @Tags user
+// @Produce json
+// @Param id path int true "User ID"
+// @Router /api/v1/user/{id}/sessions [delete]
+func RevokeUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz Kullanıcı ID'si"})
+		return
+	}
+
+	claims := c.MustGet("claims").(*Claims)
+	if _, err := models.GetUserByID(userID, claims.TenantID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı Bulunamadı"})
+		return
+	}
+
+	if err := InvalidateUserSessions(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Oturumlar iptal edilemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kullanıcının tüm oturumları iptal edildi"})
+}
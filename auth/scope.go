@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Token'ların taşıyabileceği kapsamlar. Kapsamlar rol politikasına ek bir kısıttır, rolün izin vermediği bir işleme izin vermez
+const (
+	ScopePersonRead  = "person:read"
+	ScopePersonWrite = "person:write"
+	ScopeUserAdmin   = "user:admin"
+)
+
+var knownScopes = map[string]bool{
+	ScopePersonRead:  true,
+	ScopePersonWrite: true,
+	ScopeUserAdmin:   true,
+}
+
+var errInvalidScope = errors.New("geçersiz scope")
+
+// OAuth tarzı boşlukla ayrılmış kapsam listesini doğrular
+func parseScopes(scope string) ([]string, error) {
+	return validateScopes(strings.Fields(scope))
+}
+
+// Bilinmeyen kapsam varsa hata döner, tekrarları temizleyip sıralar
+func validateScopes(scopes []string) ([]string, error) {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(scopes))
+
+	for _, scope := range scopes {
+		if !knownScopes[scope] {
+			return nil, errInvalidScope
+		}
+
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+// İstenen kapsamların mevcut kapsamların alt kümesi olup olmadığını kontrol eder. Boş liste kısıtsız anlamına gelir;
+// hiçbir kapsam istenmezse mevcut kapsamlar aynen devredilir
+func narrowScopes(granted, requested []string) ([]string, error) {
+	requested, err := validateScopes(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(requested) == 0 {
+		return granted, nil
+	}
+
+	if len(granted) == 0 {
+		return requested, nil
+	}
+
+	for _, scope := range requested {
+		if !containsScope(granted, scope) {
+			return nil, errInvalidScope
+		}
+	}
+
+	return requested, nil
+}
+
+func containsScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Kapsamsız (tam yetkili) token'lar ile verilen kapsamı taşıyan token'lar geçer
+func (c *Claims) HasScope(scope string) bool {
+	return len(c.Scopes) == 0 || containsScope(c.Scopes, scope)
+}
+
+// Route için gerekli kapsamı kontrol eden middleware. TokenAuthMiddleware'den sonra kullanılmalıdır
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := c.MustGet("claims").(*Claims)
+
+		if !claims.HasScope(scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "YETERSİZ KAPSAM (SCOPE)", "required_scope": scope})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,61 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/auth"
+)
+
+func TestScopedTokens(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) }
+	r.GET("/read", auth.TokenAuthMiddleware(), auth.RequireScope(auth.ScopePersonRead), ok)
+	r.GET("/write", auth.TokenAuthMiddleware(), auth.RequireScope(auth.ScopePersonWrite), ok)
+
+	request := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if w, _ := postJSON(r, "/login", map[string]string{"username": "test", "password": "gizli1234", "scope": "person:delete"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Bilinmeyen scope kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Kapsamsız token tüm kapsamlı route'lara erişebilir
+	full := login(t, r, "test", "gizli1234")["token"].(string)
+	if request("/read", full) != http.StatusOK || request("/write", full) != http.StatusOK {
+		t.Errorf("Kapsamsız token reddedildi")
+	}
+
+	w, resp := postJSON(r, "/login", map[string]string{"username": "test", "password": "gizli1234", "scope": "person:read"})
+	if w.Code != http.StatusOK || resp["scope"] != "person:read" {
+		t.Fatalf("Kapsamlı giriş yapılamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	if code := request("/read", resp["token"].(string)); code != http.StatusOK {
+		t.Errorf("person:read token okuma yapamadı. Kod: %d", code)
+	}
+
+	if code := request("/write", resp["token"].(string)); code != http.StatusForbidden {
+		t.Errorf("person:read token yazma yapabildi. Kod: %d", code)
+	}
+
+	// Yenileme kapsamı koruyor ve genişletemiyor
+	if w, _ := postJSON(r, "/token/refresh", map[string]string{"refresh_token": resp["refresh_token"].(string), "scope": "person:write"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Refresh ile kapsam genişletildi. Kod: %d", w.Code)
+	}
+
+	resp = login(t, r, "test", "gizli1234")
+	w, resp = postJSON(r, "/token/refresh", map[string]string{"refresh_token": resp["refresh_token"].(string), "scope": "person:read"})
+	if w.Code != http.StatusOK || request("/write", resp["token"].(string)) != http.StatusForbidden {
+		t.Errorf("Refresh ile kapsam daraltılamadı. Kod: %d, Yanıt: %v", w.Code, resp)
+	}
+}
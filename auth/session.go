@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+// Oturumun son görülme zamanı veritabanına en fazla bu aralıkla yazılır
+const sessionTouchInterval = time.Minute
+
+// Her istekte veritabanına yazmamak için oturumların son yazılma zamanları bellekte tutulur
+type sessionTracker struct {
+	mu      sync.Mutex
+	written map[string]time.Time
+}
+
+var sessionActivity = &sessionTracker{written: make(map[string]time.Time)}
+
+func (st *sessionTracker) touch(sessionID string) {
+	now := time.Now()
+
+	st.mu.Lock()
+	if now.Sub(st.written[sessionID]) < sessionTouchInterval {
+		st.mu.Unlock()
+		return
+	}
+
+	st.written[sessionID] = now
+
+	// Süresi dolan oturumların kayıtları bellekte birikmesin
+	if len(st.written) > 10000 {
+		for id, at := range st.written {
+			if now.Sub(at) >= sessionTouchInterval {
+				delete(st.written, id)
+			}
+		}
+	}
+	st.mu.Unlock()
+
+	if err := models.TouchSession(sessionID, now); err != nil {
+		log.Println("Oturumun son görülme zamanı güncellenemedi:", err)
+	}
+}
+
+// Yeni bir oturum kaydı oluşturup oturuma ait ilk token çiftini üretir
+func startSession(c *gin.Context, user models.User, scopes []string) (tokenPair, error) {
+	session := models.Session{
+		ID:        randomID(),
+		UserID:    user.ID,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}
+
+	if err := models.CreateSession(session); err != nil {
+		return tokenPair{}, err
+	}
+
+	return issueTokens(user, session.ID, scopes)
+}
+
+type sessionResponse struct {
+	models.Session
+	Current bool `json:"current"`
+}
+
+func listSessions(c *gin.Context, userID int, currentID string) {
+	sessions, err := models.GetUserSessions(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Oturumlar alınamadı"})
+		return
+	}
+
+	response := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = sessionResponse{Session: session, Current: session.ID == currentID}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func revokeSession(c *gin.Context, sessionID string, userID int) bool {
+	if err := models.RevokeSession(sessionID, userID); err != nil {
+		if err == models.ErrSessionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "OTURUM BULUNAMADI"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OTURUM SONLANDIRILAMADI"})
+		return false
+	}
+
+	revocations.revokeSession(sessionID)
+	return true
+}
+
+// @Summary List my sessions
+// @Description Lists the active sessions (logins) of the user with device, IP address and last seen time. The last seen time is updated at most once a minute
+// @Tags session
+// @Produce json
+// @Router /me/sessions [get]
+func GetMySessions(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	listSessions(c, claims.UserID, claims.SessionID)
+}
+
+// @Summary Revoke one of my sessions
+// @Description Ends the session; its access and refresh tokens are revoked
+// @Tags session
+// @Produce json
+// @Param id path string true "Session ID"
+// @Router /me/sessions/{id} [delete]
+func RevokeMySession(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	if revokeSession(c, c.Param("id"), claims.UserID) {
+		c.JSON(http.StatusOK, gin.H{"message": "OTURUM SONLANDIRILDI"})
+	}
+}
+
+// @Summary Revoke my other sessions
+// @Description Ends every session of the user except the one making the request
+// @Tags session
+// @Produce json
+// @Router /me/sessions [delete]
+func RevokeMyOtherSessions(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	revoked, err := models.RevokeOtherSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "OTURUMLAR SONLANDIRILAMADI"})
+		return
+	}
+
+	for _, sessionID := range revoked {
+		revocations.revokeSession(sessionID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "DİĞER OTURUMLAR SONLANDIRILDI", "revoked": len(revoked)})
+}
+
+// @Summary List sessions of a user
+// @Description Lists the active sessions of the user (admin only)
+// @Tags user
+// @Produce json
+// @Param id path int true "User ID"
+// @Router /api/v1/user/{id}/sessions [get]
+func GetUserSessions(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz Kullanıcı ID'si"})
+		return
+	}
+
+	claims := c.MustGet("claims").(*Claims)
+	if _, err := models.GetUserByID(userID, claims.TenantID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı Bulunamadı"})
+		return
+	}
+
+	listSessions(c, userID, "")
+}
+
+// @Summary Revoke a session of a user
+// @Description Ends one session of the user; its access and refresh tokens are revoked (admin only)
+// @Tags user
+// @Produce json
+// @Param id path int true "User ID"
+// @Param sid path string true "Session ID"
+// @Router /api/v1/user/{id}/sessions/{sid} [delete]
+func RevokeUserSession(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz Kullanıcı ID'si"})
+		return
+	}
+
+	claims := c.MustGet("claims").(*Claims)
+	if _, err := models.GetUserByID(userID, claims.TenantID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı Bulunamadı"})
+		return
+	}
+
+	if revokeSession(c, c.Param("sid"), userID) {
+		c.JSON(http.StatusOK, gin.H{"message": "Oturum sonlandırıldı"})
+	}
+}
@@ -0,0 +1,156 @@
+package auth_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+func TestSessions(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.GET("/me/sessions", auth.TokenAuthMiddleware(), auth.GetMySessions)
+	r.DELETE("/me/sessions", auth.TokenAuthMiddleware(), auth.RevokeMyOtherSessions)
+	r.DELETE("/me/sessions/:id", auth.TokenAuthMiddleware(), auth.RevokeMySession)
+
+	loginFrom := func(userAgent string) map[string]interface{} {
+		payload, _ := json.Marshal(map[string]string{"username": "test", "password": "gizli1234"})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Giriş yapılamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+		}
+
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	request := func(method, path, token string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	laptop := loginFrom("Firefox")
+	phone := loginFrom("Mobile Safari")
+	tablet := loginFrom("Tablet")
+
+	code, resp := request(http.MethodGet, "/me/sessions", laptop["token"].(string))
+	if code != http.StatusOK {
+		t.Fatalf("Oturumlar listelenemedi. Kod: %d", code)
+	}
+
+	sessions, _ := resp["data"].([]interface{})
+	if len(sessions) != 3 {
+		t.Fatalf("Beklenen 3 oturum, alınan %d: %v", len(sessions), resp)
+	}
+
+	var phoneSession string
+	current := 0
+	for _, s := range sessions {
+		session := s.(map[string]interface{})
+		if session["current"] == true {
+			current++
+			if session["user_agent"] != "Firefox" {
+				t.Errorf("Geçerli oturum yanlış işaretlendi: %v", session)
+			}
+		}
+		if session["user_agent"] == "Mobile Safari" {
+			phoneSession = session["id"].(string)
+		}
+	}
+
+	if current != 1 || phoneSession == "" {
+		t.Fatalf("Oturum listesi beklenen gibi değil: %v", sessions)
+	}
+
+	if code, _ := request(http.MethodDelete, "/me/sessions/"+phoneSession, laptop["token"].(string)); code != http.StatusOK {
+		t.Fatalf("Oturum sonlandırılamadı. Kod: %d", code)
+	}
+
+	// Sonlandırılan oturumun access ve refresh token'ları geçersiz olmalı
+	if code := getSecured(r, phone["token"].(string)); code != http.StatusUnauthorized {
+		t.Errorf("Sonlandırılan oturumun access token'ı kabul edildi. Kod: %d", code)
+	}
+
+	if w, _ := postJSON(r, "/token/refresh", map[string]string{"refresh_token": phone["refresh_token"].(string)}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Sonlandırılan oturumun refresh token'ı kabul edildi. Kod: %d", w.Code)
+	}
+
+	if code, _ := request(http.MethodDelete, "/me/sessions/"+phoneSession, laptop["token"].(string)); code != http.StatusNotFound {
+		t.Errorf("Sonlandırılmış oturum tekrar sonlandırıldı. Kod: %d", code)
+	}
+
+	// Diğer oturumları sonlandırmak geçerli oturumu etkilememeli
+	if code, resp := request(http.MethodDelete, "/me/sessions", laptop["token"].(string)); code != http.StatusOK || resp["revoked"] != float64(1) {
+		t.Errorf("Diğer oturumlar sonlandırılamadı. Kod: %d, Yanıt: %v", code, resp)
+	}
+
+	if code := getSecured(r, tablet["token"].(string)); code != http.StatusUnauthorized {
+		t.Errorf("Sonlandırılan diğer oturumun token'ı kabul edildi. Kod: %d", code)
+	}
+
+	if code := getSecured(r, laptop["token"].(string)); code != http.StatusOK {
+		t.Errorf("Geçerli oturumun token'ı reddedildi. Kod: %d", code)
+	}
+}
+
+// Başka bir instance'ta yapılan iptaller önbelleğin TTL'ini beklemeden eşitleme aralığında görülür
+func TestRevocationsSyncedFromOtherInstances(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+
+	first := login(t, r, "test", "gizli1234")
+	second := login(t, r, "test", "gizli1234")
+
+	payload, _ := base64.RawURLEncoding.DecodeString(strings.Split(first["token"].(string), ".")[1])
+	var claims struct {
+		SessionID string `json:"sid"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.SessionID == "" {
+		t.Fatalf("Token çözülemedi: %v", err)
+	}
+
+	if code := getSecured(r, first["token"].(string)); code != http.StatusOK {
+		t.Fatalf("Geçerli token reddedildi. Kod: %d", code)
+	}
+
+	// Diğer instance sadece veritabanını günceller
+	if err := models.RevokeSession(claims.SessionID, 1); err != nil {
+		t.Fatalf("Oturum iptal edilemedi: %v", err)
+	}
+
+	auth.ExpireRevocationSync()
+	if code := getSecured(r, first["token"].(string)); code != http.StatusUnauthorized {
+		t.Errorf("Başka instance'ta sonlandırılan oturumun token'ı kabul edildi. Kod: %d", code)
+	}
+	if code := getSecured(r, second["token"].(string)); code != http.StatusOK {
+		t.Fatalf("Diğer oturumun token'ı reddedildi. Kod: %d", code)
+	}
+
+	if _, err := models.IncrementSessionVersion(1); err != nil {
+		t.Fatalf("Oturum versiyonu artırılamadı: %v", err)
+	}
+
+	auth.ExpireRevocationSync()
+	if code := getSecured(r, second["token"].(string)); code != http.StatusUnauthorized {
+		t.Errorf("Başka instance'ta iptal edilen kullanıcı oturumlarının token'ı kabul edildi. Kod: %d", code)
+	}
+}
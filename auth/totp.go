@@ -0,0 +1,578 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+const (
+	totpIssuer        = "Go-Web-Service"
+	totpPeriod        = 30
+	totpDigits        = 6
+	totpSkewSteps     = 1
+	challengeTTL      = 5 * time.Minute
+	challengeAud      = "2fa-challenge"
+	maxCodeAttempts   = 5
+	recoveryCodeCount = 10
+)
+
+// Şifre doğrulandıktan sonra verilen ve sadece /login/2fa uç noktalarında geçerli olan kısa ömürlü token
+type challengeClaims struct {
+	UserID int      `json:"user_id"`
+	Scopes []string `json:"scopes,omitempty"`
+	jwt.StandardClaims
+}
+
+type TwoFactorRequest struct {
+	Challenge    string `json:"challenge"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type RoleTwoFactorRequest struct {
+	Required bool `json:"required"`
+}
+
+func generateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// RFC 6238 TOTP kodu (HMAC-SHA1, 30 saniye, 6 hane)
+func totpCode(secret string, step int64) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// Kodu saat kaymasına karşı önceki ve sonraki adımlarla birlikte kontrol eder, eşleşen adımı döner
+func verifyTOTP(secret, code string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+
+	for i := -totpSkewSteps; i <= totpSkewSteps; i++ {
+		step := current + int64(i)
+		expected, err := totpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func provisioningURI(username, secret string) string {
+	label := url.PathEscape(totpIssuer + ":" + username)
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", totpIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Kurtarma kodlarını üretir. Kodların kendisi sadece bir kez kullanıcıya gösterilir, veritabanında hash'leri tutulur
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		code = code[:4] + "-" + code[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// TOTP kodunu veya kurtarma kodunu doğrular. TOTP kodları tekrar kullanılamaz
+func checkSecondFactor(totp models.UserTOTP, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		if !totp.Enabled {
+			return false, nil
+		}
+		return models.UseRecoveryCode(totp.UserID, hashToken(strings.ToLower(strings.TrimSpace(recoveryCode))))
+	}
+
+	step, ok := verifyTOTP(totp.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return models.UseTOTPStep(totp.UserID, step)
+}
+
+// Kullanıcı için 2FA gerekiyorsa token yerine challenge döner. Yanıt yazıldıysa true döner
+func startTwoFactor(c *gin.Context, user models.User, scopes []string) bool {
+	totp, err := models.GetUserTOTP(user.ID)
+	if err != nil && err != models.ErrTOTPNotFound {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return true
+	}
+
+	enabled := err == nil && totp.Enabled
+
+	required, err := models.IsTwoFactorRequired(user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return true
+	}
+
+	if !enabled && !required {
+		return false
+	}
+
+	deleteExpiredLoginAttempts()
+
+	challenge, err := signToken(&challengeClaims{
+		UserID: user.ID,
+		Scopes: scopes,
+		StandardClaims: jwt.StandardClaims{
+			Id:        randomID(),
+			Audience:  challengeAud,
+			ExpiresAt: time.Now().Add(challengeTTL).Unix(),
+		},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "TOKEN OLUŞTURULAMADI"})
+		return true
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "2FA KODU GEREKLİ",
+		"challenge":      challenge,
+		"setup_required": !enabled,
+	})
+	return true
+}
+
+func parseChallenge(tokenString string) (*challengeClaims, error) {
+	claims := &challengeClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, verificationKey)
+	if err != nil || !token.Valid || !claims.VerifyAudience(challengeAud, true) {
+		return nil, errors.New("geçersiz challenge")
+	}
+
+	return claims, nil
+}
+
+// Kod denemeleri giriş denemeleriyle aynı tabloda (login_attempt) tutulur, böylece sınırlar tüm instance'larda ve yeniden
+// başlatmalardan sonra da geçerlidir. Challenge başına en fazla maxCodeAttempts hatalı deneme yapılabilir; ayrıca kullanıcı
+// başına hatalı denemeler giriş kilidi gibi artan süreli kilit uygular (yeni challenge almak veya token ile denemek sınırı aşmaz)
+const errTooManyCodeAttempts = "ÇOK FAZLA HATALI KOD DENEMESİ, DAHA SONRA TEKRAR DENEYİN"
+
+func challengeKey(claims *challengeClaims) string {
+	return "2fa-challenge:" + claims.Id
+}
+
+func twoFactorKey(userID int) string {
+	return "2fa:" + strconv.Itoa(userID)
+}
+
+// Challenge kullanıldıysa veya hatalı deneme sınırı aşıldıysa false döner
+func challengeUsable(claims *challengeClaims) (bool, error) {
+	attempt, err := models.GetLoginAttempt(challengeKey(claims))
+	if err != nil {
+		return false, err
+	}
+
+	return attempt.Failures < maxCodeAttempts && !attempt.LockedUntil.After(time.Now()), nil
+}
+
+// Challenge'ı süresi dolana kadar kullanılamaz yapar
+func consumeChallenge(claims *challengeClaims) error {
+	return models.LockLoginAttempt(challengeKey(claims), time.Unix(claims.ExpiresAt, 0))
+}
+
+// Kullanıcının kod denemelerinin kilidinin bitmesine kalan süre
+func twoFactorLockedFor(userID int) (time.Duration, error) {
+	attempt, err := models.GetLoginAttempt(twoFactorKey(userID))
+	if err != nil {
+		return 0, err
+	}
+
+	return time.Until(attempt.LockedUntil), nil
+}
+
+// Kullanıcının kod denemeleri kilitliyse 429 yazar ve true döner
+func twoFactorLocked(c *gin.Context, userID int) bool {
+	wait, err := twoFactorLockedFor(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return true
+	}
+
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": errTooManyCodeAttempts})
+		return true
+	}
+
+	return false
+}
+
+func recordCodeFailure(userID int, claims *challengeClaims) {
+	if _, err := countFailure(twoFactorKey(userID), maxCodeAttempts, loginFailureWindow); err != nil {
+		log.Println("Hatalı 2FA kodu kaydedilemedi:", err)
+	}
+
+	if claims != nil {
+		if _, err := models.IncrementLoginAttempt(challengeKey(claims), challengeTTL); err != nil {
+			log.Println("Hatalı 2FA kodu kaydedilemedi:", err)
+		}
+	}
+}
+
+func clearCodeFailures(userID int) {
+	if err := models.ClearLoginAttempts(twoFactorKey(userID)); err != nil {
+		log.Println("Hatalı 2FA kodu kayıtları temizlenemedi:", err)
+	}
+}
+
+// @Summary Complete login with a second factor
+// @Description Exchanges the challenge returned by /login and a TOTP code (or a recovery code) for tokens. If the challenge was issued with setup_required, the code confirms the secret created by /login/2fa/setup and recovery codes are returned
+// @Accept json
+// @Produce json
+// @Param input body TwoFactorRequest true "Challenge and code"
+// @Router /login/2fa [post]
+func LoginTwoFactor(c *gin.Context) {
+	var req TwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Challenge == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	claims, err := parseChallenge(req.Challenge)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ CHALLENGE"})
+		return
+	}
+
+	if usable, err := challengeUsable(claims); err != nil || !usable {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ CHALLENGE"})
+		return
+	}
+
+	if twoFactorLocked(c, claims.UserID) {
+		return
+	}
+
+	totp, err := models.GetUserTOTP(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA KURULUMU GEREKLİ"})
+		return
+	}
+
+	ok, err := checkSecondFactor(totp, req.Code, req.RecoveryCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return
+	}
+
+	if !ok {
+		recordCodeFailure(claims.UserID, claims)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ KOD"})
+		return
+	}
+
+	clearCodeFailures(claims.UserID)
+
+	// Challenge bir kez kullanılabilir
+	if err := consumeChallenge(claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return
+	}
+
+	user, err := models.GetUserByID(claims.UserID, models.AllTenants)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "KULLANICI BULUNAMADI"})
+		return
+	}
+
+	response := gin.H{"message": "BAŞARILI GİRİŞ"}
+
+	if !totp.Enabled {
+		codes, hashes, err := generateRecoveryCodes()
+		if err == nil {
+			err = models.EnableTOTP(user.ID, hashes)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "2FA ETKİNLEŞTİRİLEMEDİ"})
+			return
+		}
+		response["recovery_codes"] = codes
+	}
+
+	tokens, err := startSession(c, user, claims.Scopes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "TOKEN OLUŞTURULAMADI"})
+		return
+	}
+
+	response["token"] = tokens.AccessToken
+	response["refresh_token"] = tokens.RefreshToken
+	response["expires_in"] = tokens.ExpiresIn
+	response["scope"] = strings.Join(tokens.Scopes, " ")
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Set up 2FA during login
+// @Description For users whose role requires 2FA but who have not enrolled yet. Returns a new TOTP secret for the challenge returned by /login
+// @Accept json
+// @Produce json
+// @Param input body TwoFactorRequest true "Challenge"
+// @Router /login/2fa/setup [post]
+func LoginTwoFactorSetup(c *gin.Context) {
+	var req TwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Challenge == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	claims, err := parseChallenge(req.Challenge)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ CHALLENGE"})
+		return
+	}
+
+	if usable, err := challengeUsable(claims); err != nil || !usable {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ CHALLENGE"})
+		return
+	}
+
+	user, err := models.GetUserByID(claims.UserID, models.AllTenants)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "KULLANICI BULUNAMADI"})
+		return
+	}
+
+	enrollTOTP(c, user.ID, user.Username)
+}
+
+// @Summary Enroll in 2FA
+// @Description Creates a new TOTP secret for the logged in user. The secret must be confirmed with /2fa/confirm before it is used at login
+// @Tags 2fa
+// @Produce json
+// @Router /2fa/enroll [post]
+func EnrollTwoFactor(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+	enrollTOTP(c, claims.UserID, claims.Username)
+}
+
+func enrollTOTP(c *gin.Context, userID int, username string) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "2FA ANAHTARI OLUŞTURULAMADI"})
+		return
+	}
+
+	if err := models.SavePendingTOTP(userID, secret); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA ZATEN ETKİN"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":           secret,
+		"provisioning_uri": provisioningURI(username, secret),
+	})
+}
+
+// @Summary Confirm 2FA enrollment
+// @Description Confirms the TOTP secret created by /2fa/enroll with a code from the authenticator app and returns one-time recovery codes
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Param input body TwoFactorCodeRequest true "TOTP code"
+// @Router /2fa/confirm [post]
+func ConfirmTwoFactor(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	totp, err := models.GetUserTOTP(claims.UserID)
+	if err != nil || totp.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ONAY BEKLEYEN 2FA KAYDI YOK"})
+		return
+	}
+
+	if twoFactorLocked(c, claims.UserID) {
+		return
+	}
+
+	ok, err := checkSecondFactor(totp, req.Code, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return
+	}
+
+	if !ok {
+		recordCodeFailure(claims.UserID, nil)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ KOD"})
+		return
+	}
+
+	clearCodeFailures(claims.UserID)
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err == nil {
+		err = models.EnableTOTP(claims.UserID, hashes)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "2FA ETKİNLEŞTİRİLEMEDİ"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "2FA ETKİNLEŞTİRİLDİ", "recovery_codes": codes})
+}
+
+// @Summary Disable 2FA
+// @Description Disables 2FA for the logged in user after verifying a TOTP or recovery code. Not allowed when the user's role requires 2FA
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Param input body TwoFactorCodeRequest true "TOTP code or recovery code"
+// @Router /2fa/disable [post]
+func DisableTwoFactor(c *gin.Context) {
+	claims := c.MustGet("claims").(*Claims)
+	if rejectMachineAuth(c, claims) {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	required, err := models.IsTwoFactorRequired(claims.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return
+	}
+
+	if required {
+		c.JSON(http.StatusForbidden, gin.H{"error": "BU ROL İÇİN 2FA ZORUNLU"})
+		return
+	}
+
+	totp, err := models.GetUserTOTP(claims.UserID)
+	if err != nil || !totp.Enabled {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "2FA ETKİN DEĞİL"})
+		return
+	}
+
+	if twoFactorLocked(c, claims.UserID) {
+		return
+	}
+
+	ok, err := checkSecondFactor(totp, req.Code, req.RecoveryCode)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "BİLİNMEYEN HATA"})
+		return
+	}
+
+	if !ok {
+		recordCodeFailure(claims.UserID, nil)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "GEÇERSİZ KOD"})
+		return
+	}
+
+	clearCodeFailures(claims.UserID)
+
+	if err := models.DisableTOTP(claims.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "2FA DEVRE DIŞI BIRAKILAMADI"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "2FA DEVRE DIŞI BIRAKILDI"})
+}
+
+// @Summary Require 2FA for a role
+// @Description Makes 2FA mandatory (or optional) for every user with the role (platform admin only)
+// @Tags rbac
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name"
+// @Param input body RoleTwoFactorRequest true "Whether 2FA is required"
+// @Router /api/v1/role/{name}/2fa [put]
+func SetRoleTwoFactor(c *gin.Context) {
+	var req RoleTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "GEÇERSİZ İSTEK"})
+		return
+	}
+
+	if err := models.SetTwoFactorRequired(c.Param("name"), req.Required); err != nil {
+		if err == models.ErrRoleNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Rol bulunamadı"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rol güncellenemedi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rol güncellendi"})
+}
@@ -0,0 +1,153 @@
+package auth_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+// Doğrulayıcı uygulamanın üreteceği RFC 6238 kodu
+func totpNow(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("Anahtar çözülemedi: %v", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestTwoFactorLogin(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/login/2fa", auth.LoginTwoFactor)
+	r.POST("/2fa/enroll", auth.TokenAuthMiddleware(), auth.EnrollTwoFactor)
+	r.POST("/2fa/confirm", auth.TokenAuthMiddleware(), auth.ConfirmTwoFactor)
+
+	token := login(t, r, "test", "gizli1234")["token"].(string)
+
+	w, resp := postJSONWithToken(r, "/2fa/enroll", token, nil)
+	secret, _ := resp["secret"].(string)
+	if w.Code != http.StatusOK || secret == "" || !strings.HasPrefix(resp["provisioning_uri"].(string), "otpauth://totp/") {
+		t.Fatalf("2FA kaydı başlatılamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	// Onaylanmadan girişte 2FA istenmez
+	if resp := login(t, r, "test", "gizli1234"); resp["token"] == nil {
+		t.Fatalf("Onaylanmamış 2FA girişte istendi: %v", resp)
+	}
+
+	if w, _ := postJSONWithToken(r, "/2fa/confirm", token, map[string]string{"code": "abcdef"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Yanlış kod ile 2FA onaylandı. Kod: %d", w.Code)
+	}
+
+	code := totpNow(t, secret)
+	w, resp = postJSONWithToken(r, "/2fa/confirm", token, map[string]string{"code": code})
+	codes, _ := resp["recovery_codes"].([]interface{})
+	if w.Code != http.StatusOK || len(codes) == 0 {
+		t.Fatalf("2FA onaylanamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	resp = login(t, r, "test", "gizli1234")
+	challenge, _ := resp["challenge"].(string)
+	if resp["token"] != nil || challenge == "" {
+		t.Fatalf("2FA etkin kullanıcıya challenge yerine token verildi: %v", resp)
+	}
+
+	// Challenge erişim token'ı olarak kullanılamaz
+	if code := getSecured(r, challenge); code != http.StatusUnauthorized {
+		t.Errorf("Challenge ile güvenli uç noktaya erişildi. Kod: %d", code)
+	}
+
+	// Onayda kullanılan kod tekrar kullanılamaz
+	if w, _ := postJSON(r, "/login/2fa", map[string]string{"challenge": challenge, "code": code}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Kullanılmış TOTP kodu kabul edildi. Kod: %d", w.Code)
+	}
+
+	recovery := codes[0].(string)
+	w, resp = postJSON(r, "/login/2fa", map[string]string{"challenge": challenge, "recovery_code": recovery})
+	if w.Code != http.StatusOK || resp["token"] == nil {
+		t.Fatalf("Kurtarma kodu ile giriş yapılamadı. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	// Challenge ve kurtarma kodu tek kullanımlıktır
+	resp = login(t, r, "test", "gizli1234")
+	if w, _ := postJSON(r, "/login/2fa", map[string]string{"challenge": resp["challenge"].(string), "recovery_code": recovery}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Kullanılmış kurtarma kodu kabul edildi. Kod: %d", w.Code)
+	}
+
+	if w, _ := postJSON(r, "/login/2fa", map[string]string{"challenge": challenge, "recovery_code": codes[1].(string)}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Kullanılmış challenge kabul edildi. Kod: %d", w.Code)
+	}
+}
+
+func TestTwoFactorCodeAttemptsLimited(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+	r.POST("/login/2fa", auth.LoginTwoFactor)
+	r.POST("/2fa/enroll", auth.TokenAuthMiddleware(), auth.EnrollTwoFactor)
+	r.POST("/2fa/confirm", auth.TokenAuthMiddleware(), auth.ConfirmTwoFactor)
+	r.POST("/2fa/disable", auth.TokenAuthMiddleware(), auth.DisableTwoFactor)
+
+	token := login(t, r, "test", "gizli1234")["token"].(string)
+	_, resp := postJSONWithToken(r, "/2fa/enroll", token, nil)
+	secret := resp["secret"].(string)
+	if w, _ := postJSONWithToken(r, "/2fa/confirm", token, map[string]string{"code": totpNow(t, secret)}); w.Code != http.StatusOK {
+		t.Fatalf("2FA onaylanamadı. Kod: %d", w.Code)
+	}
+
+	// Challenge kilitten önce alınır; kilit 1 saniye sürdüğü için kontroller şifre doğrulamasını beklememeli
+	challenge := login(t, r, "test", "gizli1234")["challenge"].(string)
+
+	// Çalınmış bir access token ile kod denenerek 2FA kapatılamamalı
+	for i := 0; i < 5; i++ {
+		if w, _ := postJSONWithToken(r, "/2fa/disable", token, map[string]string{"code": "000000"}); w.Code != http.StatusUnauthorized {
+			t.Fatalf("Yanlış kod kabul edildi. Kod: %d", w.Code)
+		}
+	}
+
+	if w, _ := postJSONWithToken(r, "/2fa/disable", token, map[string]string{"code": totpNow(t, secret)}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Deneme sınırı aşıldığı halde kod denendi. Kod: %d", w.Code)
+	}
+
+	// Kilit yeni challenge ile de aşılamaz
+	if w, _ := postJSON(r, "/login/2fa", map[string]string{"challenge": challenge, "code": "000000"}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Kilitli kullanıcı için challenge kodu denendi. Kod: %d", w.Code)
+	}
+}
+
+func TestRoleRequiresTwoFactor(t *testing.T) {
+	setupTestDB(t)
+	r := setupRouter()
+
+	if _, err := models.DB.Exec("INSERT OR IGNORE INTO role (name, description) VALUES ('user', '')"); err != nil {
+		t.Fatalf("Rol eklenemedi: %v", err)
+	}
+
+	if err := models.SetTwoFactorRequired("user", true); err != nil {
+		t.Fatalf("Rol güncellenemedi: %v", err)
+	}
+
+	resp := login(t, r, "test", "gizli1234")
+	if resp["token"] != nil || resp["setup_required"] != true {
+		t.Errorf("2FA zorunlu role kurulum istenmedi: %v", resp)
+	}
+}
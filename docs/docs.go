@@ -19,9 +19,19 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/v1/person": {
+        "/.well-known/jwks.json": {
             "get": {
-                "description": "Get persons list from the database",
+                "description": "Public keys that can be used to verify tokens issued by this service. Shared HS256 secrets are never published",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {}
+            }
+        },
+        "/2fa/confirm": {
+            "post": {
+                "description": "Confirms the TOTP secret created by /2fa/enroll with a code from the authenticator app and returns one-time recovery codes",
                 "consumes": [
                     "application/json"
                 ],
@@ -29,10 +39,86 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "person"
+                    "2fa"
                 ],
-                "summary": "Get a list of persons with pagination",
+                "summary": "Confirm 2FA enrollment",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/2fa/disable": {
+            "post": {
+                "description": "Disables 2FA for the logged in user after verifying a TOTP or recovery code. Not allowed when the user's role requires 2FA",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Disable 2FA",
                 "parameters": [
+                    {
+                        "description": "TOTP code or recovery code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/2fa/enroll": {
+            "post": {
+                "description": "Creates a new TOTP secret for the logged in user. The secret must be confirmed with /2fa/confirm before it is used at login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Enroll in 2FA",
+                "responses": {}
+            }
+        },
+        "/api/v1/audit/impersonation": {
+            "get": {
+                "description": "Lists impersonation starts and every request made with an impersonation token, newest first (platform admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Impersonation audit log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Impersonated user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Admin",
+                        "name": "admin_id",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "Page number for pagination (default is 1)",
@@ -46,17 +132,30 @@ const docTemplate = `{
                         "in": "query"
                     }
                 ],
+                "responses": {}
+            }
+        },
+        "/api/v1/group": {
+            "get": {
+                "description": "Lists user groups with their member IDs",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "group"
+                ],
+                "summary": "List groups",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Person"
+                            "$ref": "#/definitions/models.Group"
                         }
                     }
                 }
             },
             "post": {
-                "description": "Add a new person to the database",
+                "description": "Creates a user group that persons can be shared with",
                 "consumes": [
                     "application/json"
                 ],
@@ -64,81 +163,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "person"
+                    "group"
                 ],
-                "summary": "Add a new person",
+                "summary": "Create a group",
                 "parameters": [
                     {
-                        "description": "New Person Object",
-                        "name": "person",
+                        "description": "New group",
+                        "name": "group",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Person"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "Person added successfully",
-                        "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Group"
                         }
                     }
-                }
-            },
-            "options": {
-                "description": "Get available options for the API",
-                "produces": [
-                    "text/plain"
-                ],
-                "tags": [
-                    "persons"
                 ],
-                "summary": "Get available options",
-                "responses": {
-                    "200": {
-                        "description": "Available options for the API",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
+                "responses": {}
             }
         },
-        "/api/v1/person/{id}": {
-            "get": {
-                "description": "Get a person by their ID from the database",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/group/{id}": {
+            "delete": {
+                "description": "Deletes a group, its memberships and all shares made with it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "person"
+                    "group"
                 ],
-                "summary": "Get a person by ID",
+                "summary": "Delete a group",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Person ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Person"
-                        }
-                    }
-                }
-            },
-            "put": {
-                "description": "Update a person's information in the database by their ID",
+                "responses": {}
+            }
+        },
+        "/api/v1/group/{id}/member": {
+            "post": {
+                "description": "Adds a user to a group",
                 "consumes": [
                     "application/json"
                 ],
@@ -146,105 +212,73 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "person"
+                    "group"
                 ],
-                "summary": "Update a person's information by their ID",
+                "summary": "Add a group member",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Person ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated Person Object",
-                        "name": "person",
+                        "description": "User to add",
+                        "name": "member",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Person"
+                            "$ref": "#/definitions/main.groupMemberRequest"
                         }
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "Person updated successfully",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            },
+                "responses": {}
+            }
+        },
+        "/api/v1/group/{id}/member/{userId}": {
             "delete": {
-                "description": "Delete a person from the database by their ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Removes a user from a group",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "person"
+                    "group"
                 ],
-                "summary": "Delete a person by their ID",
+                "summary": "Remove a group member",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Person ID",
+                        "description": "Group ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "Person deleted successfully",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
+                "responses": {}
             }
         },
-        "/api/v1/user": {
+        "/api/v1/oauth/client": {
             "get": {
-                "description": "Get users list from the database",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Lists the registered OAuth2 clients (platform admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
-                ],
-                "summary": "Get a list of users with pagination",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number for pagination (default is 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of items per page (default is 20)",
-                        "name": "pageSize",
-                        "in": "query"
-                    }
+                    "oauth"
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.User"
-                        }
-                    }
-                }
+                "summary": "List OAuth2 clients",
+                "responses": {}
             },
             "post": {
-                "description": "Create a new user in the database",
+                "description": "Registers a new OAuth2 client (platform admin only). The secret of a confidential client is returned only once. client_credentials tokens act as the user given in user_id",
                 "consumes": [
                     "application/json"
                 ],
@@ -252,63 +286,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "oauth"
                 ],
-                "summary": "Create a new user",
+                "summary": "Register an OAuth2 client",
                 "parameters": [
                     {
-                        "description": "New user details",
-                        "name": "newUser",
+                        "description": "Client",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/auth.OAuthClientRequest"
                         }
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "integer"
-                        }
-                    }
-                }
+                "responses": {}
             }
         },
-        "/api/v1/user/{id}": {
-            "get": {
-                "description": "Get a user by their ID from the database",
-                "consumes": [
-                    "application/json"
-                ],
+        "/api/v1/oauth/client/{id}": {
+            "delete": {
+                "description": "Deletes an OAuth2 client and revokes its refresh tokens and the sessions granted to it (platform admin only)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "oauth"
                 ],
-                "summary": "Get a user by ID",
+                "summary": "Delete an OAuth2 client",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
+                        "type": "string",
+                        "description": "Client ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.User"
-                        }
-                    }
-                }
+                "responses": {}
+            }
+        },
+        "/api/v1/partner": {
+            "get": {
+                "description": "Lists the partners that sign their requests with a shared secret (platform admin only). Secrets are never shown again after creation",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "partner"
+                ],
+                "summary": "List partners",
+                "responses": {}
             },
-            "put": {
-                "description": "Update an existing user in the database",
+            "post": {
+                "description": "Registers a partner that signs requests with HMAC-SHA256 instead of a JWT (platform admin only). Signed requests act as the user given in user_id; scopes default to person:write. The secret is returned only once",
                 "consumes": [
                     "application/json"
                 ],
@@ -316,85 +346,1495 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "partner"
                 ],
-                "summary": "Update an existing user",
+                "summary": "Register a partner",
+                "parameters": [
+                    {
+                        "description": "Partner",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.PartnerRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/partner/{id}": {
+            "delete": {
+                "description": "Deletes a partner; its signed requests are refused immediately (platform admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "partner"
+                ],
+                "summary": "Delete a partner",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partner ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/partner/{id}/rotate": {
+            "post": {
+                "description": "Issues a new secret for the partner (platform admin only). The previous secret keeps working for 24 hours so the partner can switch without downtime",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "partner"
+                ],
+                "summary": "Rotate a partner secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Partner ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/permission": {
+            "post": {
+                "description": "Grants a role access to a route and method. Route and method may be \"*\". Condition may be empty or \"self\" (platform admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Add a permission",
+                "parameters": [
+                    {
+                        "description": "New permission",
+                        "name": "permission",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Permission"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/permission/{id}": {
+            "delete": {
+                "description": "Removes a permission by its ID (platform admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Delete a permission",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID to update",
+                        "description": "Permission ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/person": {
+            "get": {
+                "description": "Get persons list from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Get a list of persons with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (default is 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default is 20)",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact first name (case insensitive)",
+                        "name": "first_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact last name (case insensitive)",
+                        "name": "last_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact e-mail address (case insensitive)",
+                        "name": "email",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact IP address",
+                        "name": "ip_address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Domain of the e-mail address, e.g. example.com",
+                        "name": "email_domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text contained in the first name, last name or e-mail address",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated fields (id, first_name, last_name, email, ip_address); prefix with - for descending, e.g. -last_name,first_name",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Person"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new person to the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Add a new person",
+                "parameters": [
+                    {
+                        "description": "New Person Object",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Person"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Person added successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "options": {
+                "description": "Get available options for the API",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "persons"
+                ],
+                "summary": "Get available options",
+                "responses": {
+                    "200": {
+                        "description": "Available options for the API",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/person/search": {
+            "get": {
+                "description": "Full-text search over first name, last name and e-mail address. Every word is matched as a prefix, case and diacritics are ignored (sukru finds Şükrü). Results are ordered by relevance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Search persons",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (default is 1)",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "Updated user details",
-                        "name": "updatedUser",
+                        "type": "integer",
+                        "description": "Number of items per page (default is 20)",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PersonMatch"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/person/{id}": {
+            "get": {
+                "description": "Get a person by their ID from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Get a person by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Person"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update a person's information in the database by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Update a person's information by their ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated Person Object",
+                        "name": "person",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Person"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Person updated successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a person from the database by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Delete a person by their ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Person deleted successfully",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/person/{id}/share": {
+            "get": {
+                "description": "Lists the users and groups a person is shared with. Only the owner or an admin can see them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "List shares of a person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PersonShare"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Shares a person with a user or a group. Set can_edit to allow updates. Only the owner or an admin can share",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Share a person",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Share target (either user_id or group_id)",
+                        "name": "share",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PersonShare"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/person/{id}/share/{shareId}": {
+            "delete": {
+                "description": "Stops sharing a person with a user or group. Only the owner or an admin can remove shares",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "person"
+                ],
+                "summary": "Remove a share",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Person ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Share ID",
+                        "name": "shareId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/role": {
+            "get": {
+                "description": "Lists roles together with their permissions (platform admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "List roles",
+                "responses": {}
+            },
+            "post": {
+                "description": "Creates a new role without permissions (platform admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Create a role",
+                "parameters": [
+                    {
+                        "description": "New role",
+                        "name": "role",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/role/{name}": {
+            "delete": {
+                "description": "Deletes a role and all of its permissions (platform admin only). The admin role cannot be deleted",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Delete a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/role/{name}/2fa": {
+            "put": {
+                "description": "Makes 2FA mandatory (or optional) for every user with the role (platform admin only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rbac"
+                ],
+                "summary": "Require 2FA for a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Whether 2FA is required",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.RoleTwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/tenant": {
+            "get": {
+                "description": "Lists all tenants (platform admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenant"
+                ],
+                "summary": "List tenants",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tenant"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new tenant together with its first admin user (platform admin only). The admin can then manage users, groups and persons of the new tenant only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tenant"
+                ],
+                "summary": "Provision a tenant",
+                "parameters": [
+                    {
+                        "description": "Tenant name and initial admin",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.tenantRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/user": {
+            "get": {
+                "description": "Get users list from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get a list of users with pagination",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number for pagination (default is 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default is 20)",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new user in the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "New user details",
+                        "name": "newUser",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "integer"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}": {
+            "get": {
+                "description": "Get a user by their ID from the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Get a user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing user in the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Update an existing user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID to update",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated user details",
+                        "name": "updatedUser",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a user from the database by their ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Delete a user by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID to delete",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/user/{id}/impersonate": {
+            "post": {
+                "description": "Issues a short-lived (10 minutes) access token acting as the user, without a refresh token (admin only). The token carries an act claim with the admin and the admin's scopes; it can only read persons, users and groups and create or update persons, and every request is written to the audit log before it is handled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Impersonate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason of the impersonation",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ImpersonateRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/user/{id}/lockout": {
+            "delete": {
+                "description": "Clears failed login attempts and the temporary lockout of the user (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Unlock a user's login",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/user/{id}/sessions": {
+            "get": {
+                "description": "Lists the active sessions of the user (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "List sessions of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Invalidates every access and refresh token issued to the user (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Revoke all sessions of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/api/v1/user/{id}/sessions/{sid}": {
+            "delete": {
+                "description": "Ends one session of the user; its access and refresh tokens are revoked (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Revoke a session of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "sid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/apikey": {
+            "get": {
+                "description": "Lists the API keys of the logged in user. The keys themselves are never shown again after creation",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikey"
+                ],
+                "summary": "List API keys",
+                "responses": {}
+            },
+            "post": {
+                "description": "Creates a named API key for the logged in user. The key is returned only once; send it in the X-API-Key header or as \"Authorization: ApiKey \u003ckey\u003e\"",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikey"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "API key",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.APIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/apikey/{id}": {
+            "put": {
+                "description": "Changes the name, scopes or expiry of an API key of the logged in user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikey"
+                ],
+                "summary": "Update an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "API key",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.APIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Deletes (revokes) an API key of the logged in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "apikey"
+                ],
+                "summary": "Delete an API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Allows users to log in with their credentials",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "User Login",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.Credentials"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/login/2fa": {
+            "post": {
+                "description": "Exchanges the challenge returned by /login and a TOTP code (or a recovery code) for tokens. If the challenge was issued with setup_required, the code confirms the secret created by /login/2fa/setup and recovery codes are returned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Complete login with a second factor",
+                "parameters": [
+                    {
+                        "description": "Challenge and code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/login/2fa/setup": {
+            "post": {
+                "description": "For users whose role requires 2FA but who have not enrolled yet. Returns a new TOTP secret for the challenge returned by /login",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Set up 2FA during login",
+                "parameters": [
+                    {
+                        "description": "Challenge",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.TwoFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Revokes the access token used for this request and ends its session, so every token issued from the same login is revoked as well. A refresh token can be given to end its session too",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "description": "Refresh token to revoke",
+                        "name": "input",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/auth.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/me/sessions": {
+            "get": {
+                "description": "Lists the active sessions (logins) of the user with device, IP address and last seen time. The last seen time is updated at most once a minute",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "List my sessions",
+                "responses": {}
+            },
+            "delete": {
+                "description": "Ends every session of the user except the one making the request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Revoke my other sessions",
+                "responses": {}
+            }
+        },
+        "/me/sessions/{id}": {
+            "delete": {
+                "description": "Ends the session; its access and refresh tokens are revoked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "session"
+                ],
+                "summary": "Revoke one of my sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "description": "Shows the consent page of the authorization code flow. PKCE (code_challenge_method=S256) is required",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth2 authorization endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "code",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered redirect URI",
+                        "name": "redirect_uri",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space separated scopes",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque value returned to the client",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge",
+                        "name": "code_challenge",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "S256",
+                        "name": "code_challenge_method",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "description": "Authenticates the user on the consent page and redirects back to the client with an authorization code",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth2 consent form",
+                "responses": {}
+            }
+        },
+        "/oauth/introspect": {
+            "post": {
+                "description": "Returns whether an access or refresh token is active, with its metadata (RFC 7662). Only confidential clients may call it",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth2 token introspection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token to inspect",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "Supports the client_credentials, authorization_code (with PKCE) and refresh_token grants. Clients authenticate with HTTP Basic or client_id/client_secret form fields",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "OAuth2 token endpoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "client_credentials, authorization_code or refresh_token",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/oidc/callback": {
+            "get": {
+                "description": "Exchanges the authorization code, verifies the ID token and returns tokens, or a 2FA challenge like /login when the account requires a second factor. Users signing in for the first time are created with the role mapped from the provider claims",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oidc"
+                ],
+                "summary": "OpenID Connect callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/oidc/link": {
+            "post": {
+                "description": "Returns the provider URL that links the logged in local account to the user's OIDC identity. After linking, the user can log in through /oidc/login",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oidc"
+                ],
+                "summary": "Link the OpenID Connect account",
+                "responses": {}
+            }
+        },
+        "/oidc/login": {
+            "get": {
+                "description": "Redirects to the configured OIDC provider. After the user signs in, the provider redirects to /oidc/callback which returns the tokens",
+                "tags": [
+                    "oidc"
+                ],
+                "summary": "Log in with the OpenID Connect provider",
+                "responses": {}
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Sends a single-use password reset link to every account registered with the e-mail address. The response is the same whether the address is known or not",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "password"
+                ],
+                "summary": "Forgot password",
+                "parameters": [
+                    {
+                        "description": "E-mail address",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/password/reset": {
+            "get": {
+                "description": "The page opened from the reset link; posts the new password to /password/reset",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "password"
+                ],
+                "summary": "Password reset form",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reset token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "description": "Sets a new password with a token from /password/forgot. The token can be used once; every session and refresh token of the user is revoked",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "password"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/auth.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Creates an inactive user and sends a verification link to the e-mail address. The account can log in after the link is opened; accounts that are not verified within 7 days are deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "register"
+                ],
+                "summary": "Register",
+                "parameters": [
+                    {
+                        "description": "New account",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "$ref": "#/definitions/auth.RegisterRequest"
                         }
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "responses": {}
+            }
+        },
+        "/register/resend": {
+            "post": {
+                "description": "Sends a new verification link to every unverified account registered with the e-mail address. The response is the same whether the address is known or not. Accounts that are not verified within 7 days of registration are deleted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "register"
+                ],
+                "summary": "Resend the verification link",
+                "parameters": [
+                    {
+                        "description": "E-mail address",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/auth.ResendVerificationRequest"
                         }
                     }
-                }
-            },
-            "delete": {
-                "description": "Delete a user from the database by their ID",
-                "consumes": [
-                    "application/json"
                 ],
+                "responses": {}
+            }
+        },
+        "/register/verify": {
+            "get": {
+                "description": "Activates the account with the signed link sent by /register",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "register"
                 ],
-                "summary": "Delete a user by ID",
+                "summary": "Verify e-mail address",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID to delete",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "query",
                         "required": true
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
+                "responses": {}
             }
         },
-        "/login": {
+        "/token/refresh": {
             "post": {
-                "description": "Allows users to log in with their credentials",
+                "description": "Exchanges a refresh token for a new access token and a new refresh token. A refresh token can only be used once; reusing it revokes every token issued from the same login",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "User Login",
+                "summary": "Refresh access token",
                 "parameters": [
                     {
-                        "description": "User credentials",
+                        "description": "Refresh token",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/auth.Credentials"
+                            "$ref": "#/definitions/auth.RefreshRequest"
                         }
                     }
                 ],
@@ -403,9 +1843,117 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "auth.APIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "auth.Credentials": {
             "type": "object",
             "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "İsteğe bağlı, boşlukla ayrılmış kapsam listesi (örn. \"person:read\")",
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.ImpersonateRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.OAuthClientRequest": {
+            "type": "object",
+            "properties": {
+                "confidential": {
+                    "description": "false ise public istemci (secret yok, PKCE zorunlu)",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "redirect_uris": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "description": "client_credentials ile alınan token'ların adına işlem yapacağı kullanıcı",
+                    "type": "integer"
+                }
+            }
+        },
+        "auth.PartnerRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "auth.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                },
+                "scope": {
+                    "description": "İsteğe bağlı, kapsam sadece daraltılabilir",
+                    "type": "string"
+                }
+            }
+        },
+        "auth.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
                 "password": {
                     "type": "string"
                 },
@@ -414,6 +1962,102 @@ const docTemplate = `{
                 }
             }
         },
+        "auth.ResendVerificationRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.RoleTwoFactorRequest": {
+            "type": "object",
+            "properties": {
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "auth.TwoFactorCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "auth.TwoFactorRequest": {
+            "type": "object",
+            "properties": {
+                "challenge": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.groupMemberRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.tenantRequest": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "$ref": "#/definitions/models.User"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Group": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Permission": {
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Person": {
             "type": "object",
             "properties": {
@@ -431,6 +2075,71 @@ const docTemplate = `{
                 }
             }
         },
+        "models.PersonMatch": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "ip_address": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PersonShare": {
+            "type": "object",
+            "properties": {
+                "can_edit": {
+                    "type": "boolean"
+                },
+                "group_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Role": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "require_2fa": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.Tenant": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "properties": {
@@ -453,6 +2162,12 @@ const docTemplate = `{
         }
     },
     "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key created with POST /apikey.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
         "BearerAuth": {
             "description": "Type \"Bearer\" followed by a space and JWT token.",
             "type": "apiKey",
@@ -0,0 +1,121 @@
+// Package mailer kullanıcılara e-posta göndermek için kullanılan arayüzü ve SMTP, dosya ve log tabanlı gerçekleştirmelerini içerir
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// SMTP sunucusu üzerinden e-posta gönderir. Username boşsa kimlik doğrulama yapılmaz
+type SMTPMailer struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+}
+
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host := m.Addr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	return smtp.SendMail(m.Addr, auth, m.From, []string{msg.To}, formatMessage(m.From, msg))
+}
+
+// E-postaları gönderme yerine bir dosyanın sonuna yazar (geliştirme ortamı için)
+type FileMailer struct {
+	mu   sync.Mutex
+	Path string
+}
+
+func (m *FileMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\n%s\n\n", time.Now().Format(time.RFC3339), formatMessage("", msg))
+	return err
+}
+
+// Bağlantılardaki sorgu parametrelerinin değerleri (doğrulama ve sıfırlama token'ları)
+var queryValuePattern = regexp.MustCompile(`([?&][^=\s&]+=)[^&\s]+`)
+
+// E-postaları uygulama loguna yazar (geliştirme ortamı için). Loglara erişen herkes hesapları ele geçiremesin diye
+// bağlantılardaki token'lar gizlenir, bu yüzden bağlantılar loglardan kullanılamaz
+type LogMailer struct{}
+
+func (LogMailer) Send(msg Message) error {
+	log.Printf("E-POSTA -> %s | %s\n%s", msg.To, msg.Subject, queryValuePattern.ReplaceAllString(msg.Body, "${1}[GİZLENDİ]"))
+	return nil
+}
+
+func formatMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	if from != "" {
+		fmt.Fprintf(&b, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	// Başlıklar ASCII olmalıdır, Türkçe karakter içeren konu RFC 2047'ye göre kodlanır
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
+
+// MAIL_DRIVER ortam değişkenine göre mailer oluşturur: "smtp" (SMTP_ADDR, SMTP_FROM, SMTP_USERNAME, SMTP_PASSWORD),
+// "file" (MAIL_FILE) veya "log". E-postalar hesap token'ları içerdiği için varsayılan yoktur, sürücü açıkça seçilmelidir
+func FromEnv() (Mailer, error) {
+	switch driver := os.Getenv("MAIL_DRIVER"); driver {
+	case "":
+		return nil, fmt.Errorf("MAIL_DRIVER tanımlanmalı: smtp, file veya log")
+	case "log":
+		return LogMailer{}, nil
+	case "file":
+		path := os.Getenv("MAIL_FILE")
+		if path == "" {
+			path = "mail.log"
+		}
+		return &FileMailer{Path: path}, nil
+	case "smtp":
+		m := &SMTPMailer{
+			Addr:     os.Getenv("SMTP_ADDR"),
+			From:     os.Getenv("SMTP_FROM"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+		}
+		if m.Addr == "" || m.From == "" {
+			return nil, fmt.Errorf("SMTP_ADDR ve SMTP_FROM gerekli")
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("bilinmeyen MAIL_DRIVER: %s", driver)
+	}
+}
@@ -0,0 +1,78 @@
+package mailer_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example.com/webservice/mailer"
+)
+
+func TestFileMailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := &mailer.FileMailer{Path: path}
+
+	if err := m.Send(mailer.Message{To: "ali@test.com", Subject: "Merhaba", Body: "İçerik"}); err != nil {
+		t.Fatalf("E-posta yazılamadı: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Dosya okunamadı: %v", err)
+	}
+
+	for _, want := range []string{"To: ali@test.com", "Subject: Merhaba", "İçerik"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("E-posta dosyasında %q yok: %s", want, data)
+		}
+	}
+}
+
+func TestSubjectEncoding(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := &mailer.FileMailer{Path: path}
+
+	if err := m.Send(mailer.Message{To: "ali@test.com", Subject: "Hesabınızı doğrulayın", Body: "İçerik"}); err != nil {
+		t.Fatalf("E-posta yazılamadı: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "Subject: =?utf-8?q?Hesab=C4=B1n=C4=B1z=C4=B1_do=C4=9Frulay=C4=B1n?=\r\n") {
+		t.Errorf("Konu başlığı kodlanmadı: %s", data)
+	}
+}
+
+func TestLogMailerRedactsTokens(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	mailer.LogMailer{}.Send(mailer.Message{To: "ali@test.com", Subject: "Şifre sıfırlama", Body: "Bağlantı: http://localhost:8080/password/reset?token=gizli-token&lang=tr\n"})
+
+	if strings.Contains(buf.String(), "gizli-token") || !strings.Contains(buf.String(), "/password/reset?token=[GİZLENDİ]&lang=[GİZLENDİ]") {
+		t.Errorf("Token loga yazıldı: %s", buf.String())
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("MAIL_DRIVER", "")
+	if _, err := mailer.FromEnv(); err == nil {
+		t.Errorf("MAIL_DRIVER tanımlanmadan mailer oluşturuldu")
+	}
+
+	t.Setenv("MAIL_DRIVER", "smtp")
+	t.Setenv("SMTP_ADDR", "")
+	if _, err := mailer.FromEnv(); err == nil {
+		t.Errorf("Eksik SMTP ayarı kabul edildi")
+	}
+
+	t.Setenv("MAIL_DRIVER", "file")
+	if m, err := mailer.FromEnv(); err != nil {
+		t.Errorf("file mailer oluşturulamadı: %v", err)
+	} else if _, ok := m.(*mailer.FileMailer); !ok {
+		t.Errorf("Beklenmeyen mailer tipi: %T", m)
+	}
+}
@@ -2,14 +2,18 @@ package main
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"example.com/webservice/mailer"
 	"example.com/webservice/models"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -56,14 +60,77 @@ func init() {
 // @in header
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key created with POST /apikey.
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := auth.LoadKeysFromEnv(); err != nil {
+		log.Fatal("JWT anahtarları yüklenemedi: ", err)
+	}
+
+	if err := auth.LoadOIDCFromEnv(); err != nil {
+		log.Fatal("OIDC ayarları yüklenemedi: ", err)
+	}
+
+	if err := models.LoadPasswordPolicyFromEnv(); err != nil {
+		log.Fatal("Şifre politikası yüklenemedi: ", err)
+	}
+
+	if err := models.LoadPartnerKeyFromEnv(); err != nil {
+		log.Fatal("Partner anahtarı yüklenemedi: ", err)
+	} else if os.Getenv("PARTNER_SECRET_KEY") == "" {
+		log.Println("PARTNER_SECRET_KEY tanımlanmadı; partner oluşturma ve imzalı istekler kullanılamaz")
+	}
+
+	if err := auth.LoadMTLSFromEnv(); err != nil {
+		log.Fatal("İstemci sertifikası eşlemeleri yüklenemedi: ", err)
+	}
+
+	tlsConfig, tlsErr := auth.LoadTLSFromEnv()
+	if tlsErr != nil {
+		log.Fatal("TLS ayarları yüklenemedi: ", tlsErr)
+	}
+
+	m, mailErr := mailer.FromEnv()
+	if mailErr != nil {
+		log.Fatal("Mailer ayarları yüklenemedi: ", mailErr)
+	}
+	auth.ConfigureMail(m, os.Getenv("APP_BASE_URL"))
+
+	// DB_AUTO_MIGRATE=false ise migrasyonlar `migrate up` ile ayrıca uygulanmalıdır
+	models.SetAutoMigrate(os.Getenv("DB_AUTO_MIGRATE") != "false")
+
+	err := models.ConnectDatabase()
+	checkErr(err)
+
+	if err := checkPendingMigrations(); err != nil {
+		log.Fatal(err)
+	}
+
+	h := newHandlers(models.Persons(), models.Users())
+
 	r := gin.Default()
 
+	// X-Forwarded-For sadece TRUSTED_PROXIES'teki proxy'lerden gelirse dikkate alınır. Aksi halde istemci başlığı değiştirerek
+	// IP başına giriş ve şifre sıfırlama sınırlarını atlatabilir
+	if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatal("TRUSTED_PROXIES okunamadı: ", err)
+	}
+
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"*"} // İZİN VERİLEN URL'LER (TÜMÜ)
 	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Authorization", "Content-Type"}
+	config.AllowHeaders = []string{"Authorization", "Content-Type", "X-API-Key", "X-Partner-Id", "X-Timestamp", "X-Nonce", "X-Signature"}
+	config.ExposeHeaders = []string{"X-Impersonated-User", "X-Impersonator"}
 
 	r.Use(cors.New(config))
 
@@ -81,44 +148,152 @@ func main() {
 
 	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	r.GET("/.well-known/jwks.json", auth.JWKS)
+
 	r.POST("/login", auth.Login)
-	r.GET("/secured", auth.TokenAuthMiddleware(), auth.SecuredEndpoint) // TOKEN ÖRNEĞİ: İSTENİLEN ENDPOINT İÇİN auth.TokenAuthMiddleware() KULLANILIR ÖRNEK: v1.GET("person", auth.TokenAuthMiddleware(), getPersons)
+	r.POST("/register", auth.Register)
+	r.GET("/register/verify", auth.VerifyEmail)
+	r.POST("/register/resend", auth.ResendVerification)
+	r.POST("/password/forgot", auth.ForgotPassword)
+	r.GET("/password/reset", auth.ResetPasswordForm)
+	r.POST("/password/reset", auth.ResetPassword)
+	r.POST("/login/2fa", auth.LoginTwoFactor)
+	r.POST("/login/2fa/setup", auth.LoginTwoFactorSetup)
+	r.POST("/token/refresh", auth.RefreshToken)
+	r.POST("/logout", auth.TokenAuthMiddleware(), auth.Logout)
+	r.GET("/me/sessions", auth.TokenAuthMiddleware(), auth.GetMySessions)
+	r.DELETE("/me/sessions", auth.TokenAuthMiddleware(), auth.RevokeMyOtherSessions)
+	r.DELETE("/me/sessions/:id", auth.TokenAuthMiddleware(), auth.RevokeMySession)
+	r.POST("/2fa/enroll", auth.TokenAuthMiddleware(), auth.EnrollTwoFactor)
+	r.POST("/2fa/confirm", auth.TokenAuthMiddleware(), auth.ConfirmTwoFactor)
+	r.POST("/2fa/disable", auth.TokenAuthMiddleware(), auth.DisableTwoFactor)
+	r.GET("/oauth/authorize", auth.OAuthAuthorize)
+	r.POST("/oauth/authorize", auth.OAuthAuthorizeSubmit)
+	r.POST("/oauth/token", auth.OAuthToken)
+	r.POST("/oauth/introspect", auth.OAuthIntrospect)
+	r.GET("/oidc/login", auth.OIDCLogin)
+	r.GET("/oidc/callback", auth.OIDCCallback)
+	r.POST("/oidc/link", auth.TokenAuthMiddleware(), auth.OIDCLink)
+	r.GET("/apikey", auth.TokenAuthMiddleware(), auth.GetAPIKeys)
+	r.POST("/apikey", auth.TokenAuthMiddleware(), auth.CreateAPIKey)
+	r.PUT("/apikey/:id", auth.TokenAuthMiddleware(), auth.UpdateAPIKey)
+	r.DELETE("/apikey/:id", auth.TokenAuthMiddleware(), auth.DeleteAPIKey)
+	r.GET("/secured", auth.TokenAuthMiddleware(), auth.SecuredEndpoint) // TOKEN ÖRNEĞİ: İSTENİLEN ENDPOINT İÇİN auth.TokenAuthMiddleware() KULLANILIR ÖRNEK: v1.GET("person", auth.TokenAuthMiddleware(), h.getPersons)
 
 	v1 := r.Group("/api/v1")
+	v1.Use(auth.TokenAuthMiddleware(), auth.Authorize()) // YETKİLER ROL BAZLI POLİTİKA TABLOSUNDAN OKUNUR (role_permission)
+
+	// TOKEN KAPSAMI (SCOPE) KISITLARI: KAPSAMSIZ TOKEN'LAR SADECE ROL POLİTİKASINA TABİDİR
+	personRead := auth.RequireScope(auth.ScopePersonRead)
+	personWrite := auth.RequireScope(auth.ScopePersonWrite)
+	userAdmin := auth.RequireScope(auth.ScopeUserAdmin)
 
 	{
-		v1.GET("person", auth.TokenAuthMiddleware(), getPersons)
-		v1.GET("person/:id", auth.TokenAuthMiddleware(), getPersonById)
-		v1.POST("person", auth.TokenAuthMiddleware(), addPerson)
-		v1.PUT("person/:id", auth.TokenAuthMiddleware(), updatePerson)
-		v1.DELETE("person/:id", auth.TokenAuthMiddleware(), deletePerson)
-		v1.OPTIONS("person", auth.TokenAuthMiddleware(), options)
-		v1.GET("/user", auth.TokenAuthMiddleware(), getUsers)
-		v1.GET("/user/:id", auth.TokenAuthMiddleware(), getUserByID)
-		v1.POST("/user", auth.TokenAuthMiddleware(), addUser)
-		v1.PUT("/user/:id", auth.TokenAuthMiddleware(), updateUser)
-		v1.DELETE("/user/:id", auth.TokenAuthMiddleware(), deleteUser)
+		v1.GET("person", personRead, h.getPersons)
+		v1.GET("person/search", personRead, h.searchPersons)
+		v1.GET("person/:id", personRead, h.getPersonById)
+		v1.POST("person", personWrite, h.addPerson)
+		v1.PUT("person/:id", personWrite, h.updatePerson)
+		v1.DELETE("person/:id", personWrite, h.deletePerson)
+		v1.OPTIONS("person", options)
+		v1.GET("person/:id/share", personRead, getPersonShares)
+		v1.POST("person/:id/share", personWrite, sharePerson)
+		v1.DELETE("person/:id/share/:shareId", personWrite, deletePersonShare)
+		v1.GET("/user", userAdmin, h.getUsers)
+		v1.GET("/user/:id", userAdmin, h.getUserByID)
+		v1.POST("/user", userAdmin, h.addUser)
+		v1.PUT("/user/:id", userAdmin, h.updateUser)
+		v1.DELETE("/user/:id", userAdmin, h.deleteUser)
+		v1.GET("/user/:id/sessions", userAdmin, auth.GetUserSessions)
+		v1.DELETE("/user/:id/sessions", userAdmin, auth.RevokeUserSessions)
+		v1.DELETE("/user/:id/sessions/:sid", userAdmin, auth.RevokeUserSession)
+		v1.DELETE("/user/:id/lockout", userAdmin, auth.UnlockUser)
+		v1.POST("/user/:id/impersonate", userAdmin, auth.ImpersonateUser)
+		v1.GET("/audit/impersonation", userAdmin, auth.PlatformAdminOnly(), auth.GetImpersonationAudit)
+		v1.GET("/group", userAdmin, getGroups)
+		v1.POST("/group", userAdmin, addGroup)
+		v1.DELETE("/group/:id", userAdmin, deleteGroup)
+		v1.POST("/group/:id/member", userAdmin, addGroupMember)
+		v1.DELETE("/group/:id/member/:userId", userAdmin, removeGroupMember)
+		v1.GET("/role", userAdmin, auth.PlatformAdminOnly(), auth.GetRoles)
+		v1.POST("/role", userAdmin, auth.PlatformAdminOnly(), auth.CreateRole)
+		v1.DELETE("/role/:name", userAdmin, auth.PlatformAdminOnly(), auth.DeleteRole)
+		v1.PUT("/role/:name/2fa", userAdmin, auth.PlatformAdminOnly(), auth.SetRoleTwoFactor)
+		v1.POST("/permission", userAdmin, auth.PlatformAdminOnly(), auth.AddPermission)
+		v1.DELETE("/permission/:id", userAdmin, auth.PlatformAdminOnly(), auth.DeletePermission)
+		v1.GET("/oauth/client", userAdmin, auth.PlatformAdminOnly(), auth.GetOAuthClients)
+		v1.POST("/oauth/client", userAdmin, auth.PlatformAdminOnly(), auth.CreateOAuthClient)
+		v1.DELETE("/oauth/client/:id", userAdmin, auth.PlatformAdminOnly(), auth.DeleteOAuthClient)
+		v1.GET("/partner", userAdmin, auth.PlatformAdminOnly(), auth.GetPartners)
+		v1.POST("/partner", userAdmin, auth.PlatformAdminOnly(), auth.CreatePartner)
+		v1.POST("/partner/:id/rotate", userAdmin, auth.PlatformAdminOnly(), auth.RotatePartnerSecret)
+		v1.DELETE("/partner/:id", userAdmin, auth.PlatformAdminOnly(), auth.DeletePartner)
+		v1.GET("/tenant", userAdmin, auth.PlatformAdminOnly(), getTenants)
+		v1.POST("/tenant", userAdmin, auth.PlatformAdminOnly(), addTenant)
 	}
 
-	err := models.ConnectDatabase()
+	err = auth.LoadPolicy()
 	checkErr(err)
 
+	// TLS ayarlandıysa istemci sertifikalarını da doğrulayan HTTPS sunucusu başlatılır
+	if tlsConfig != nil {
+		addr := ":8080"
+		if port := os.Getenv("PORT"); port != "" {
+			addr = ":" + port
+		}
+
+		server := &http.Server{Addr: addr, Handler: r, TLSConfig: tlsConfig}
+		log.Fatal(server.ListenAndServeTLS("", ""))
+	}
+
 	r.Run()
 
 }
 
+// TRUSTED_PROXIES: virgülle ayrılmış IP veya CIDR listesi. Boşsa hiçbir proxy'ye güvenilmez
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 func checkErr(err error) {
 	if err != nil {
 		log.Println("Error:", err)
 	}
 }
 
+// Kişi ve kullanıcı handler'larının bağımlılıkları. Handler'lar veritabanına doğrudan değil depo arayüzleri üzerinden erişir
+type handlers struct {
+	persons models.PersonRepository
+	users   models.UserRepository
+}
+
+func newHandlers(persons models.PersonRepository, users models.UserRepository) *handlers {
+	return &handlers{persons: persons, users: users}
+}
+
+// İsteği yapan kullanıcıya göre kişi sorgularının kapsamı
+func personScope(c *gin.Context) models.PersonScope {
+	claims := c.MustGet("claims").(*auth.Claims)
+	return models.PersonScope{UserID: claims.UserID, TenantID: claims.TenantID, Admin: claims.Role == "admin"}
+}
+
+// İsteği yapan kullanıcının tenant'ı. Tüm kullanıcı ve grup sorguları bu tenant ile sınırlandırılır
+func tenantID(c *gin.Context) int {
+	return c.MustGet("claims").(*auth.Claims).TenantID
+}
+
 func handleRequest(f func(*gin.Context), c *gin.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 	f(c)
 }
 
-func getPersons(c *gin.Context) {
+func (h *handlers) getPersons(c *gin.Context) {
 
 	start := time.Now()
 
@@ -135,7 +310,14 @@ func getPersons(c *gin.Context) {
 		pageSize = 20
 	}
 
-	totalPersons, err := models.GetTotalPersonsCount() // Veritabanındaki toplam person
+	filters, err := models.ParsePersonQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Hata": err.Error()})
+		crudOperations.WithLabelValues("GET", "bad_request").Inc()
+		return
+	}
+
+	totalPersons, err := h.persons.Count(personScope(c), filters) // Filtrelere uyan toplam person
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sunucu hatası: Kişi verileri alınamadı"})
@@ -149,26 +331,30 @@ func getPersons(c *gin.Context) {
 	if page > totalPages {
 		page = totalPages // Kullanıcının girdiği sayfa numarası toplam sayfa numarasından büyükse mevcut olan en son sayfayı getir
 	}
+	if page < 1 {
+		page = 1
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 
 	go handleRequest(func(c *gin.Context) {
 		offset := (page - 1) * pageSize
-		persons, err := models.GetPersons(pageSize, offset)
+		persons, err := h.persons.List(pageSize, offset, personScope(c), filters)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Veritabanından kişiler alınamadı"})
 			crudOperations.WithLabelValues("GET", "error").Inc()
 			return
 		}
 
-		if len(persons) == 0 {
+		// Filtreye uyan kayıt olmaması hata değildir, boş liste döner
+		if len(persons) == 0 && !filters.HasFilters() {
 			c.JSON(http.StatusBadRequest, gin.H{"Hata": "Kayıt bulunamadı"})
 			crudOperations.WithLabelValues("GET", "not_found").Inc()
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"data": persons})
+		c.JSON(http.StatusOK, gin.H{"data": persons, "total": totalPersons, "page": page, "pageSize": pageSize, "totalPages": totalPages})
 		crudOperations.WithLabelValues("GET", "success").Inc()
 	}, c, &wg)
 
@@ -178,7 +364,55 @@ func getPersons(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/person", "GET").Observe(duration)
 }
 
-func getPersonById(c *gin.Context) {
+func (h *handlers) searchPersons(c *gin.Context) {
+	start := time.Now()
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go handleRequest(func(c *gin.Context) {
+		text := strings.TrimSpace(c.Query("q"))
+		if text == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"Hata": "Arama metni (q) boş olamaz"})
+			crudOperations.WithLabelValues("GET", "bad_request").Inc()
+			return
+		}
+
+		searcher, ok := h.persons.(models.PersonSearcher)
+		if !ok {
+			c.JSON(http.StatusNotImplemented, gin.H{"Hata": models.ErrSearchNotSupported.Error()})
+			crudOperations.WithLabelValues("GET", "error").Inc()
+			return
+		}
+
+		matches, total, err := searcher.Search(text, pageSize, (page-1)*pageSize, personScope(c))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Veritabanında arama yapılamadı"})
+			crudOperations.WithLabelValues("GET", "error").Inc()
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"data": matches, "total": total, "page": page, "pageSize": pageSize})
+		crudOperations.WithLabelValues("GET", "success").Inc()
+	}, c, &wg)
+
+	wg.Wait()
+
+	duration := time.Since(start).Seconds()
+	requestDuration.WithLabelValues("/api/v1/person/search", "GET").Observe(duration)
+}
+
+func (h *handlers) getPersonById(c *gin.Context) {
 	start := time.Now()
 
 	var wg sync.WaitGroup
@@ -186,7 +420,7 @@ func getPersonById(c *gin.Context) {
 
 	go handleRequest(func(c *gin.Context) {
 		id := c.Param("id")
-		person, err := models.GetPersonById(id)
+		person, err := h.persons.Get(id, personScope(c))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"HATA": "Veritabanında kişi aranırken bir hata oluştu"})
 			crudOperations.WithLabelValues("getPersonById", "error").Inc()
@@ -209,7 +443,7 @@ func getPersonById(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/person/:id", "GET").Observe(duration)
 }
 
-func addPerson(c *gin.Context) {
+func (h *handlers) addPerson(c *gin.Context) {
 	start := time.Now()
 
 	var wg sync.WaitGroup
@@ -230,7 +464,7 @@ func addPerson(c *gin.Context) {
 			return
 		}
 
-		success, err := models.AddPerson(json)
+		success, err := h.persons.Add(json, personScope(c))
 
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Kişi eklenirken bir hata oluştu"})
@@ -253,7 +487,7 @@ func addPerson(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/person", "POST").Observe(duration)
 }
 
-func updatePerson(c *gin.Context) {
+func (h *handlers) updatePerson(c *gin.Context) {
 	start := time.Now()
 
 	var wg sync.WaitGroup
@@ -273,9 +507,10 @@ func updatePerson(c *gin.Context) {
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"HATA": "GEÇERSİZ ID !"})
 			crudOperations.WithLabelValues("updatePerson", "invalid_id").Inc()
+			return
 		}
 
-		success, err := models.UpdatePerson(json, personId)
+		success, err := h.persons.Update(json, personId, personScope(c))
 
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Kişi güncellenirken bir hata oluştu"})
@@ -298,7 +533,7 @@ func updatePerson(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/person/:id", "PUT").Observe(duration)
 }
 
-func deletePerson(c *gin.Context) {
+func (h *handlers) deletePerson(c *gin.Context) {
 	start := time.Now()
 
 	var wg sync.WaitGroup
@@ -310,9 +545,10 @@ func deletePerson(c *gin.Context) {
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"HATA": "GEÇERSİZ ID !"})
 			crudOperations.WithLabelValues("deletePerson", "invalid_id").Inc()
+			return
 		}
 
-		success, err := models.DeletePerson(personId)
+		success, err := h.persons.Delete(personId, personScope(c))
 
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Kişi silinirken bir hata oluştu"})
@@ -363,7 +599,7 @@ func options(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/person", "OPTIONS").Observe(duration)
 }
 
-func getUsers(c *gin.Context) {
+func (h *handlers) getUsers(c *gin.Context) {
 	start := time.Now()
 
 	pageStr := c.DefaultQuery("page", "1")
@@ -379,7 +615,7 @@ func getUsers(c *gin.Context) {
 		pageSize = 20
 	}
 
-	totalUsers, err := models.GetTotalUsersCount() // Veritabanındaki toplam user
+	totalUsers, err := h.users.Count(tenantID(c)) // Veritabanındaki toplam user
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sunucu hatası: Kişi verileri alınamadı"})
@@ -393,13 +629,16 @@ func getUsers(c *gin.Context) {
 	if page > totalPages {
 		page = totalPages // Kullanıcının girdiği sayfa numarası toplam sayfa numarasından büyükse mevcut olan en son sayfayı getir
 	}
+	if page < 1 {
+		page = 1 // Hiç kullanıcı yoksa totalPages 0 olur, offset negatif olmamalı
+	}
 
 	var wg sync.WaitGroup
 	wg.Add(1)
 
 	go handleRequest(func(c *gin.Context) {
 		offset := (page - 1) * pageSize
-		users, err := models.GetUsers(pageSize, offset)
+		users, err := h.users.List(pageSize, offset, tenantID(c))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Kullanıcılar alınamadı"})
 			crudOperations.WithLabelValues("GET", "error").Inc()
@@ -422,7 +661,7 @@ func getUsers(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/user", "GET").Observe(duration)
 }
 
-func getUserByID(c *gin.Context) {
+func (h *handlers) getUserByID(c *gin.Context) {
 	start := time.Now()
 
 	var wg sync.WaitGroup
@@ -436,7 +675,7 @@ func getUserByID(c *gin.Context) {
 			return
 		}
 
-		user, err := models.GetUserByID(userID)
+		user, err := h.users.GetByID(userID, tenantID(c))
 		if err != nil {
 			if err == sql.ErrNoRows {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Kullanıcı Bulunamadı"})
@@ -455,7 +694,7 @@ func getUserByID(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/user/:id", "GET").Observe(duration)
 }
 
-func addUser(c *gin.Context) {
+func (h *handlers) addUser(c *gin.Context) {
 	start := time.Now()
 
 	var wg sync.WaitGroup
@@ -470,7 +709,20 @@ func addUser(c *gin.Context) {
 			return
 		}
 
-		id, err := models.CreateUser(user)
+		user.TenantID = tenantID(c)
+
+		id, err := h.users.Create(user)
+		var policyErr *models.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"Hata": "Şifre politikaya uymuyor", "violations": policyErr.Violations})
+			crudOperations.WithLabelValues("addUser", "bad_request").Inc()
+			return
+		}
+		if err == models.ErrUsernameTaken {
+			c.JSON(http.StatusConflict, gin.H{"Hata": "Bu kullanıcı adı zaten alınmış"})
+			crudOperations.WithLabelValues("addUser", "failed").Inc()
+			return
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Kullanıcı eklenemedi"})
 			crudOperations.WithLabelValues("addUser", "error").Inc()
@@ -492,7 +744,7 @@ func addUser(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/user", "POST").Observe(duration)
 }
 
-func updateUser(c *gin.Context) {
+func (h *handlers) updateUser(c *gin.Context) {
 	start := time.Now()
 
 	var wg sync.WaitGroup
@@ -516,14 +768,52 @@ func updateUser(c *gin.Context) {
 		}
 
 		user.ID = userID
+		user.TenantID = tenantID(c)
 
-		err = models.UpdateUser(user)
+		if user.Role != "" {
+			exists, err := models.RoleExists(user.Role)
+			if err != nil || !exists {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Geçersiz rol"})
+				crudOperations.WithLabelValues("updateUser", "bad_request").Inc()
+				return
+			}
+		}
+
+		previous, err := h.users.GetByID(userID, user.TenantID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcı güncellenemedi"})
 			crudOperations.WithLabelValues("updateUser", "error").Inc()
 			return
 		}
 
+		err = h.users.Update(user)
+		var policyErr *models.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Şifre politikaya uymuyor", "violations": policyErr.Violations})
+			crudOperations.WithLabelValues("updateUser", "bad_request").Inc()
+			return
+		}
+		if err == models.ErrUsernameTaken {
+			c.JSON(http.StatusConflict, gin.H{"error": "Bu kullanıcı adı zaten alınmış"})
+			crudOperations.WithLabelValues("updateUser", "failed").Inc()
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Kullanıcı güncellenemedi"})
+			crudOperations.WithLabelValues("updateUser", "error").Inc()
+			return
+		}
+
+		// Rolü veya şifresi değişen kullanıcının mevcut token'ları geçersiz kılınır
+		newRole := user.Role
+		if newRole == "" {
+			newRole = "user"
+		}
+
+		if user.Password != "" || newRole != previous.Role {
+			checkErr(auth.InvalidateUserSessions(userID))
+		}
+
 		c.JSON(http.StatusOK, gin.H{"message": "Kullanıcı başarıyla güncellendi"})
 		crudOperations.WithLabelValues("updateUser", "success").Inc()
 	}, c, &wg)
@@ -534,7 +824,7 @@ func updateUser(c *gin.Context) {
 	requestDuration.WithLabelValues("/api/v1/user/:id", "PUT").Observe(duration)
 }
 
-func deleteUser(c *gin.Context) {
+func (h *handlers) deleteUser(c *gin.Context) {
 	start := time.Now()
 
 	var wg sync.WaitGroup
@@ -544,13 +834,15 @@ func deleteUser(c *gin.Context) {
 		userID := c.Param("id")
 		id, _ := strconv.Atoi(userID)
 
-		err := models.DeleteUser(id)
+		err := h.users.Delete(id, tenantID(c))
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Kullanıcı silinemedi"})
 			crudOperations.WithLabelValues("deleteUser", "error").Inc()
 			return
 		}
 
+		checkErr(auth.InvalidateUserSessions(id))
+
 		c.JSON(http.StatusOK, gin.H{"message": "Kullanıcı başarıyla silindi"})
 		crudOperations.WithLabelValues("deleteUser", "success").Inc()
 	}, c, &wg)
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/auth"
+	"example.com/webservice/models"
+)
+
+// Handler testleri için bellekte tutulan kişi deposu
+type memoryPersons struct {
+	persons []models.Person
+	scopes  []models.PersonScope
+	filters []models.PersonQuery
+	err     error
+}
+
+func (m *memoryPersons) List(limit, offset int, scope models.PersonScope, filters models.PersonQuery) ([]models.Person, error) {
+	m.filters = append(m.filters, filters)
+	if offset >= len(m.persons) {
+		return []models.Person{}, m.err
+	}
+	end := offset + limit
+	if end > len(m.persons) {
+		end = len(m.persons)
+	}
+	return m.persons[offset:end], m.err
+}
+
+func (m *memoryPersons) Count(scope models.PersonScope, filters models.PersonQuery) (int, error) {
+	return len(m.persons), m.err
+}
+
+func (m *memoryPersons) Get(id string, scope models.PersonScope) (models.Person, error) {
+	for _, p := range m.persons {
+		if strconv.Itoa(p.Id) == id {
+			return p, m.err
+		}
+	}
+	return models.Person{}, m.err
+}
+
+func (m *memoryPersons) Add(person models.Person, scope models.PersonScope) (bool, error) {
+	m.scopes = append(m.scopes, scope)
+	person.Id = len(m.persons) + 1
+	m.persons = append(m.persons, person)
+	return m.err == nil, m.err
+}
+
+func (m *memoryPersons) Update(person models.Person, id int, scope models.PersonScope) (bool, error) {
+	return false, m.err
+}
+
+func (m *memoryPersons) Delete(id int, scope models.PersonScope) (bool, error) {
+	return false, m.err
+}
+
+func newTestRouter(persons models.PersonRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	h := newHandlers(persons, nil)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("claims", &auth.Claims{UserID: 7, TenantID: 3, Role: "user"})
+	})
+	r.GET("/api/v1/person", h.getPersons)
+	r.GET("/api/v1/person/search", h.searchPersons)
+	r.POST("/api/v1/person", h.addPerson)
+	return r
+}
+
+func TestPersonHandlersUseRepository(t *testing.T) {
+	persons := &memoryPersons{}
+	r := newTestRouter(persons)
+
+	body := []byte(`{"first_name":"Ali","last_name":"Veli","email":"ali@test.com","ip_address":"127.0.0.1"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/person", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || len(persons.persons) != 1 {
+		t.Fatalf("Kişi depoya eklenmedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	// Kapsam isteği yapan kullanıcının claim'lerinden oluşturulmalı
+	if scope := persons.scopes[0]; scope.UserID != 7 || scope.TenantID != 3 || scope.Admin {
+		t.Errorf("Depoya yanlış kapsam verildi: %+v", scope)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/person?page=5", nil))
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"first_name":"Ali"`)) {
+		t.Errorf("Kişiler listelenemedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/person?last_name=Veli&sort=-last_name,first_name", nil))
+	if filters := persons.filters[len(persons.filters)-1]; w.Code != http.StatusOK || filters.LastName != "Veli" || len(filters.Sort) != 2 || !filters.Sort[0].Desc {
+		t.Errorf("Filtreler depoya iletilmedi. Kod: %d, Filtreler: %+v", w.Code, filters)
+	}
+
+	// Listede olmayan alanla sıralama reddedilmeli
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/person?sort=password", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Geçersiz sıralama alanı kabul edildi. Kod: %d", w.Code)
+	}
+
+	persons.err = errors.New("depo hatası")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/person", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Depo hatası 500 dönmedi. Kod: %d", w.Code)
+	}
+}
+
+// Filtreye uyan kayıt yoksa boş liste döner
+func TestGetPersonsEmptyFilterResult(t *testing.T) {
+	r := newTestRouter(&memoryPersons{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/person?last_name=Yok", nil))
+
+	var resp struct {
+		Data  []models.Person `json:"data"`
+		Total *int            `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); w.Code != http.StatusOK || err != nil || resp.Data == nil || len(resp.Data) != 0 || resp.Total == nil || *resp.Total != 0 {
+		t.Errorf("Boş filtre sonucu 200 ve boş liste dönmedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSearchPersonsHandler(t *testing.T) {
+	r := newTestRouter(&memoryPersons{})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/person/search?q=+", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Boş arama metni kabul edildi. Kod: %d", w.Code)
+	}
+
+	// Tam metin aramasını desteklemeyen depo
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/person/search?q=ali", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("Desteklenmeyen arama 501 dönmedi. Kod: %d", w.Code)
+	}
+
+	if err := models.OpenDatabase(":memory:"); err != nil {
+		t.Fatalf("Test veritabanı açılamadı: %v", err)
+	}
+	models.DB.SetMaxOpenConns(1)
+	t.Cleanup(func() { models.DB.Close() })
+
+	persons := models.NewSQLitePersonRepository(models.DB)
+	if _, err := persons.Add(models.Person{FirstName: "Şükrü", LastName: "Yılmaz", Email: "sukru@test.com"}, models.PersonScope{UserID: 7, TenantID: 3}); err != nil {
+		t.Fatalf("Kişi eklenemedi: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	newTestRouter(persons).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/person/search?q=suk", nil))
+	if w.Code != http.StatusOK || !bytes.Contains(w.Body.Bytes(), []byte(`"total":1`)) || !strings.Contains(w.Body.String(), `\u003cmark\u003eŞükrü\u003c/mark\u003e`) {
+		t.Errorf("Arama sonucu dönmedi. Kod: %d, Yanıt: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	clientIP := func() string {
+		r := gin.New()
+		if err := r.SetTrustedProxies(trustedProxies()); err != nil {
+			t.Fatalf("Proxy listesi okunamadı: %v", err)
+		}
+		r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	// Varsayılan olarak X-Forwarded-For yok sayılır
+	t.Setenv("TRUSTED_PROXIES", "")
+	if ip := clientIP(); ip != "10.0.0.1" {
+		t.Errorf("Güvenilmeyen proxy başlığı kullanıldı: %s", ip)
+	}
+
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+	if ip := clientIP(); ip != "203.0.113.7" {
+		t.Errorf("Güvenilen proxy başlığı kullanılmadı: %s", ip)
+	}
+}
+
+func TestMigrateCommand(t *testing.T) {
+	t.Setenv("DATABASE_DSN", filepath.Join(t.TempDir(), "test.db"))
+	t.Cleanup(func() {
+		models.DB.Close()
+		models.SetAutoMigrate(true)
+	})
+
+	var out bytes.Buffer
+	if err := runMigrate([]string{"status"}, &out); err != nil || !strings.Contains(out.String(), "bekliyor") {
+		t.Fatalf("Migrasyon durumu listelenemedi: %v\n%s", err, out.String())
+	}
+
+	if err := checkPendingMigrations(); err == nil {
+		t.Error("Bekleyen migrasyonlar bildirilmedi")
+	}
+
+	out.Reset()
+	if err := runMigrate([]string{"up"}, &out); err != nil || strings.Contains(out.String(), "bekliyor") {
+		t.Fatalf("Migrasyonlar uygulanamadı: %v\n%s", err, out.String())
+	}
+
+	if err := runMigrate([]string{"-db", "sqlite", "to", "1"}, &out); err != nil || checkPendingMigrations() == nil {
+		t.Errorf("Sürüme geri dönülemedi: %v", err)
+	}
+
+	if err := runMigrate([]string{"sil"}, &out); err == nil {
+		t.Error("Bilinmeyen komut kabul edildi")
+	}
+}
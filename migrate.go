@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"example.com/webservice/models"
+)
+
+const migrateUsage = `Kullanım: go-web-service migrate [-db sqlite|postgres] <komut>
+
+Komutlar:
+  up            Bekleyen tüm migrasyonları uygular
+  down [adım]   Son uygulanan migrasyonları geri alır (varsayılan: 1)
+  to <sürüm>    Şemayı verilen sürüme getirir (0 tüm migrasyonları geri alır)
+  status        Migrasyonları ve uygulanma zamanlarını listeler
+
+PostgreSQL kullanılıyorsa (DATABASE_DSN) komut önce SQLite'a, sonra PostgreSQL'e uygulanır. İki veritabanının
+sürüm numaraları birbirinden bağımsız olduğu için "to" ve "down" -db ile tek bir veritabanına sınırlandırılabilir.`
+
+// `go-web-service migrate ...` alt komutu. Migrasyonlar otomatik uygulanmadan veritabanına bağlanır
+func runMigrate(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	target := flags.String("db", "", "")
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return errors.New(migrateUsage)
+	}
+	args = flags.Args()
+
+	switch args[0] {
+	case "up", "down", "to", "status":
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	models.SetAutoMigrate(false)
+	if err := models.ConnectDatabase(); err != nil {
+		return err
+	}
+
+	migrators, err := models.Migrators()
+	if err != nil {
+		return err
+	}
+
+	for _, migrator := range migrators {
+		if *target != "" && migrator.Name != *target {
+			continue
+		}
+
+		if err := runMigrateCommand(migrator, args, out); err != nil {
+			return fmt.Errorf("%s: %v", migrator.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func runMigrateCommand(migrator *models.Migrator, args []string, out io.Writer) error {
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(); err != nil {
+			return err
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return errors.New(migrateUsage)
+			}
+			steps = n
+		}
+
+		if err := migrator.Down(steps); err != nil {
+			return err
+		}
+
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New(migrateUsage)
+		}
+
+		if err := migrator.To(version); err != nil {
+			return err
+		}
+	}
+
+	return printMigrationStatus(migrator, out)
+}
+
+func printMigrationStatus(migrator *models.Migrator, out io.Writer) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "%s:\n", migrator.Name)
+
+	for _, status := range statuses {
+		applied := "bekliyor"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(out, "  %04d  %-40s %s\n", status.Version, status.Name, applied)
+	}
+
+	return nil
+}
+
+// Uygulanmamış migrasyon varsa hata döner, böylece servis eski şemayla çalışmaya başlamaz
+func checkPendingMigrations() error {
+	migrators, err := models.Migrators()
+	if err != nil {
+		return err
+	}
+
+	for _, migrator := range migrators {
+		pending, err := migrator.Pending()
+		if err != nil {
+			return err
+		}
+
+		if pending > 0 {
+			return fmt.Errorf("%s veritabanında %d migrasyon uygulanmamış, `go-web-service migrate up` çalıştırın", migrator.Name, pending)
+		}
+	}
+
+	return nil
+}
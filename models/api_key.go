@@ -0,0 +1,137 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrAPIKeyNotFound = errors.New("api anahtarı bulunamadı")
+
+// Makine istemcileri için kullanıcıya ait API anahtarı. Anahtarın kendisi değil yalnızca SHA-256 hash'i tutulur,
+// Prefix anahtarı listede tanıyabilmek için saklanan ilk karakterlerdir
+type APIKey struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"-"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+const apiKeyColumns = "id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at"
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (APIKey, error) {
+	var key APIKey
+	var scopes string
+	var expiresAt, lastUsedAt sql.NullInt64
+	var createdAt int64
+
+	if err := row.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &scopes, &expiresAt, &lastUsedAt, &createdAt); err != nil {
+		return APIKey{}, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	key.ExpiresAt = nullUnixTime(expiresAt)
+	key.LastUsedAt = nullUnixTime(lastUsedAt)
+	key.CreatedAt = time.Unix(createdAt, 0)
+
+	return key, nil
+}
+
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.Unix()
+}
+
+func CreateAPIKey(key APIKey, keyHash string) (int64, error) {
+	result, err := DB.Exec("INSERT INTO api_key (user_id, name, prefix, key_hash, scopes, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		key.UserID, key.Name, key.Prefix, keyHash, strings.Join(key.Scopes, " "), nullTime(key.ExpiresAt), time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func GetAPIKeys(userID int) ([]APIKey, error) {
+	rows, err := DB.Query("SELECT "+apiKeyColumns+" FROM api_key WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := make([]APIKey, 0)
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func GetAPIKeyByHash(keyHash string) (APIKey, error) {
+	key, err := scanAPIKey(DB.QueryRow("SELECT "+apiKeyColumns+" FROM api_key WHERE key_hash = ?", keyHash))
+	if err == sql.ErrNoRows {
+		return APIKey{}, ErrAPIKeyNotFound
+	}
+
+	return key, err
+}
+
+// Anahtarın adını, yetki kapsamlarını ve son kullanma tarihini günceller
+func UpdateAPIKey(key APIKey) error {
+	result, err := DB.Exec("UPDATE api_key SET name = ?, scopes = ?, expires_at = ? WHERE id = ? AND user_id = ?",
+		key.Name, strings.Join(key.Scopes, " "), nullTime(key.ExpiresAt), key.ID, key.UserID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, ErrAPIKeyNotFound)
+}
+
+func DeleteAPIKey(id, userID int) error {
+	result, err := DB.Exec("DELETE FROM api_key WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, ErrAPIKeyNotFound)
+}
+
+// Kullanıcının tüm API anahtarlarını siler
+func DeleteUserAPIKeys(userID int) error {
+	_, err := DB.Exec("DELETE FROM api_key WHERE user_id = ?", userID)
+	return err
+}
+
+// Son kullanım zamanını günceller. Her istekte yazmamak için kayıt en fazla dakikada bir güncellenir
+func TouchAPIKey(id int) error {
+	now := time.Now().Unix()
+	_, err := DB.Exec("UPDATE api_key SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)", now, id, now-60)
+	return err
+}
+
+func expectOneRow(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return notFound
+	}
+
+	return nil
+}
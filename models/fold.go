@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	"modernc.org/sqlite"
+)
+
+// Büyük/küçük harf duyarsız karşılaştırmalarda kullanılan harf eşlemesi. SQLite'ın lower() fonksiyonu sadece ASCII harfleri,
+// PostgreSQL'inki ise veritabanının locale'ine göre çevirir; bu yüzden iki veritabanında da bu tabloyu kullanan fold()
+// fonksiyonu kullanılır. SQLite'ta fonksiyon Go ile kaydedilir, PostgreSQL'de aynı tabloyla migrasyonda oluşturulur
+// (migrations/postgres/0002_create_fold_function). Türkçe ı ve İ, i ile aynı kabul edilir. Tablo değişirse PostgreSQL
+// fonksiyonu yeni bir migrasyonla güncellenmelidir
+const (
+	foldFrom = "ABCDEFGHIJKLMNOPQRSTUVWXYZÇĞİÖŞÜÂÎÛı"
+	foldTo   = "abcdefghijklmnopqrstuvwxyzçğiöşüâîûi"
+)
+
+var foldRunes = func() map[rune]rune {
+	from, to := []rune(foldFrom), []rune(foldTo)
+
+	runes := make(map[rune]rune, len(from))
+	for i, r := range from {
+		runes[r] = to[i]
+	}
+
+	return runes
+}()
+
+// Metni fold() ile aynı şekilde küçük harfe çevirir
+func foldText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if folded, ok := foldRunes[r]; ok {
+			return folded
+		}
+		return r
+	}, s)
+}
+
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("fold", 1, func(_ *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch value := args[0].(type) {
+		case string:
+			return foldText(value), nil
+		case []byte:
+			return foldText(string(value)), nil
+		default:
+			return value, nil
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+)
+
+var ErrGroupNotFound = errors.New("grup bulunamadı")
+
+type Group struct {
+	ID       int    `json:"id" swaggerignore:"true"`
+	Name     string `json:"name"`
+	TenantID int    `json:"tenant_id" swaggerignore:"true"`
+	Members  []int  `json:"members" swaggerignore:"true"`
+}
+
+func GetGroups(tenantID int) ([]Group, error) {
+	rows, err := DB.Query("SELECT g.id, g.name, m.user_id FROM user_group g LEFT JOIN user_group_member m ON m.group_id = g.id WHERE g.tenant_id = ? ORDER BY g.id, m.user_id", tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	groups := make([]Group, 0)
+
+	for rows.Next() {
+		var id int
+		var name string
+		var userID *int
+		if err := rows.Scan(&id, &name, &userID); err != nil {
+			return nil, err
+		}
+
+		if len(groups) == 0 || groups[len(groups)-1].ID != id {
+			groups = append(groups, Group{ID: id, Name: name, TenantID: tenantID, Members: []int{}})
+		}
+
+		if userID != nil {
+			last := &groups[len(groups)-1]
+			last.Members = append(last.Members, *userID)
+		}
+	}
+
+	return groups, rows.Err()
+}
+
+func CreateGroup(name string, tenantID int) (int64, error) {
+	result, err := DB.Exec("INSERT INTO user_group (name, tenant_id) VALUES (?, ?)", name, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// Grubu, üyeliklerini ve grupla yapılan paylaşımları siler
+func DeleteGroup(groupID, tenantID int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("DELETE FROM user_group WHERE id = ? AND tenant_id = ?", groupID, tenantID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return ErrGroupNotFound
+	}
+
+	for _, stmt := range []string{"DELETE FROM user_group_member WHERE group_id = ?", "DELETE FROM person_share WHERE group_id = ?"} {
+		if _, err := tx.Exec(stmt, groupID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func GroupExists(groupID, tenantID int) (bool, error) {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM user_group WHERE id = ? AND tenant_id = ?", groupID, tenantID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func AddGroupMember(groupID, userID, tenantID int) error {
+	exists, err := GroupExists(groupID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return ErrGroupNotFound
+	}
+
+	_, err = DB.Exec("INSERT OR IGNORE INTO user_group_member (group_id, user_id) VALUES (?, ?)", groupID, userID)
+	return err
+}
+
+func RemoveGroupMember(groupID, userID, tenantID int) error {
+	_, err := DB.Exec("DELETE FROM user_group_member WHERE group_id = ? AND user_id = ? AND group_id IN (SELECT id FROM user_group WHERE tenant_id = ?)", groupID, userID, tenantID)
+	return err
+}
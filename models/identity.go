@@ -0,0 +1,108 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrIdentityNotFound = errors.New("harici kimlik bulunamadı")
+	ErrIdentityLinked   = errors.New("harici kimlik başka bir kullanıcıya bağlı")
+)
+
+// Harici kimlik sağlayıcıdaki (OIDC issuer + subject) kimliğe bağlı kullanıcıyı döner
+func GetUserByIdentity(issuer, subject string) (User, error) {
+	var userID int
+	err := DB.QueryRow("SELECT user_id FROM user_identity WHERE issuer = ? AND subject = ?", issuer, subject).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return User{}, ErrIdentityNotFound
+		}
+		return User{}, err
+	}
+
+	user, err := Users().GetByID(userID, AllTenants)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return User{}, ErrIdentityNotFound
+		}
+		return User{}, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// Mevcut bir yerel kullanıcıyı harici kimliğe bağlar
+func LinkIdentity(userID int, issuer, subject string) error {
+	existing, err := GetUserByIdentity(issuer, subject)
+	if err == nil {
+		if existing.ID == userID {
+			return nil
+		}
+		return ErrIdentityLinked
+	}
+
+	if err != ErrIdentityNotFound {
+		return err
+	}
+
+	_, err = DB.Exec("INSERT INTO user_identity (user_id, issuer, subject, created_at) VALUES (?, ?, ?, ?)", userID, issuer, subject, time.Now().Unix())
+	return err
+}
+
+// Harici kimlikle ilk kez giriş yapan kullanıcıyı oluşturur ve kimliğe bağlar. Kullanıcı adı alınmışsa sonuna sayı eklenir.
+// Password rastgele ve kullanıcıya bildirilmeyen bir değer olmalıdır, böylece hesaba sadece kimlik sağlayıcı üzerinden girilebilir
+func ProvisionExternalUser(user User, issuer, subject string) (User, error) {
+	if user.TenantID == AllTenants {
+		return User{}, errors.New("kullanıcı için tenant belirtilmedi")
+	}
+
+	hashedPassword, err := HashPassword(user.Password)
+	if err != nil {
+		return User{}, err
+	}
+
+	users := Users()
+
+	base := strings.TrimSpace(user.Username)
+	for i := 1; ; i++ {
+		candidate := base
+		if i > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, i)
+		}
+
+		exists, err := users.UsernameExists(candidate)
+		if err != nil {
+			return User{}, err
+		}
+
+		if !exists {
+			user.Username = candidate
+			break
+		}
+	}
+
+	id, err := users.Insert(user, hashedPassword, true)
+	if err != nil {
+		return User{}, err
+	}
+
+	if _, err := DB.Exec("INSERT INTO user_identity (user_id, issuer, subject, created_at) VALUES (?, ?, ?, ?)", id, issuer, subject, time.Now().Unix()); err != nil {
+		// Kimliğe bağlanamayan kullanıcı geri alınır
+		users.Delete(int(id), user.TenantID)
+		return User{}, err
+	}
+
+	user.ID = int(id)
+	user.Password = ""
+	return user, nil
+}
+
+// Kimlik sağlayıcıdan gelen rol eşlemesine göre kullanıcının rolünü günceller
+func SetUserRole(userID int, role string) error {
+	return Users().SetRole(userID, role)
+}
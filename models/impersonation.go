@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// Admin'in başka bir kullanıcı adına yaptığı bir istek. Taklit başlatma isteği de (Reason ile) kaydedilir
+type ImpersonationAudit struct {
+	ID        int       `json:"id"`
+	AdminID   int       `json:"admin_id"`
+	UserID    int       `json:"user_id"`
+	TokenID   string    `json:"token_id"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	IPAddress string    `json:"ip_address"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Kaydı ekleyip ID'sini döner. İstek işlendikten sonra durum kodu SetImpersonationAuditStatus ile güncellenir
+func AddImpersonationAudit(entry ImpersonationAudit) (int64, error) {
+	result, err := DB.Exec("INSERT INTO impersonation_audit (admin_id, user_id, token_id, method, path, status, ip_address, reason, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.AdminID, entry.UserID, entry.TokenID, entry.Method, entry.Path, entry.Status, entry.IPAddress, entry.Reason, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func SetImpersonationAuditStatus(id int64, status int) error {
+	_, err := DB.Exec("UPDATE impersonation_audit SET status = ? WHERE id = ?", status, id)
+	return err
+}
+
+// Kayıtları yeniden eskiye döner. userID veya adminID 0 ise o alana göre filtrelenmez
+func GetImpersonationAudit(userID, adminID, limit, offset int) ([]ImpersonationAudit, error) {
+	rows, err := DB.Query(`SELECT id, admin_id, user_id, token_id, method, path, status, ip_address, reason, created_at FROM impersonation_audit
+		WHERE (? = 0 OR user_id = ?) AND (? = 0 OR admin_id = ?) ORDER BY id DESC LIMIT ? OFFSET ?`,
+		userID, userID, adminID, adminID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := make([]ImpersonationAudit, 0)
+
+	for rows.Next() {
+		var entry ImpersonationAudit
+		var createdAt int64
+		if err := rows.Scan(&entry.ID, &entry.AdminID, &entry.UserID, &entry.TokenID, &entry.Method, &entry.Path, &entry.Status, &entry.IPAddress, &entry.Reason, &createdAt); err != nil {
+			return nil, err
+		}
+
+		entry.CreatedAt = time.Unix(createdAt, 0)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
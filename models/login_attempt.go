@@ -0,0 +1,74 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Başarısız giriş denemeleri. Key "user:<kullanıcı adı>" veya "ip:<adres>" biçimindedir
+type LoginAttempt struct {
+	Key         string
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Kayıt yoksa sıfır değerli bir LoginAttempt döner
+func GetLoginAttempt(key string) (LoginAttempt, error) {
+	attempt := LoginAttempt{Key: key}
+
+	var lastFailure, lockedUntil int64
+	err := DB.QueryRow("SELECT failures, last_failure, locked_until FROM login_attempt WHERE key = ?", key).
+		Scan(&attempt.Failures, &lastFailure, &lockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return attempt, nil
+		}
+		return attempt, err
+	}
+
+	attempt.LastFailure = time.Unix(lastFailure, 0)
+	attempt.LockedUntil = time.Unix(lockedUntil, 0)
+
+	return attempt, nil
+}
+
+// Anahtarın sayacını tek bir sorguyla artırır ve yeni değerini döner, böylece eş zamanlı istekler birbirinin artırmasını
+// ezmez. Sayaç son artırmadan window süre sonra sıfırdan başlar
+func IncrementLoginAttempt(key string, window time.Duration) (int, error) {
+	now := time.Now()
+
+	var failures int
+	err := DB.QueryRow(`INSERT INTO login_attempt (key, failures, last_failure, locked_until) VALUES (?, 1, ?, 0)
+		ON CONFLICT(key) DO UPDATE SET failures = CASE WHEN last_failure < ? THEN 1 ELSE failures + 1 END, last_failure = excluded.last_failure
+		RETURNING failures`, key, now.Unix(), now.Add(-window).Unix()).Scan(&failures)
+	if err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+// Anahtarı verilen zamana kadar kilitler. Daha uzun süreli bir kilit varsa kısaltılmaz
+func LockLoginAttempt(key string, until time.Time) error {
+	_, err := DB.Exec(`INSERT INTO login_attempt (key, failures, last_failure, locked_until) VALUES (?, 0, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET locked_until = MAX(locked_until, excluded.locked_until)`, key, time.Now().Unix(), until.Unix())
+	return err
+}
+
+// Son denemesi before'dan önce olan ve kilidi bitmiş kayıtları siler (sayaçları zaten sıfırlanmış sayılır)
+func DeleteExpiredLoginAttempts(before time.Time) error {
+	_, err := DB.Exec("DELETE FROM login_attempt WHERE last_failure < ? AND locked_until < ?", before.Unix(), time.Now().Unix())
+	return err
+}
+
+// Verilen anahtarların başarısız deneme kayıtlarını ve kilitlerini siler
+func ClearLoginAttempts(keys ...string) error {
+	for _, key := range keys {
+		if _, err := DB.Exec("DELETE FROM login_attempt WHERE key = ?", key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
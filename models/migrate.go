@@ -0,0 +1,361 @@
+package models
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Numaralı SQL migrasyonları. Her migrasyon <sürüm>_<ad>.up.sql ve <sürüm>_<ad>.down.sql dosyalarından oluşur
+// ve kendi transaction'ında uygulanır. Uygulanan sürümler veritabanındaki schema_migrations tablosunda tutulur
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// Bir veritabanının migrasyonlarını yönetir
+type Migrator struct {
+	// "sqlite" veya "postgres"
+	Name       string
+	db         *sql.DB
+	migrations []Migration
+	// Sorgudaki ? parametrelerini veritabanının biçimine çevirir
+	bind func(string) string
+	// Migrasyon kaydı olmayan veritabanında migrasyonlardan önce çalışır
+	legacy func(*sql.DB) error
+}
+
+// Migrasyonların başlangıçta otomatik uygulanıp uygulanmayacağı
+var autoMigrate = true
+
+func SetAutoMigrate(enabled bool) {
+	autoMigrate = enabled
+}
+
+func newSQLiteMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations("migrations/sqlite")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{Name: "sqlite", db: db, migrations: migrations, bind: func(q string) string { return q }, legacy: upgradeLegacySchema}, nil
+}
+
+func newPostgresMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations("migrations/postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{Name: "postgres", db: db, migrations: migrations, bind: postgresPlaceholders}, nil
+}
+
+// Kullanılan veritabanlarının migrator'ları. PostgreSQL kullanılıyorsa SQLite'tan sonra gelir
+func Migrators() ([]*Migrator, error) {
+	sqliteMigrator, err := newSQLiteMigrator(DB)
+	if err != nil {
+		return nil, err
+	}
+
+	migrators := []*Migrator{sqliteMigrator}
+
+	if postgresDB != nil {
+		postgresMigrator, err := newPostgresMigrator(postgresDB)
+		if err != nil {
+			return nil, err
+		}
+		migrators = append(migrators, postgresMigrator)
+	}
+
+	return migrators, nil
+}
+
+// ? parametrelerini $1, $2... biçimine çevirir
+func postgresPlaceholders(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func loadMigrations(dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("geçersiz migrasyon dosyası: %s", name)
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		number, label, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("geçersiz migrasyon dosyası: %s", name)
+		}
+
+		content, err := fs.ReadFile(migrationFiles, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("%d sürümlü birden fazla migrasyon var", version)
+		}
+
+		if direction == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("%d sürümlü migrasyonun up veya down dosyası eksik", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Bilinen en yüksek sürüm
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at BIGINT NOT NULL
+	)`)
+	return err
+}
+
+// Uygulanmış sürümler ve uygulanma zamanları
+func (m *Migrator) applied() (map[int]MigrationStatus, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.Query("SELECT version, name, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	applied := make(map[int]MigrationStatus)
+
+	for rows.Next() {
+		var status MigrationStatus
+		var appliedAt int64
+		if err := rows.Scan(&status.Version, &status.Name, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		at := time.Unix(appliedAt, 0)
+		status.AppliedAt = &at
+		applied[status.Version] = status
+	}
+
+	return applied, rows.Err()
+}
+
+// Bilinen tüm migrasyonlar ve veritabanında olup bu sürümde bulunmayan (daha yeni bir sürümün uyguladığı) migrasyonlar
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if a, ok := applied[migration.Version]; ok {
+			status.AppliedAt = a.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+
+	for _, unknown := range applied {
+		statuses = append(statuses, unknown)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Uygulanmamış migrasyon sayısı
+func (m *Migrator) Pending() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+// Bekleyen tüm migrasyonları uygular
+func (m *Migrator) Up() error {
+	return m.To(m.Latest())
+}
+
+// Son uygulanan steps adet migrasyonu geri alır
+func (m *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("geçersiz adım sayısı: %d", steps)
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	if steps >= len(versions) {
+		return m.To(0)
+	}
+
+	return m.To(versions[steps])
+}
+
+// Şemayı verilen sürüme getirir: sürüme kadar olan bekleyen migrasyonlar sırayla uygulanır, sonrakiler tersten geri alınır
+func (m *Migrator) To(version int) error {
+	if version < 0 || version > m.Latest() {
+		return fmt.Errorf("bilinmeyen migrasyon sürümü: %d", version)
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	for v := range applied {
+		if v > version && m.find(v) == nil {
+			return fmt.Errorf("%d sürümlü migrasyon bu sürümde yok, geri alınamaz", v)
+		}
+	}
+
+	if len(applied) == 0 && m.legacy != nil {
+		if err := m.legacy(m.db); err != nil {
+			return err
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+			if err := m.apply(migration, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; ok && migration.Version > version {
+			if err := m.apply(migration, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(migration Migration, up bool) error {
+	script, record, args := migration.up, "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		[]interface{}{migration.Version, migration.Name, time.Now().Unix()}
+	if !up {
+		script, record, args = migration.down, "DELETE FROM schema_migrations WHERE version = ?", []interface{}{migration.Version}
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(script); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("%04d_%s migrasyonu uygulanamadı: %v", migration.Version, migration.Name, err)
+	}
+
+	if _, err := tx.Exec(m.bind(record), args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,118 @@
+package models_test
+
+import (
+	"testing"
+
+	"example.com/webservice/models"
+)
+
+func sqliteMigrator(t *testing.T) *models.Migrator {
+	t.Helper()
+
+	migrators, err := models.Migrators()
+	if err != nil {
+		t.Fatalf("Migrasyonlar yüklenemedi: %v", err)
+	}
+
+	return migrators[0]
+}
+
+func tableExists(t *testing.T, name string) bool {
+	t.Helper()
+
+	var count int
+	if err := models.DB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count); err != nil {
+		t.Fatalf("Tablo sorgulanamadı: %v", err)
+	}
+
+	return count > 0
+}
+
+func TestMigrateUpDownAndTo(t *testing.T) {
+	openTestDB(t)
+	migrator := sqliteMigrator(t)
+
+	if pending, err := migrator.Pending(); err != nil || pending != 0 {
+		t.Fatalf("Açılışta migrasyonlar uygulanmadı: %d, %v", pending, err)
+	}
+
+	if err := migrator.To(2); err != nil || tableExists(t, "people_fts") || !tableExists(t, "tenant") {
+		t.Fatalf("Migrasyonlar geri alınamadı: %v", err)
+	}
+
+	if err := migrator.Down(1); err != nil {
+		t.Fatalf("Migrasyon geri alınamadı: %v", err)
+	}
+
+	if tableExists(t, "tenant") || !tableExists(t, "people") {
+		t.Error("Son migrasyon doğru geri alınmadı")
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("Migrasyon durumu alınamadı: %v", err)
+	}
+	if last := statuses[len(statuses)-1]; last.AppliedAt != nil || statuses[0].AppliedAt == nil {
+		t.Errorf("Beklenmeyen migrasyon durumu: %+v", statuses)
+	}
+
+	if err := migrator.To(0); err != nil || tableExists(t, "people") || tableExists(t, "user") {
+		t.Fatalf("Tüm migrasyonlar geri alınamadı: %v", err)
+	}
+
+	if err := migrator.To(migrator.Latest() + 1); err == nil {
+		t.Error("Bilinmeyen sürüme geçilebildi")
+	}
+
+	if err := migrator.Up(); err != nil || !tableExists(t, "tenant") || !tableExists(t, "people_fts") {
+		t.Fatalf("Migrasyonlar tekrar uygulanamadı: %v", err)
+	}
+}
+
+func TestMigrateUpgradesLegacyDatabase(t *testing.T) {
+	// Depodaki database.db gibi migrasyonlardan önce oluşturulmuş bir veritabanı
+	models.SetAutoMigrate(false)
+	t.Cleanup(func() { models.SetAutoMigrate(true) })
+	openTestDB(t)
+
+	for _, stmt := range []string{
+		`CREATE TABLE "people" ("id" INTEGER, "first_name" TEXT, "last_name" TEXT, "email" TEXT, "ip_address" TEXT, PRIMARY KEY("id" AUTOINCREMENT))`,
+		`CREATE TABLE "user" ("id" INTEGER NOT NULL UNIQUE, "username" TEXT UNIQUE, "email" TEXT, "password" TEXT NOT NULL, "role" TEXT NOT NULL DEFAULT 'user', PRIMARY KEY("id"))`,
+		`CREATE TABLE sqlb_temp_table_1 (id INTEGER)`,
+		`INSERT INTO people (first_name, last_name, email, ip_address) VALUES ('Ali', 'Veli', 'ali@test.com', '127.0.0.1')`,
+		`INSERT INTO user (id, username, email, password) VALUES (1, 'eski', 'eski@test.com', 'gizli')`,
+	} {
+		if _, err := models.DB.Exec(stmt); err != nil {
+			t.Fatalf("Eski şema oluşturulamadı: %v", err)
+		}
+	}
+
+	if err := sqliteMigrator(t).Up(); err != nil {
+		t.Fatalf("Migrasyonlar uygulanamadı: %v", err)
+	}
+
+	if tableExists(t, "sqlb_temp_table_1") {
+		t.Error("Geçici tablo silinmedi")
+	}
+
+	// Eski kayıtlar varsayılan tenant'a ait olmalı
+	persons, err := models.GetPersons(10, 0, models.PersonScope{TenantID: models.DefaultTenantID, Admin: true}, models.PersonQuery{})
+	if err != nil || len(persons) != 1 {
+		t.Errorf("Eski kişiler alınamadı: %+v, %v", persons, err)
+	}
+
+	// user tablosu AUTOINCREMENT ile yeniden oluşturulur; eski kullanıcılar korunur, silinen son kullanıcının id'si tekrar verilmez
+	if user, err := models.GetUserByUsername("eski"); err != nil || user.ID != 1 || user.TenantID != models.DefaultTenantID {
+		t.Errorf("Eski kullanıcı korunmadı: %+v, %v", user, err)
+	}
+	if _, err := models.DB.Exec("INSERT INTO user (id, username, password) VALUES (2, 'ara', 'x')"); err != nil {
+		t.Fatalf("Kullanıcı eklenemedi: %v", err)
+	}
+	if _, err := models.DB.Exec("DELETE FROM user WHERE id = 2"); err != nil {
+		t.Fatalf("Kullanıcı silinemedi: %v", err)
+	}
+	var id int
+	if err := models.DB.QueryRow("INSERT INTO user (username, password) VALUES ('yeni', 'x') RETURNING id").Scan(&id); err != nil || id != 3 {
+		t.Errorf("Silinen kullanıcının id'si tekrar verildi: %d, %v", id, err)
+	}
+}
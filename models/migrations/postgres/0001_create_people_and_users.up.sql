@@ -0,0 +1,27 @@
+-- "user" PostgreSQL'de ayrılmış bir kelime olduğu için kullanıcı tablosunun adı users'dır
+
+CREATE TABLE IF NOT EXISTS people (
+	id SERIAL PRIMARY KEY,
+	first_name TEXT NOT NULL DEFAULT '',
+	last_name TEXT NOT NULL DEFAULT '',
+	email TEXT NOT NULL DEFAULT '',
+	ip_address TEXT NOT NULL DEFAULT '',
+	owner_id INTEGER,
+	tenant_id INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_people_tenant ON people (tenant_id);
+
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	username TEXT NOT NULL UNIQUE,
+	email TEXT NOT NULL DEFAULT '',
+	password TEXT NOT NULL,
+	role TEXT NOT NULL DEFAULT 'user',
+	tenant_id INTEGER NOT NULL DEFAULT 1,
+	email_verified BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_tenant ON users (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_email ON users (lower(email));
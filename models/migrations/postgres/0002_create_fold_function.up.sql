@@ -0,0 +1,5 @@
+-- Büyük/küçük harf duyarsız filtreler için locale'den bağımsız küçük harfe çevirme. Eşleme models/fold.go'daki tabloyla aynıdır
+
+CREATE OR REPLACE FUNCTION fold(value TEXT) RETURNS TEXT AS $$
+	SELECT translate(value, 'ABCDEFGHIJKLMNOPQRSTUVWXYZÇĞİÖŞÜÂÎÛı', 'abcdefghijklmnopqrstuvwxyzçğiöşüâîûi')
+$$ LANGUAGE SQL IMMUTABLE STRICT PARALLEL SAFE;
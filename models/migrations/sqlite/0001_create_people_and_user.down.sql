@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user;
+DROP TABLE IF EXISTS people;
@@ -0,0 +1,26 @@
+-- Migrasyonlardan önce oluşturulmuş veritabanlarında (örn. depodaki database.db) bu tablolar zaten mevcuttur
+
+CREATE TABLE IF NOT EXISTS people (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	first_name TEXT,
+	last_name TEXT,
+	email TEXT,
+	ip_address TEXT,
+	owner_id INTEGER,
+	tenant_id INTEGER NOT NULL DEFAULT 1
+);
+
+-- AUTOINCREMENT ile silinen kullanıcıların id'leri yeni kullanıcılara verilmez
+CREATE TABLE IF NOT EXISTS user (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	username TEXT UNIQUE,
+	email TEXT,
+	password TEXT NOT NULL,
+	role TEXT NOT NULL DEFAULT 'user',
+	tenant_id INTEGER NOT NULL DEFAULT 1,
+	email_verified INTEGER NOT NULL DEFAULT 1,
+	created_at INTEGER NOT NULL DEFAULT 0
+);
+
+-- Tablo düzenleyicisinde yarım kalan bir değişiklikten geriye kalan geçici tablo
+DROP TABLE IF EXISTS sqlb_temp_table_1;
@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS partner_nonce;
+DROP TABLE IF EXISTS partner;
+DROP TABLE IF EXISTS impersonation_audit;
+DROP TABLE IF EXISTS user_session;
+DROP TABLE IF EXISTS password_history;
+DROP TABLE IF EXISTS password_reset;
+DROP TABLE IF EXISTS oidc_state;
+DROP TABLE IF EXISTS user_identity;
+DROP TABLE IF EXISTS oauth_code;
+DROP TABLE IF EXISTS oauth_client;
+DROP TABLE IF EXISTS api_key;
+DROP TABLE IF EXISTS login_attempt;
+DROP TABLE IF EXISTS user_recovery_code;
+DROP TABLE IF EXISTS user_totp;
+DROP TABLE IF EXISTS person_share;
+DROP TABLE IF EXISTS user_group_member;
+DROP TABLE IF EXISTS user_group;
+DROP TABLE IF EXISTS tenant;
+DROP TABLE IF EXISTS policy_default;
+DROP TABLE IF EXISTS role_permission;
+DROP TABLE IF EXISTS role;
+DROP TABLE IF EXISTS user_session_version;
+DROP TABLE IF EXISTS revoked_token;
+DROP TABLE IF EXISTS refresh_token;
@@ -0,0 +1,236 @@
+-- Kimlik doğrulama, tenant, grup, paylaşım ve oturum tabloları
+
+CREATE TABLE IF NOT EXISTS refresh_token (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	token_hash TEXT NOT NULL UNIQUE,
+	user_id INTEGER NOT NULL,
+	family_id TEXT NOT NULL,
+	expires_at INTEGER NOT NULL,
+	created_at INTEGER NOT NULL,
+	used_at INTEGER,
+	revoked_at INTEGER,
+	scopes TEXT NOT NULL DEFAULT '',
+	client_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_family ON refresh_token (family_id);
+
+-- Instance'lar iptal önbelleklerini son eşitlemeden sonraki değişikliklerle günceller; değişiklikler zamana göre aranır
+CREATE TABLE IF NOT EXISTS revoked_token (
+	jti TEXT PRIMARY KEY,
+	expires_at INTEGER NOT NULL,
+	revoked_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_token_revoked ON revoked_token (revoked_at);
+
+CREATE TABLE IF NOT EXISTS user_session_version (
+	user_id INTEGER PRIMARY KEY,
+	version INTEGER NOT NULL DEFAULT 0,
+	updated_at INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_session_version_updated ON user_session_version (updated_at);
+
+CREATE TABLE IF NOT EXISTS role (
+	name TEXT PRIMARY KEY,
+	description TEXT NOT NULL DEFAULT '',
+	require_2fa INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS role_permission (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	role TEXT NOT NULL,
+	route TEXT NOT NULL,
+	method TEXT NOT NULL,
+	condition TEXT NOT NULL DEFAULT '',
+	UNIQUE (role, route, method, condition)
+);
+
+-- Veritabanına daha önce eklenmiş varsayılan yetkiler. Yeni sürümlerde varsayılan politikaya eklenen yetkiler başlangıçta
+-- role_permission'a eklenir; burada kaydı olan yetkiler admin tarafından silinmiş olsa bile tekrar eklenmez
+CREATE TABLE IF NOT EXISTS policy_default (
+	role TEXT NOT NULL,
+	route TEXT NOT NULL,
+	method TEXT NOT NULL,
+	condition TEXT NOT NULL DEFAULT '',
+	PRIMARY KEY (role, route, method, condition)
+);
+
+CREATE TABLE IF NOT EXISTS tenant (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	created_at INTEGER NOT NULL DEFAULT (strftime('%s', 'now'))
+);
+
+INSERT OR IGNORE INTO tenant (id, name) VALUES (1, 'default');
+
+CREATE TABLE IF NOT EXISTS user_group (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	tenant_id INTEGER NOT NULL DEFAULT 1,
+	UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS user_group_member (
+	group_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	PRIMARY KEY (group_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS person_share (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	person_id INTEGER NOT NULL,
+	user_id INTEGER,
+	group_id INTEGER,
+	can_edit INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_person_share_person ON person_share (person_id);
+
+CREATE TABLE IF NOT EXISTS user_totp (
+	user_id INTEGER PRIMARY KEY,
+	secret TEXT NOT NULL,
+	enabled INTEGER NOT NULL DEFAULT 0,
+	last_used_step INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS user_recovery_code (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	code_hash TEXT NOT NULL,
+	used_at INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS login_attempt (
+	key TEXT PRIMARY KEY,
+	failures INTEGER NOT NULL DEFAULT 0,
+	last_failure INTEGER NOT NULL,
+	locked_until INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS api_key (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	prefix TEXT NOT NULL,
+	key_hash TEXT NOT NULL UNIQUE,
+	scopes TEXT NOT NULL DEFAULT '',
+	expires_at INTEGER,
+	last_used_at INTEGER,
+	created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS oauth_client (
+	id TEXT PRIMARY KEY,
+	secret_hash TEXT NOT NULL DEFAULT '',
+	name TEXT NOT NULL,
+	redirect_uris TEXT NOT NULL DEFAULT '',
+	scopes TEXT NOT NULL DEFAULT '',
+	user_id INTEGER,
+	created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS oauth_code (
+	code_hash TEXT PRIMARY KEY,
+	client_id TEXT NOT NULL,
+	user_id INTEGER NOT NULL,
+	redirect_uri TEXT NOT NULL,
+	scopes TEXT NOT NULL DEFAULT '',
+	code_challenge TEXT NOT NULL,
+	expires_at INTEGER NOT NULL,
+	used_at INTEGER,
+	family_id TEXT
+);
+
+CREATE TABLE IF NOT EXISTS user_identity (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	issuer TEXT NOT NULL,
+	subject TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	UNIQUE (issuer, subject)
+);
+
+-- Kimlik sağlayıcıya yönlendirilen ve callback'te doğrulanacak OIDC giriş istekleri. Veritabanında tutulduğu için
+-- giriş başka bir instance'ta tamamlanabilir. State'in sadece hash'i saklanır
+CREATE TABLE IF NOT EXISTS oidc_state (
+	state_hash TEXT PRIMARY KEY,
+	nonce TEXT NOT NULL,
+	verifier TEXT NOT NULL,
+	link_user_id INTEGER NOT NULL DEFAULT 0,
+	expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_state_expires ON oidc_state (expires_at);
+
+CREATE TABLE IF NOT EXISTS password_reset (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	token_hash TEXT NOT NULL UNIQUE,
+	user_id INTEGER NOT NULL,
+	expires_at INTEGER NOT NULL,
+	created_at INTEGER NOT NULL,
+	used_at INTEGER
+);
+
+CREATE TABLE IF NOT EXISTS password_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	password_hash TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history (user_id);
+
+-- OAuth istemcisine verilen yetkiler de kullanıcının oturumları arasında listelenir ve iptal edilebilir.
+-- client_id boşsa oturum kullanıcının kendi girişidir
+CREATE TABLE IF NOT EXISTS user_session (
+	id TEXT PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	user_agent TEXT NOT NULL DEFAULT '',
+	ip_address TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+	last_seen INTEGER NOT NULL,
+	expires_at INTEGER NOT NULL,
+	revoked_at INTEGER,
+	client_id TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_session_user ON user_session (user_id);
+CREATE INDEX IF NOT EXISTS idx_user_session_client ON user_session (client_id);
+CREATE INDEX IF NOT EXISTS idx_user_session_revoked ON user_session (revoked_at);
+
+CREATE TABLE IF NOT EXISTS impersonation_audit (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	admin_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	token_id TEXT NOT NULL,
+	method TEXT NOT NULL,
+	path TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	ip_address TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS partner (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	user_id INTEGER NOT NULL,
+	secret TEXT NOT NULL,
+	previous_secret TEXT NOT NULL DEFAULT '',
+	previous_expires_at INTEGER,
+	scopes TEXT NOT NULL DEFAULT '',
+	last_used_at INTEGER,
+	created_at INTEGER NOT NULL
+);
+
+-- İmzalı partner isteklerinin nonce'ları; kayıtlar isteğin zaman damgası kabul aralığından çıkınca silinir
+CREATE TABLE IF NOT EXISTS partner_nonce (
+	partner_id TEXT NOT NULL,
+	nonce TEXT NOT NULL,
+	expires_at INTEGER NOT NULL,
+	PRIMARY KEY (partner_id, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_partner_nonce_expires ON partner_nonce (expires_at);
@@ -0,0 +1,42 @@
+-- Kişi araması için FTS5 dizini. unicode61 tokenizer'ı büyük/küçük harf ve aksan farklarını yok sayar (Şükrü = sukru).
+-- Aksan sayılmayan ı/İ harfleri folded (ad ve soyad) ve folded_email kolonlarında i/I olarak ayrıca dizinlenir
+-- (Yılmaz = yilmaz); kolonlar sadece değer bu harflerden birini içeriyorsa doldurulur. E-posta ayrı kolonda tutulduğu için
+-- e-posta eşleşmeleri ad eşleşmelerinden düşük ağırlıkla puanlanabilir. Dizin people tablosundaki tetikleyicilerle güncel tutulur
+
+CREATE VIRTUAL TABLE IF NOT EXISTS people_fts USING fts5(
+	first_name,
+	last_name,
+	email,
+	folded,
+	folded_email,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS people_fts_insert AFTER INSERT ON people BEGIN
+	INSERT INTO people_fts (rowid, first_name, last_name, email, folded, folded_email)
+	VALUES (new.id, new.first_name, new.last_name, new.email, nullif(
+		replace(replace(coalesce(new.first_name, '') || ' ' || coalesce(new.last_name, ''), 'ı', 'i'), 'İ', 'I'),
+		coalesce(new.first_name, '') || ' ' || coalesce(new.last_name, '')),
+		nullif(replace(replace(new.email, 'ı', 'i'), 'İ', 'I'), new.email));
+END;
+
+CREATE TRIGGER IF NOT EXISTS people_fts_update AFTER UPDATE OF first_name, last_name, email ON people BEGIN
+	DELETE FROM people_fts WHERE rowid = old.id;
+	INSERT INTO people_fts (rowid, first_name, last_name, email, folded, folded_email)
+	VALUES (new.id, new.first_name, new.last_name, new.email, nullif(
+		replace(replace(coalesce(new.first_name, '') || ' ' || coalesce(new.last_name, ''), 'ı', 'i'), 'İ', 'I'),
+		coalesce(new.first_name, '') || ' ' || coalesce(new.last_name, '')),
+		nullif(replace(replace(new.email, 'ı', 'i'), 'İ', 'I'), new.email));
+END;
+
+CREATE TRIGGER IF NOT EXISTS people_fts_delete AFTER DELETE ON people BEGIN
+	DELETE FROM people_fts WHERE rowid = old.id;
+END;
+
+-- Mevcut kişiler
+INSERT INTO people_fts (rowid, first_name, last_name, email, folded, folded_email)
+SELECT id, first_name, last_name, email, nullif(
+	replace(replace(coalesce(first_name, '') || ' ' || coalesce(last_name, ''), 'ı', 'i'), 'İ', 'I'),
+	coalesce(first_name, '') || ' ' || coalesce(last_name, '')),
+	nullif(replace(replace(email, 'ı', 'i'), 'İ', 'I'), email)
+FROM people;
@@ -2,23 +2,79 @@ package models
 
 import (
 	"database/sql"
-	"fmt"
-
-	"errors"
+	"log"
+	"os"
 
 	_ "modernc.org/sqlite"
 )
 
 var DB *sql.DB
 
+// Kişi ve kullanıcı depoları. Boşsa global DB üzerindeki SQLite depoları kullanılır
+var (
+	personStore PersonRepository
+	userStore   UserRepository
+)
+
+// DATABASE_DSN bir PostgreSQL bağlantısıysa (postgres:// veya "host=..." biçiminde) kişi ve kullanıcılar PostgreSQL'de tutulur;
+// diğer tablolar her durumda SQLite'ta kalır. Her instance'ın kendi SQLite dosyası olduğundan PostgreSQL ile sadece tek
+// instance çalıştırılmalıdır. Aksi halde DATABASE_DSN SQLite dosyasının yolu olarak kullanılır
 func ConnectDatabase() error {
-	db, err := sql.Open("sqlite", "./database.db")
+	dsn := os.Getenv("DATABASE_DSN")
+
+	if isPostgresDSN(dsn) {
+		if err := OpenDatabase("./database.db"); err != nil {
+			return err
+		}
+		log.Println("Kişi ve kullanıcılar PostgreSQL'de, diğer kayıtlar ./database.db dosyasında tutuluyor; sadece tek instance çalıştırılmalı")
+		return OpenPostgres(dsn)
+	}
+
+	if dsn == "" {
+		dsn = "./database.db"
+	}
+
+	return OpenDatabase(dsn)
+}
+
+func OpenDatabase(dsn string) error {
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return err
 	}
 
 	DB = db
-	return nil
+
+	if postgresDB != nil {
+		postgresDB.Close()
+		postgresDB = nil
+	}
+	personStore, userStore = nil, nil
+
+	if !autoMigrate {
+		return nil
+	}
+
+	migrator, err := newSQLiteMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up()
+}
+
+func Persons() PersonRepository {
+	if personStore != nil {
+		return personStore
+	}
+	return NewSQLitePersonRepository(DB)
+}
+
+func Users() UserRepository {
+	if userStore != nil {
+		return userStore
+	}
+	return NewSQLiteUserRepository(DB)
 }
 
 type Person struct {
@@ -27,6 +83,16 @@ type Person struct {
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
 	IpAddress string `json:"ip_address"`
+	OwnerID   int    `json:"owner_id" swaggerignore:"true"`
+	TenantID  int    `json:"tenant_id" swaggerignore:"true"`
+}
+
+// İsteği yapan kullanıcı. Sorgular her zaman kullanıcının tenant'ı ile sınırlandırılır. Admin (tenant admin'i) değilse
+// ayrıca kullanıcının sahip olduğu veya onunla paylaşılan kayıtlarla sınırlandırılır
+type PersonScope struct {
+	UserID   int
+	TenantID int
+	Admin    bool
 }
 
 type User struct {
@@ -35,8 +101,12 @@ type User struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Role     string `json:"role"`
+	TenantID int    `json:"tenant_id" swaggerignore:"true"`
 }
 
+// Aşağıdaki paket fonksiyonları Persons() ve Users() depolarını kullanır. main'deki handler'lar depoları
+// PersonRepository ve UserRepository arayüzleri üzerinden alır
+
 // @Summary Get a list of persons with pagination
 // @Description Get persons list from the database
 // @Tags person
@@ -44,39 +114,17 @@ type User struct {
 // @Produce json
 // @Param page query int false "Page number for pagination (default is 1)"
 // @Param pageSize query int false "Number of items per page (default is 20)"
+// @Param first_name query string false "Exact first name (case insensitive)"
+// @Param last_name query string false "Exact last name (case insensitive)"
+// @Param email query string false "Exact e-mail address (case insensitive)"
+// @Param ip_address query string false "Exact IP address"
+// @Param email_domain query string false "Domain of the e-mail address, e.g. example.com"
+// @Param q query string false "Text contained in the first name, last name or e-mail address"
+// @Param sort query string false "Comma separated fields (id, first_name, last_name, email, ip_address); prefix with - for descending, e.g. -last_name,first_name"
 // @Success 200 {object} Person
 // @Router /api/v1/person [get]
-func GetPersons(limit, offset int) ([]Person, error) {
-
-	query := fmt.Sprintf("SELECT id, first_name, last_name, email, ip_address FROM people LIMIT %d OFFSET %d", limit, offset)
-
-	rows, err := DB.Query(query)
-	if err != nil {
-		return nil, err
-	}
-
-	defer rows.Close()
-
-	people := make([]Person, 0)
-
-	for rows.Next() {
-		singlePerson := Person{}
-		err = rows.Scan(&singlePerson.Id, &singlePerson.FirstName, &singlePerson.LastName, &singlePerson.Email, &singlePerson.IpAddress)
-
-		if err != nil {
-			return nil, err
-		}
-
-		people = append(people, singlePerson)
-	}
-
-	err = rows.Err()
-
-	if err != nil {
-		return nil, err
-	}
-
-	return people, err
+func GetPersons(limit, offset int, scope PersonScope, filters PersonQuery) ([]Person, error) {
+	return Persons().List(limit, offset, scope, filters)
 }
 
 // @Summary Get a person by ID
@@ -87,24 +135,8 @@ func GetPersons(limit, offset int) ([]Person, error) {
 // @Param id path int true "Person ID"
 // @Success 200 {object} Person
 // @Router /api/v1/person/{id} [get]
-func GetPersonById(id string) (Person, error) {
-	stmt, err := DB.Prepare("SELECT id, first_name, last_name, email, ip_address FROM people WHERE id = ?")
-
-	if err != nil {
-		return Person{}, err
-	}
-
-	person := Person{}
-
-	sqlErr := stmt.QueryRow(id).Scan(&person.Id, &person.FirstName, &person.LastName, &person.Email, &person.IpAddress)
-
-	if sqlErr != nil {
-		if sqlErr == sql.ErrNoRows {
-			return Person{}, nil
-		}
-		return Person{}, sqlErr
-	}
-	return person, nil
+func GetPersonById(id string, scope PersonScope) (Person, error) {
+	return Persons().Get(id, scope)
 }
 
 // @Summary Add a new person
@@ -115,29 +147,8 @@ func GetPersonById(id string) (Person, error) {
 // @Param person body Person true "New Person Object"
 // @Success 200 {string} string "Person added successfully"
 // @Router /api/v1/person [post]
-func AddPerson(newPerson Person) (bool, error) {
-	tx, err := DB.Begin()
-	if err != nil {
-		return false, err
-	}
-
-	stmt, err := tx.Prepare("INSERT INTO people (first_name, last_name, email, ip_address) VALUES (?, ?, ?, ?)")
-
-	if err != nil {
-		return false, err
-	}
-
-	defer stmt.Close()
-
-	_, err = stmt.Exec(newPerson.FirstName, newPerson.LastName, newPerson.Email, newPerson.IpAddress)
-
-	if err != nil {
-		return false, err
-	}
-
-	tx.Commit()
-
-	return true, nil
+func AddPerson(newPerson Person, scope PersonScope) (bool, error) {
+	return Persons().Add(newPerson, scope)
 }
 
 // @Summary Update a person's information by their ID
@@ -149,41 +160,8 @@ func AddPerson(newPerson Person) (bool, error) {
 // @Param person body Person true "Updated Person Object"
 // @Success 200 {string} string "Person updated successfully"
 // @Router /api/v1/person/{id} [put]
-func UpdatePerson(ourPerson Person, id int) (bool, error) {
-	tx, err := DB.Begin()
-	if err != nil {
-		return false, err
-	}
-
-	var count int
-	err = DB.QueryRow("SELECT COUNT(*) FROM people WHERE id = ?", id).Scan(&count)
-	if err != nil {
-		tx.Rollback()
-		return false, err
-	}
-
-	if count == 0 {
-		tx.Rollback()
-		return false, err
-	}
-
-	stmt, err := tx.Prepare("UPDATE people SET first_name = ?, last_name = ?, email = ?, ip_address = ? WHERE Id = ?")
-
-	if err != nil {
-		return false, err
-	}
-
-	defer stmt.Close()
-
-	_, err = stmt.Exec(ourPerson.FirstName, ourPerson.LastName, ourPerson.Email, ourPerson.IpAddress, ourPerson.Id)
-
-	if err != nil {
-		return false, err
-	}
-
-	tx.Commit()
-
-	return true, nil
+func UpdatePerson(ourPerson Person, id int, scope PersonScope) (bool, error) {
+	return Persons().Update(ourPerson, id, scope)
 }
 
 // @Summary Delete a person by their ID
@@ -194,41 +172,8 @@ func UpdatePerson(ourPerson Person, id int) (bool, error) {
 // @Param id path int true "Person ID"
 // @Success 200 {string} string "Person deleted successfully"
 // @Router /api/v1/person/{id} [delete]
-func DeletePerson(personId int) (bool, error) {
-	tx, err := DB.Begin()
-
-	if err != nil {
-		return false, err
-	}
-
-	var count int
-	err = DB.QueryRow("SELECT COUNT(*) FROM people WHERE id = ?", personId).Scan(&count)
-	if err != nil {
-		return false, err
-	}
-
-	if count == 0 {
-		tx.Rollback()
-		return false, err
-	}
-
-	stmt, err := DB.Prepare("DELETE from people WHERE id = ?")
-
-	if err != nil {
-		return false, err
-	}
-
-	defer stmt.Close()
-
-	_, err = stmt.Exec(personId)
-
-	if err != nil {
-		return false, err
-	}
-
-	tx.Commit()
-
-	return true, nil
+func DeletePerson(personId int, scope PersonScope) (bool, error) {
+	return Persons().Delete(personId, scope)
 }
 
 // @Summary Get a list of users with pagination
@@ -240,37 +185,8 @@ func DeletePerson(personId int) (bool, error) {
 // @Param pageSize query int false "Number of items per page (default is 20)"
 // @Success 200 {object} User
 // @Router /api/v1/user [get]
-func GetUsers(limit, offset int) ([]User, error) {
-
-	query := fmt.Sprintf("SELECT id, username, email, '*****' AS password, role FROM user LIMIT %d OFFSET %d", limit, offset)
-
-	rows, err := DB.Query(query)
-	if err != nil {
-		return nil, err
-	}
-
-	defer rows.Close()
-
-	users := make([]User, 0)
-
-	for rows.Next() {
-		var user User
-		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
-
-		if err != nil {
-			return nil, err
-		}
-
-		users = append(users, user)
-	}
-
-	err = rows.Err()
-
-	if err != nil {
-		return nil, err
-	}
-
-	return users, nil
+func GetUsers(limit, offset, tenantID int) ([]User, error) {
+	return Users().List(limit, offset, tenantID)
 }
 
 // @Summary Get a user by ID
@@ -281,13 +197,12 @@ func GetUsers(limit, offset int) ([]User, error) {
 // @Param id path int true "User ID"
 // @Success 200 {object} User
 // @Router /api/v1/user/{id} [get]
-func GetUserByID(userID int) (User, error) {
-	var user User
-	err := DB.QueryRow("SELECT id, username, email, '*****' AS password, role FROM user WHERE id = ?", userID).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
-	if err != nil {
-		return User{}, err
-	}
-	return user, nil
+func GetUserByID(userID, tenantID int) (User, error) {
+	return Users().GetByID(userID, tenantID)
+}
+
+func GetUserByUsername(username string) (User, error) {
+	return Users().GetByUsername(username)
 }
 
 // @Summary Create a new user
@@ -299,21 +214,7 @@ func GetUserByID(userID int) (User, error) {
 // @Success 200 {integer} integer
 // @Router /api/v1/user [post]
 func CreateUser(newUser User) (int64, error) {
-	if newUser.Role != "user" {
-		newUser.Role = "user"
-	}
-
-	result, err := DB.Exec("INSERT INTO user (username, email, password, role) VALUES (?, ?, ?, ?)", newUser.Username, newUser.Email, newUser.Password, newUser.Role)
-	if err != nil {
-		return 0, err
-	}
-
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, err
-	}
-
-	return id, nil
+	return Users().Create(newUser)
 }
 
 // @Summary Update an existing user
@@ -326,37 +227,7 @@ func CreateUser(newUser User) (int64, error) {
 // @Success 200 {string} string
 // @Router /api/v1/user/{id} [put]
 func UpdateUser(updatedUser User) error {
-	if updatedUser.Role == "" {
-		updatedUser.Role = "user"
-	}
-
-	var count int
-	err := DB.QueryRow("SELECT COUNT(*) FROM user WHERE id = ?", updatedUser.ID).Scan(&count)
-	if err != nil {
-		return err
-	}
-	if count == 0 {
-		return errors.New("kullanici bulunamadi")
-	}
-
-	query := "UPDATE user SET username = ?, email = ?, role = ?"
-	var args []interface{}
-	args = append(args, updatedUser.Username, updatedUser.Email, updatedUser.Role)
-
-	if updatedUser.Password != "" {
-		query += ", password = ?"
-		args = append(args, updatedUser.Password)
-	}
-
-	query += " WHERE id = ?"
-	args = append(args, updatedUser.ID)
-
-	_, err = DB.Exec(query, args...)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return Users().Update(updatedUser)
 }
 
 // @Summary Delete a user by ID
@@ -367,63 +238,18 @@ func UpdateUser(updatedUser User) error {
 // @Param id path int true "User ID to delete"
 // @Success 200 {string} string
 // @Router /api/v1/user/{id} [delete]
-func DeleteUser(userID int) error {
-	result, err := DB.Exec("DELETE FROM user WHERE id = ?", userID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-
-	if rowsAffected == 0 {
-		return errors.New("kullanici bulunamadi")
-	}
-
-	return nil
+func DeleteUser(userID, tenantID int) error {
+	return Users().Delete(userID, tenantID)
 }
 
-func GetTotalPersonsCount() (int, error) {
-	var count int
-	query := "SELECT COUNT(*) FROM people"
-
-	err := DB.QueryRow(query).Scan(&count)
-	if err != nil {
-		return 0, err
-	}
-
-	return count, nil
+func GetTotalPersonsCount(scope PersonScope, filters PersonQuery) (int, error) {
+	return Persons().Count(scope, filters)
 }
 
-func GetTotalUsersCount() (int, error) {
-	var count int
-	query := "SELECT COUNT(*) FROM user"
-
-	err := DB.QueryRow(query).Scan(&count)
-	if err != nil {
-		return 0, err
-	}
-
-	return count, nil
+func GetTotalUsersCount(tenantID int) (int, error) {
+	return Users().Count(tenantID)
 }
 
 func GetUserByUsernameAndPassword(username, password string) (User, error) {
-	var user User
-	query := "SELECT id, username, email, password, role FROM user WHERE username = ? LIMIT 1"
-
-	err := DB.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return user, errors.New("kullanıcı bulunamadı")
-		}
-		return user, fmt.Errorf("kullanıcı verileri alınırken hata oluştu: %v", err)
-	}
-
-	if user.Password != password {
-		return user, errors.New("şifre yanlış")
-	}
-
-	return user, nil
+	return Users().Authenticate(username, password)
 }
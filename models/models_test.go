@@ -4,50 +4,19 @@ import (
 	"testing"
 
 	"example.com/webservice/models"
-
-	"errors"
 )
 
-type MockDB struct {
-	TestPersons []models.Person
-}
-
-func (m *MockDB) GetPersons(count int) ([]models.Person, error) {
-	return m.TestPersons, nil
-}
-
-func (m *MockDB) AddPerson(person models.Person) error {
-	m.TestPersons = append(m.TestPersons, person)
-	return nil
-}
-
-func (m *MockDB) UpdatePerson(person models.Person) error {
-	for i, p := range m.TestPersons {
-		if p.Id == person.Id {
-			m.TestPersons[i] = person
-			return nil
-		}
-	}
-	return errors.New("Kayıt bulunamadı")
-}
-
-func (m *MockDB) DeletePerson(id int) error {
-	for i, p := range m.TestPersons {
-		if p.Id == id {
-			m.TestPersons = append(m.TestPersons[:i], m.TestPersons[i+1:]...)
-			return nil
-		}
-	}
-	return errors.New("Kayıt bulunamadı")
+func TestCRUDOperations(t *testing.T) {
+	// Testler depo arayüzü üzerinden her veritabanı gerçeklemesinde çalışır
+	forEachBackend(t, testPersonCRUD)
 }
 
-func TestCRUDOperations(t *testing.T) {
-	// Test veritabanı olarak mock oluşturuldu
-	mockDB := &MockDB{}
+func testPersonCRUD(t *testing.T, repo models.PersonRepository, _ models.UserRepository) {
+	scope := models.PersonScope{UserID: 1, TenantID: models.DefaultTenantID}
 
 	// AddPerson testi
 	newPerson := models.Person{Id: 1, FirstName: "Ali", LastName: "Veli", Email: "aliveli@test.com", IpAddress: "192.168.1.1"}
-	err := mockDB.AddPerson(newPerson)
+	_, err := repo.Add(newPerson, scope)
 	if err != nil {
 		t.Errorf("Kişi eklenirken hata oluştu: %v", err)
 	}
@@ -55,7 +24,7 @@ func TestCRUDOperations(t *testing.T) {
 	t.Logf("Kişi eklendi: %+v", newPerson)
 
 	// GetPersons testi
-	persons, err := mockDB.GetPersons(10)
+	persons, err := repo.List(10, 0, scope, models.PersonQuery{})
 	if err != nil {
 		t.Errorf("Kişiler alınırken hata oluştu: %v", err)
 	}
@@ -69,15 +38,19 @@ func TestCRUDOperations(t *testing.T) {
 
 	// UpdatePerson testi
 	updatePerson := models.Person{Id: 1, FirstName: "Harry", LastName: "Potter", Email: "harrypotter@test2.com", IpAddress: "192.168.1.2"}
-	err = mockDB.UpdatePerson(updatePerson)
-	if err != nil {
+	updated, err := repo.Update(updatePerson, 1, scope)
+	if err != nil || !updated {
 		t.Errorf("Kişi güncellenirken hata oluştu: %v", err)
 	}
 
+	if person, _ := repo.Get("1", scope); person.FirstName != "Harry" {
+		t.Errorf("Güncellenen kişi alınamadı: %+v", person)
+	}
+
 	t.Logf("Kişi güncellendi: %+v", updatePerson)
 
 	// GetPersons Güncelleme sonrası ikinci test
-	persons, err = mockDB.GetPersons(10)
+	persons, err = repo.List(10, 0, scope, models.PersonQuery{})
 	if err != nil {
 		t.Errorf("Kişiler alınırken hata oluştu: %v", err)
 	}
@@ -90,15 +63,15 @@ func TestCRUDOperations(t *testing.T) {
 	t.Logf("Güncellemeden Sonra Alınan kişiler: %+v", persons)
 
 	// DeletePerson testi
-	err = mockDB.DeletePerson(1)
-	if err != nil {
+	deleted, err := repo.Delete(1, scope)
+	if err != nil || !deleted {
 		t.Errorf("Kişi silinirken hata oluştu: %v", err)
 	}
 
 	t.Logf("Kişi silindi: ID=%d", 1)
 
 	// Tekrar GetPersons testi
-	persons, err = mockDB.GetPersons(10)
+	persons, err = repo.List(10, 0, scope, models.PersonQuery{})
 	if err != nil {
 		t.Errorf("Kişiler alınırken hata oluştu: %v", err)
 	}
@@ -111,45 +84,16 @@ func TestCRUDOperations(t *testing.T) {
 	t.Logf("Tekrarlanan getPersons testinde alınan kişiler: %+v", persons)
 }
 
-type MockUserDB struct {
-	TestUsers []models.User
-}
-
-func (m *MockUserDB) GetUsers() ([]models.User, error) {
-	return m.TestUsers, nil
-}
-
-func (m *MockUserDB) AddUser(user models.User) error {
-	m.TestUsers = append(m.TestUsers, user)
-	return nil
-}
-
-func (m *MockUserDB) UpdateUser(user models.User) error {
-	for i, u := range m.TestUsers {
-		if u.ID == user.ID {
-			m.TestUsers[i] = user
-			return nil
-		}
-	}
-	return errors.New("Kullanıcı bulunamadı")
+func TestCRUDOperationsForUser(t *testing.T) {
+	forEachBackend(t, testUserCRUD)
 }
 
-func (m *MockUserDB) DeleteUser(id int) error {
-	for i, u := range m.TestUsers {
-		if u.ID == id {
-			m.TestUsers = append(m.TestUsers[:i], m.TestUsers[i+1:]...)
-			return nil
-		}
-	}
-	return errors.New("Kullanıcı bulunamadı")
-}
-
-func TestCRUDOperationsForUser(t *testing.T) {
-	mockDB := &MockUserDB{}
+func testUserCRUD(t *testing.T, _ models.PersonRepository, repo models.UserRepository) {
+	tenantID := models.DefaultTenantID
 
 	// AddUser testi
-	newUser := models.User{ID: 1, Username: "testuser", Email: "testuser@example.com", Password: "test1234", Role: "user"}
-	err := mockDB.AddUser(newUser)
+	newUser := models.User{Username: "testuser", Email: "testuser@example.com", Password: "gizli1234", Role: "user", TenantID: tenantID}
+	id, err := repo.Create(newUser)
 	if err != nil {
 		t.Errorf("Kullanıcı eklenirken hata oluştu: %v", err)
 	}
@@ -157,7 +101,7 @@ func TestCRUDOperationsForUser(t *testing.T) {
 	t.Logf("Kullanıcı eklendi: %+v", newUser)
 
 	// GetUsers testi
-	users, err := mockDB.GetUsers()
+	users, err := repo.List(10, 0, tenantID)
 	if err != nil {
 		t.Errorf("Kullanıcılar alınırken hata oluştu: %v", err)
 	}
@@ -170,16 +114,20 @@ func TestCRUDOperationsForUser(t *testing.T) {
 	t.Logf("Alınan kullanıcılar: %+v", users)
 
 	// UpdateUser testi
-	updateUser := models.User{ID: 1, Username: "testuserGÜNCELLENDİ", Email: "testuserGÜNCELLENDİ@example.com", Password: "test1234GÜNCELLENDİ", Role: "admin"}
-	err = mockDB.UpdateUser(updateUser)
+	updateUser := models.User{ID: int(id), Username: "testuserGÜNCELLENDİ", Email: "testuserGÜNCELLENDİ@example.com", Password: "gizli1234GÜNCELLENDİ", Role: "admin", TenantID: tenantID}
+	err = repo.Update(updateUser)
 	if err != nil {
 		t.Errorf("Kullanıcı güncellenirken hata oluştu: %v", err)
 	}
 
+	if user, err := repo.GetByID(int(id), tenantID); err != nil || user.Username != updateUser.Username || user.Password != "*****" {
+		t.Errorf("Güncellenen kullanıcı alınamadı: %+v, %v", user, err)
+	}
+
 	t.Logf("Kullanıcı güncellendi: %+v", updateUser)
 
 	// GetUsers Güncelleme sonrası ikinci test
-	users, err = mockDB.GetUsers()
+	users, err = repo.List(10, 0, tenantID)
 	if err != nil {
 		t.Errorf("Kullanıcılar alınırken hata oluştu: %v", err)
 	}
@@ -192,15 +140,15 @@ func TestCRUDOperationsForUser(t *testing.T) {
 	t.Logf("Güncellemeden Sonra Alınan kullanıcılar: %+v", users)
 
 	// DeleteUser testi
-	err = mockDB.DeleteUser(1)
+	err = repo.Delete(int(id), tenantID)
 	if err != nil {
 		t.Errorf("Kullanıcı silinirken hata oluştu: %v", err)
 	}
 
-	t.Logf("Kullanıcı silindi: ID=%d", 1)
+	t.Logf("Kullanıcı silindi: ID=%d", id)
 
 	// Tekrar GetUsers testi
-	users, err = mockDB.GetUsers()
+	users, err = repo.List(10, 0, tenantID)
 	if err != nil {
 		t.Errorf("Kullanıcılar alınırken hata oluştu: %v", err)
 	}
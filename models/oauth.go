@@ -0,0 +1,171 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOAuthClientNotFound = errors.New("oauth istemcisi bulunamadı")
+	ErrOAuthCodeNotFound   = errors.New("yetkilendirme kodu bulunamadı")
+)
+
+// Kayıtlı OAuth2 istemcisi. SecretHash boşsa istemci public'tir (örn. SPA, mobil uygulama) ve sadece PKCE ile
+// authorization code akışını kullanabilir. UserID doluysa client_credentials ile alınan token'lar bu kullanıcı adına işlem yapar
+type OAuthClient struct {
+	ID           string    `json:"client_id"`
+	SecretHash   string    `json:"-"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	UserID       *int      `json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (client OAuthClient) Confidential() bool {
+	return client.SecretHash != ""
+}
+
+// Authorization code akışında kullanıcı onayından sonra üretilen tek kullanımlık kod
+type OAuthCode struct {
+	ClientID      string
+	UserID        int
+	RedirectURI   string
+	Scopes        []string
+	CodeChallenge string
+	ExpiresAt     time.Time
+	UsedAt        *time.Time
+	FamilyID      string
+}
+
+const oauthClientColumns = "id, secret_hash, name, redirect_uris, scopes, user_id, created_at"
+
+func scanOAuthClient(row interface{ Scan(...interface{}) error }) (OAuthClient, error) {
+	var client OAuthClient
+	var redirectURIs, scopes string
+	var userID sql.NullInt64
+	var createdAt int64
+
+	if err := row.Scan(&client.ID, &client.SecretHash, &client.Name, &redirectURIs, &scopes, &userID, &createdAt); err != nil {
+		return OAuthClient{}, err
+	}
+
+	client.RedirectURIs = strings.Fields(redirectURIs)
+	client.Scopes = strings.Fields(scopes)
+	client.UserID = nullInt(userID)
+	client.CreatedAt = time.Unix(createdAt, 0)
+
+	return client, nil
+}
+
+func CreateOAuthClient(client OAuthClient) error {
+	_, err := DB.Exec("INSERT INTO oauth_client ("+oauthClientColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		client.ID, client.SecretHash, client.Name, strings.Join(client.RedirectURIs, " "), strings.Join(client.Scopes, " "), client.UserID, time.Now().Unix())
+	return err
+}
+
+func GetOAuthClient(id string) (OAuthClient, error) {
+	client, err := scanOAuthClient(DB.QueryRow("SELECT "+oauthClientColumns+" FROM oauth_client WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return OAuthClient{}, ErrOAuthClientNotFound
+	}
+
+	return client, err
+}
+
+func GetOAuthClients() ([]OAuthClient, error) {
+	rows, err := DB.Query("SELECT " + oauthClientColumns + " FROM oauth_client ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	clients := make([]OAuthClient, 0)
+
+	for rows.Next() {
+		client, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, rows.Err()
+}
+
+// İstemciyi ve istemciye verilmiş tüm refresh token'ları siler
+func DeleteOAuthClient(id string) error {
+	result, err := DB.Exec("DELETE FROM oauth_client WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	if err := expectOneRow(result, ErrOAuthClientNotFound); err != nil {
+		return err
+	}
+
+	if _, err := DB.Exec("DELETE FROM oauth_code WHERE client_id = ?", id); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+
+	if _, err := DB.Exec("UPDATE user_session SET revoked_at = ? WHERE client_id = ? AND revoked_at IS NULL", now, id); err != nil {
+		return err
+	}
+
+	_, err = DB.Exec("UPDATE refresh_token SET revoked_at = ? WHERE client_id = ? AND revoked_at IS NULL", now, id)
+	return err
+}
+
+func CreateOAuthCode(codeHash string, code OAuthCode) error {
+	_, err := DB.Exec("INSERT INTO oauth_code (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		codeHash, code.ClientID, code.UserID, code.RedirectURI, strings.Join(code.Scopes, " "), code.CodeChallenge, code.ExpiresAt.Unix())
+	return err
+}
+
+// Kodu kullanılıp kullanılmadığına bakmadan döner. Kod daha önce kullanıldıysa UsedAt dolu ve FamilyID ilk kullanımda
+// verilen token'ların ailesidir, böylece çağıran taraf o aileyi iptal edebilir
+func GetOAuthCode(codeHash string) (OAuthCode, error) {
+	var code OAuthCode
+	var scopes string
+	var expiresAt int64
+	var usedAt sql.NullInt64
+	var storedFamily sql.NullString
+
+	err := DB.QueryRow("SELECT client_id, user_id, redirect_uri, scopes, code_challenge, expires_at, used_at, family_id FROM oauth_code WHERE code_hash = ?", codeHash).
+		Scan(&code.ClientID, &code.UserID, &code.RedirectURI, &scopes, &code.CodeChallenge, &expiresAt, &usedAt, &storedFamily)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return OAuthCode{}, ErrOAuthCodeNotFound
+		}
+		return OAuthCode{}, err
+	}
+
+	code.Scopes = strings.Fields(scopes)
+	code.ExpiresAt = time.Unix(expiresAt, 0)
+	code.UsedAt = nullUnixTime(usedAt)
+	code.FamilyID = storedFamily.String
+
+	return code, nil
+}
+
+// Kodu kullanıldı olarak işaretler ve kodla üretilecek token ailesini kaydeder. Kod bu arada başka bir istekte
+// kullanıldıysa false döner
+func ConsumeOAuthCode(codeHash, familyID string) (bool, error) {
+	result, err := DB.Exec("UPDATE oauth_code SET used_at = ?, family_id = ? WHERE code_hash = ? AND used_at IS NULL", time.Now().Unix(), familyID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
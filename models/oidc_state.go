@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrOIDCStateNotFound = errors.New("OIDC state bulunamadı")
+	ErrTooManyOIDCStates = errors.New("bekleyen OIDC girişi sayısı sınırı aşıldı")
+)
+
+// Kimlik sağlayıcıya yönlendirilen giriş isteği. LinkUserID sıfırdan farklıysa mevcut hesabı bağlama isteğidir
+type OIDCState struct {
+	Nonce      string
+	Verifier   string
+	LinkUserID int
+	ExpiresAt  time.Time
+}
+
+// Süresi dolan istekleri silip yenisini kaydeder. Süresi dolmamış istek sayısı limit'e ulaştıysa kaydetmez, böylece
+// tamamlanmayan girişlerle tablo sınırsız büyütülemez
+func SaveOIDCState(stateHash string, state OIDCState, limit int) error {
+	if _, err := DB.Exec("DELETE FROM oidc_state WHERE expires_at <= ?", time.Now().Unix()); err != nil {
+		return err
+	}
+
+	result, err := DB.Exec("INSERT INTO oidc_state (state_hash, nonce, verifier, link_user_id, expires_at) SELECT ?, ?, ?, ?, ? WHERE (SELECT COUNT(*) FROM oidc_state) < ?",
+		stateHash, state.Nonce, state.Verifier, state.LinkUserID, state.ExpiresAt.Unix(), limit)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, ErrTooManyOIDCStates)
+}
+
+// İsteği silerek döner, böylece state tek kullanımlıktır. Süresi dolmuş istekler bulunamadı sayılır
+func TakeOIDCState(stateHash string) (OIDCState, error) {
+	var state OIDCState
+	var expiresAt int64
+
+	err := DB.QueryRow("DELETE FROM oidc_state WHERE state_hash = ? RETURNING nonce, verifier, link_user_id, expires_at", stateHash).
+		Scan(&state.Nonce, &state.Verifier, &state.LinkUserID, &expiresAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return OIDCState{}, ErrOIDCStateNotFound
+		}
+		return OIDCState{}, err
+	}
+
+	state.ExpiresAt = time.Unix(expiresAt, 0)
+	if time.Now().After(state.ExpiresAt) {
+		return OIDCState{}, ErrOIDCStateNotFound
+	}
+
+	return state, nil
+}
@@ -0,0 +1,44 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"example.com/webservice/models"
+)
+
+func TestOIDCStateLimitAndExpiry(t *testing.T) {
+	openTestDB(t)
+
+	valid := models.OIDCState{Nonce: "n", Verifier: "v", ExpiresAt: time.Now().Add(time.Minute)}
+	expired := models.OIDCState{Nonce: "n", Verifier: "v", ExpiresAt: time.Now().Add(-time.Minute)}
+
+	if err := models.SaveOIDCState("eski", expired, 2); err != nil {
+		t.Fatalf("State kaydedilemedi: %v", err)
+	}
+	if err := models.SaveOIDCState("a", valid, 2); err != nil {
+		t.Fatalf("State kaydedilemedi: %v", err)
+	}
+
+	// Süresi dolan istekler sınıra sayılmaz ve okunamaz
+	if _, err := models.TakeOIDCState("eski"); err != models.ErrOIDCStateNotFound {
+		t.Errorf("Süresi dolan state okundu: %v", err)
+	}
+	if err := models.SaveOIDCState("b", valid, 2); err != nil {
+		t.Fatalf("State kaydedilemedi: %v", err)
+	}
+	if err := models.SaveOIDCState("c", valid, 2); err != models.ErrTooManyOIDCStates {
+		t.Errorf("Sınır aşıldığında kayıt reddedilmedi: %v", err)
+	}
+
+	// State tek kullanımlıktır ve kullanılınca yer açılır
+	if state, err := models.TakeOIDCState("a"); err != nil || state.Nonce != "n" || state.Verifier != "v" {
+		t.Fatalf("State okunamadı: %+v, %v", state, err)
+	}
+	if _, err := models.TakeOIDCState("a"); err != models.ErrOIDCStateNotFound {
+		t.Errorf("State ikinci kez okundu: %v", err)
+	}
+	if err := models.SaveOIDCState("c", valid, 2); err != nil {
+		t.Errorf("State kaydedilemedi: %v", err)
+	}
+}
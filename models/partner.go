@@ -0,0 +1,152 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrPartnerNotFound = errors.New("partner bulunamadı")
+
+// İstekleri paylaşılan bir anahtarla HMAC imzalayarak gönderen entegrasyon ortağı. İmza sunucuda yeniden hesaplandığı için
+// anahtar hash'lenemez; veritabanında sunucu anahtarıyla şifrelenir (bkz. partner_secret.go). Anahtar yenilendiğinde eski
+// anahtar PreviousExpiresAt'e kadar geçerli kalır
+type Partner struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name"`
+	UserID            int        `json:"user_id"`
+	Secret            string     `json:"-"`
+	PreviousSecret    string     `json:"-"`
+	PreviousExpiresAt *time.Time `json:"previous_secret_expires_at"`
+	Scopes            []string   `json:"scopes"`
+	LastUsedAt        *time.Time `json:"last_used_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+const partnerColumns = "id, name, user_id, secret, previous_secret, previous_expires_at, scopes, last_used_at, created_at"
+
+func scanPartner(row interface{ Scan(...interface{}) error }) (Partner, error) {
+	var partner Partner
+	var scopes string
+	var previousExpiresAt, lastUsedAt sql.NullInt64
+	var createdAt int64
+
+	err := row.Scan(&partner.ID, &partner.Name, &partner.UserID, &partner.Secret, &partner.PreviousSecret, &previousExpiresAt,
+		&scopes, &lastUsedAt, &createdAt)
+	if err != nil {
+		return Partner{}, err
+	}
+
+	if partner.Secret, err = decryptPartnerSecret(partner.ID, partner.Secret); err != nil {
+		return Partner{}, err
+	}
+	if partner.PreviousSecret, err = decryptPartnerSecret(partner.ID, partner.PreviousSecret); err != nil {
+		return Partner{}, err
+	}
+
+	partner.Scopes = strings.Fields(scopes)
+	partner.PreviousExpiresAt = nullUnixTime(previousExpiresAt)
+	partner.LastUsedAt = nullUnixTime(lastUsedAt)
+	partner.CreatedAt = time.Unix(createdAt, 0)
+
+	return partner, nil
+}
+
+func CreatePartner(partner Partner) error {
+	secret, err := encryptPartnerSecret(partner.ID, partner.Secret)
+	if err != nil {
+		return err
+	}
+
+	_, err = DB.Exec("INSERT INTO partner (id, name, user_id, secret, scopes, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		partner.ID, partner.Name, partner.UserID, secret, strings.Join(partner.Scopes, " "), time.Now().Unix())
+	return err
+}
+
+func GetPartner(id string) (Partner, error) {
+	partner, err := scanPartner(DB.QueryRow("SELECT "+partnerColumns+" FROM partner WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return Partner{}, ErrPartnerNotFound
+	}
+
+	return partner, err
+}
+
+func GetPartners() ([]Partner, error) {
+	rows, err := DB.Query("SELECT " + partnerColumns + " FROM partner ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	partners := make([]Partner, 0)
+
+	for rows.Next() {
+		partner, err := scanPartner(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		partners = append(partners, partner)
+	}
+
+	return partners, rows.Err()
+}
+
+// Yeni anahtarı kaydeder; mevcut anahtar grace süresi boyunca önceki anahtar olarak kabul edilmeye devam eder
+func RotatePartnerSecret(id, secret string, grace time.Duration) error {
+	encrypted, err := encryptPartnerSecret(id, secret)
+	if err != nil {
+		return err
+	}
+
+	result, err := DB.Exec("UPDATE partner SET previous_secret = secret, previous_expires_at = ?, secret = ? WHERE id = ?",
+		time.Now().Add(grace).Unix(), encrypted, id)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, ErrPartnerNotFound)
+}
+
+func DeletePartner(id string) error {
+	result, err := DB.Exec("DELETE FROM partner WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, ErrPartnerNotFound)
+}
+
+// Son kullanım zamanını en fazla dakikada bir günceller
+func TouchPartner(id string) error {
+	now := time.Now().Unix()
+	_, err := DB.Exec("UPDATE partner SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)", now, id, now-60)
+	return err
+}
+
+// Nonce'u partner için kaydeder; daha önce kullanıldıysa false döner. Nonce expiresAt'e kadar saklanır, bu süreden sonra
+// aynı imzalı istek zaman aralığının dışında kalacağından kayıt silinebilir. Kayıtlar veritabanında tutulduğu için
+// bir instance'ta kullanılan nonce diğer instance'larda ve yeniden başlatmadan sonra da reddedilir
+func UsePartnerNonce(partnerID, nonce string, expiresAt time.Time) (bool, error) {
+	now := time.Now().Unix()
+
+	if _, err := DB.Exec("DELETE FROM partner_nonce WHERE expires_at <= ?", now); err != nil {
+		return false, err
+	}
+
+	result, err := DB.Exec("INSERT INTO partner_nonce (partner_id, nonce, expires_at) VALUES (?, ?, ?) ON CONFLICT (partner_id, nonce) DO NOTHING",
+		partnerID, nonce, expiresAt.Unix())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var ErrPartnerKeyMissing = errors.New("partner anahtarlarını şifrelemek için PARTNER_SECRET_KEY tanımlanmalı")
+
+// Şifrelenmiş partner anahtarlarının öneki. Önekli olmayan bir değer geçersiz sayılır
+const partnerSecretPrefix = "enc:v1:"
+
+// Partner anahtarları HMAC doğrulaması için düz hallerine ihtiyaç duyulduğundan hash'lenemez; veritabanında
+// sunucu anahtarıyla AES-256-GCM kullanılarak şifrelenir. Veritabanı yedeği sızsa bile imza üretilemez
+var partnerKey struct {
+	mu   sync.RWMutex
+	aead cipher.AEAD
+}
+
+// PARTNER_SECRET_KEY base64 kodlanmış 32 baytlık anahtardır. Tanımlanmazsa partner oluşturma ve imzalı istekler çalışmaz
+func LoadPartnerKeyFromEnv() error {
+	value := os.Getenv("PARTNER_SECRET_KEY")
+	if value == "" {
+		return SetPartnerSecretKey(nil)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("PARTNER_SECRET_KEY base64 olarak çözülemedi: %v", err)
+	}
+
+	return SetPartnerSecretKey(key)
+}
+
+// Anahtar 32 bayt olmalıdır; nil anahtar şifrelemeyi kapatır
+func SetPartnerSecretKey(key []byte) error {
+	var aead cipher.AEAD
+
+	if key != nil {
+		if len(key) != 32 {
+			return fmt.Errorf("PARTNER_SECRET_KEY 32 bayt olmalı, %d bayt verildi", len(key))
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+
+		if aead, err = cipher.NewGCM(block); err != nil {
+			return err
+		}
+	}
+
+	partnerKey.mu.Lock()
+	partnerKey.aead = aead
+	partnerKey.mu.Unlock()
+
+	return nil
+}
+
+func partnerAEAD() (cipher.AEAD, error) {
+	partnerKey.mu.RLock()
+	defer partnerKey.mu.RUnlock()
+
+	if partnerKey.aead == nil {
+		return nil, ErrPartnerKeyMissing
+	}
+
+	return partnerKey.aead, nil
+}
+
+// Şifreli metin partnerin kimliğine bağlanır, böylece bir satırdaki anahtar başka bir partnere kopyalanarak kullanılamaz
+func encryptPartnerSecret(partnerID, secret string) (string, error) {
+	aead, err := partnerAEAD()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(secret), []byte(partnerID))
+	return partnerSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func decryptPartnerSecret(partnerID, stored string) (string, error) {
+	if stored == "" {
+		return "", nil
+	}
+
+	if !strings.HasPrefix(stored, partnerSecretPrefix) {
+		return "", errors.New("partner anahtarı şifrelenmemiş")
+	}
+
+	aead, err := partnerAEAD()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, partnerSecretPrefix))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("partner anahtarı çözülemedi")
+	}
+
+	secret, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(partnerID))
+	if err != nil {
+		return "", errors.New("partner anahtarı çözülemedi")
+	}
+
+	return string(secret), nil
+}
@@ -0,0 +1,106 @@
+package models_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/webservice/models"
+)
+
+func TestPartnerSecretEncryption(t *testing.T) {
+	openTestDB(t)
+
+	if err := models.SetPartnerSecretKey(bytes.Repeat([]byte{1}, 32)); err != nil {
+		t.Fatalf("Partner anahtarı ayarlanamadı: %v", err)
+	}
+	t.Cleanup(func() { models.SetPartnerSecretKey(nil) })
+
+	if err := models.SetPartnerSecretKey([]byte("kisa")); err == nil {
+		t.Error("32 bayttan kısa anahtar kabul edildi")
+	}
+
+	if err := models.CreatePartner(models.Partner{ID: "p1", Name: "crm", UserID: 1, Secret: "gizli-anahtar"}); err != nil {
+		t.Fatalf("Partner oluşturulamadı: %v", err)
+	}
+
+	if err := models.CreatePartner(models.Partner{ID: "p2", Name: "erp", UserID: 1, Secret: "diger-anahtar"}); err != nil {
+		t.Fatalf("Partner oluşturulamadı: %v", err)
+	}
+
+	for id, secret := range map[string]string{"p1": "gizli-anahtar", "p2": "diger-anahtar"} {
+		var stored string
+		if err := models.DB.QueryRow("SELECT secret FROM partner WHERE id = ?", id).Scan(&stored); err != nil || strings.Contains(stored, secret) {
+			t.Errorf("%s anahtarı şifrelenmedi: %q, %v", id, stored, err)
+		}
+
+		if partner, err := models.GetPartner(id); err != nil || partner.Secret != secret {
+			t.Errorf("%s anahtarı çözülemedi: %q, %v", id, partner.Secret, err)
+		}
+	}
+
+	// Yenilemede eski anahtar da şifreli kalır
+	if err := models.RotatePartnerSecret("p1", "yeni-anahtar", time.Hour); err != nil {
+		t.Fatalf("Anahtar yenilenemedi: %v", err)
+	}
+	if partner, err := models.GetPartner("p1"); err != nil || partner.Secret != "yeni-anahtar" || partner.PreviousSecret != "gizli-anahtar" {
+		t.Errorf("Yenilenen anahtar okunamadı: %+v, %v", partner, err)
+	}
+
+	// Şifrelenmemiş anahtar okunmaz
+	if _, err := models.DB.Exec("INSERT INTO partner (id, name, user_id, secret, created_at) VALUES ('p4', 'duz', 1, 'duz-anahtar', 0)"); err != nil {
+		t.Fatalf("Partner eklenemedi: %v", err)
+	}
+	if _, err := models.GetPartner("p4"); err == nil {
+		t.Error("Şifrelenmemiş partner anahtarı okundu")
+	}
+
+	// Şifreli metin başka bir partnerin satırına taşınırsa çözülemez
+	if _, err := models.DB.Exec("UPDATE partner SET secret = (SELECT secret FROM partner WHERE id = 'p1') WHERE id = 'p2'"); err != nil {
+		t.Fatalf("Anahtar kopyalanamadı: %v", err)
+	}
+	if _, err := models.GetPartner("p2"); err == nil {
+		t.Error("Başka partnerin şifreli anahtarı çözüldü")
+	}
+
+	// Sunucu anahtarı olmadan partner oluşturulamaz ve şifreli anahtarlar okunamaz
+	models.SetPartnerSecretKey(nil)
+	if err := models.CreatePartner(models.Partner{ID: "p3", Name: "crm", UserID: 1, Secret: "x"}); err != models.ErrPartnerKeyMissing {
+		t.Errorf("Anahtar olmadan partner oluşturuldu: %v", err)
+	}
+	if _, err := models.GetPartner("p1"); err == nil {
+		t.Error("Anahtar olmadan partner anahtarı okundu")
+	}
+}
+
+func TestPartnerNonceExpiry(t *testing.T) {
+	openTestDB(t)
+
+	expiresAt := time.Now().Add(5 * time.Minute)
+
+	if fresh, err := models.UsePartnerNonce("p1", "n1", expiresAt); err != nil || !fresh {
+		t.Fatalf("Nonce kaydedilemedi: %v, %v", fresh, err)
+	}
+	if fresh, err := models.UsePartnerNonce("p1", "n1", expiresAt); err != nil || fresh {
+		t.Errorf("Aynı nonce ikinci kez kabul edildi: %v, %v", fresh, err)
+	}
+
+	// Nonce partner başınadır
+	if fresh, err := models.UsePartnerNonce("p2", "n1", expiresAt); err != nil || !fresh {
+		t.Errorf("Başka partnerin nonce'u reddedildi: %v, %v", fresh, err)
+	}
+
+	// Süresi dolan kayıtlar silinir
+	if _, err := models.UsePartnerNonce("p1", "eski", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("Nonce kaydedilemedi: %v", err)
+	}
+	if _, err := models.UsePartnerNonce("p1", "n2", expiresAt); err != nil {
+		t.Fatalf("Nonce kaydedilemedi: %v", err)
+	}
+
+	var count int
+	if err := models.DB.QueryRow("SELECT COUNT(*) FROM partner_nonce WHERE nonce = 'eski'").Scan(&count); err != nil || count != 0 {
+		t.Errorf("Süresi dolan nonce silinmedi: %d, %v", count, err)
+	}
+}
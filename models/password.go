@@ -0,0 +1,44 @@
+package models
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const passwordHashCost = bcrypt.DefaultCost
+
+// Kullanıcı bulunamadığında da bcrypt karşılaştırması yapılması için kullanılan hash.
+// Böylece yanıt süresinden kullanıcı adının var olup olmadığı anlaşılamaz
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), passwordHashCost)
+
+// Şifreyi bcrypt ile hashler
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// Veritabanındaki değerin bcrypt hash'i olup olmadığını kontrol eder (eski kayıtlar düz metin tutuluyordu)
+func IsPasswordHashed(stored string) bool {
+	return strings.HasPrefix(stored, "$2a$") || strings.HasPrefix(stored, "$2b$") || strings.HasPrefix(stored, "$2y$")
+}
+
+// Girilen şifreyi saklanan değerle karşılaştırır. Saklanan değer düz metinse (legacy) sabit zamanlı karşılaştırma yapılır
+// ve legacy true döner, böylece çağıran taraf kaydı yeniden hashleyebilir
+func CheckPassword(stored, password string) (match bool, legacy bool) {
+	if IsPasswordHashed(stored) {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil, false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1, true
+}
+
+// Kullanıcı bulunamadığında çağrılır, gerçek bir şifre kontrolü kadar süre harcar
+func checkDummyPassword(password string) {
+	bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+}
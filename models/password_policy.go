@@ -0,0 +1,347 @@
+package models
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"database/sql"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Şifre politikası. Yeni şifreler kullanıcı oluşturulurken, güncellenirken, kayıtta ve şifre sıfırlamada bu kurallara göre doğrulanır
+type PasswordPolicy struct {
+	MinLength            int
+	RequireUpper         bool
+	RequireLower         bool
+	RequireDigit         bool
+	RequireSymbol        bool
+	DisallowPersonalInfo bool // Şifre kullanıcı adını veya e-posta adresinin yerel kısmını içeremez
+	HistorySize          int  // Son N şifre tekrar kullanılamaz, 0 ise kontrol edilmez
+	CheckBreached        bool
+}
+
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:            8,
+	RequireLower:         true,
+	RequireDigit:         true,
+	DisallowPersonalInfo: true,
+	HistorySize:          5,
+	CheckBreached:        true,
+}
+
+// bcrypt 72 byte'tan uzun şifreleri kabul etmez
+const passwordMaxBytes = 72
+
+// Sızdırılmış şifre listesinin varsayılanı. Her satır bir şifrenin SHA-1 hash'idir (büyük harf hex, isteğe bağlı ":adet" ile)
+//
+//go:embed data/breached_passwords.txt
+var defaultBreachedPasswords string
+
+var passwordPolicy = struct {
+	sync.RWMutex
+	policy   PasswordPolicy
+	breached map[string]map[string]bool
+}{policy: DefaultPasswordPolicy}
+
+// Şifre politikasını değiştirir
+func SetPasswordPolicy(policy PasswordPolicy) {
+	passwordPolicy.Lock()
+	defer passwordPolicy.Unlock()
+
+	passwordPolicy.policy = policy
+}
+
+func currentPasswordPolicy() PasswordPolicy {
+	passwordPolicy.RLock()
+	defer passwordPolicy.RUnlock()
+
+	return passwordPolicy.policy
+}
+
+// Sızdırılmış şifre listesini yükler. Hash'ler HIBP range API'sindeki gibi ilk 5 karakterlerine göre gruplanır;
+// bir şifre sorgulanırken yalnızca o gruptaki son ekler karşılaştırılır
+func LoadBreachedPasswords(r io.Reader) error {
+	breached := make(map[string]map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash := strings.ToUpper(strings.SplitN(line, ":", 2)[0])
+		if len(hash) != 40 {
+			return fmt.Errorf("geçersiz sızdırılmış şifre hash'i: %s", line)
+		}
+
+		prefix, suffix := hash[:5], hash[5:]
+		if breached[prefix] == nil {
+			breached[prefix] = make(map[string]bool)
+		}
+		breached[prefix][suffix] = true
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	passwordPolicy.Lock()
+	passwordPolicy.breached = breached
+	passwordPolicy.Unlock()
+
+	return nil
+}
+
+func isBreachedPassword(password string) bool {
+	passwordPolicy.RLock()
+	loaded := passwordPolicy.breached != nil
+	passwordPolicy.RUnlock()
+
+	if !loaded {
+		if err := LoadBreachedPasswords(strings.NewReader(defaultBreachedPasswords)); err != nil {
+			return false
+		}
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	passwordPolicy.RLock()
+	defer passwordPolicy.RUnlock()
+
+	return passwordPolicy.breached[hash[:5]][hash[5:]]
+}
+
+// Şifre politikası ortam değişkenlerinden okunur. Tanımlanmayan ayarlar varsayılan değerlerinde kalır
+func LoadPasswordPolicyFromEnv() error {
+	policy := DefaultPasswordPolicy
+
+	ints := map[string]*int{
+		"PASSWORD_MIN_LENGTH": &policy.MinLength,
+		"PASSWORD_HISTORY":    &policy.HistorySize,
+	}
+	for name, target := range ints {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return fmt.Errorf("%s geçersiz: %s", name, value)
+			}
+			*target = n
+		}
+	}
+
+	bools := map[string]*bool{
+		"PASSWORD_REQUIRE_UPPER":     &policy.RequireUpper,
+		"PASSWORD_REQUIRE_LOWER":     &policy.RequireLower,
+		"PASSWORD_REQUIRE_DIGIT":     &policy.RequireDigit,
+		"PASSWORD_REQUIRE_SYMBOL":    &policy.RequireSymbol,
+		"PASSWORD_DISALLOW_PERSONAL": &policy.DisallowPersonalInfo,
+		"PASSWORD_CHECK_BREACHED":    &policy.CheckBreached,
+	}
+	for name, target := range bools {
+		if value := os.Getenv(name); value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s geçersiz: %s", name, value)
+			}
+			*target = b
+		}
+	}
+
+	if path := os.Getenv("PASSWORD_BREACHED_FILE"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		if err := LoadBreachedPasswords(file); err != nil {
+			return err
+		}
+	}
+
+	SetPasswordPolicy(policy)
+	return nil
+}
+
+// Şifre politikasının ihlal edilen bir kuralı
+type PasswordViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Şifre politikaya uymadığında dönen hata, ihlal edilen tüm kuralları içerir
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+
+	return "şifre politikaya uymuyor: " + strings.Join(messages, ", ")
+}
+
+// Şifreyi politikanın kullanıcıdan bağımsız kurallarına ve kullanıcı adı/e-posta kuralına göre doğrular.
+// Şifre geçmişi veritabanı gerektirdiği için ayrıca kontrol edilir
+func ValidatePassword(password string, user User) error {
+	policy := currentPasswordPolicy()
+
+	var violations []PasswordViolation
+	violate := func(rule, message string) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: message})
+	}
+
+	if n := len([]rune(password)); n < policy.MinLength {
+		violate("min_length", fmt.Sprintf("şifre en az %d karakter olmalı", policy.MinLength))
+	}
+
+	if len(password) > passwordMaxBytes {
+		violate("max_length", fmt.Sprintf("şifre en fazla %d byte olabilir", passwordMaxBytes))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	if policy.RequireUpper && !upper {
+		violate("uppercase", "şifre en az bir büyük harf içermeli")
+	}
+	if policy.RequireLower && !lower {
+		violate("lowercase", "şifre en az bir küçük harf içermeli")
+	}
+	if policy.RequireDigit && !digit {
+		violate("digit", "şifre en az bir rakam içermeli")
+	}
+	if policy.RequireSymbol && !symbol {
+		violate("symbol", "şifre en az bir özel karakter içermeli")
+	}
+
+	if policy.DisallowPersonalInfo && containsPersonalInfo(password, user) {
+		violate("personal_info", "şifre kullanıcı adını veya e-posta adresini içeremez")
+	}
+
+	if policy.CheckBreached && password != "" && isBreachedPassword(password) {
+		violate("breached", "bu şifre sızdırılmış şifreler listesinde, başka bir şifre seçin")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
+
+// 3 karakterden kısa kullanıcı adları ve e-posta yerel kısımları pek çok şifrede geçeceği için dikkate alınmaz
+func containsPersonalInfo(password string, user User) bool {
+	lowered := strings.ToLower(password)
+
+	candidates := []string{user.Username}
+	if at := strings.Index(user.Email, "@"); at > 0 {
+		candidates = append(candidates, user.Email[:at])
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		if len([]rune(candidate)) >= 3 && strings.Contains(lowered, candidate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Şifreyi mevcut şifre ve son şifrelerle karşılaştırır. Şifre geçmişi her zaman store'da (SQLite) tutulur
+func checkPasswordHistory(users UserRepository, store *sql.DB, userID int, password string) error {
+	policy := currentPasswordPolicy()
+	if policy.HistorySize == 0 {
+		return nil
+	}
+
+	hashes := make([]string, 0, policy.HistorySize+1)
+
+	current, err := users.PasswordHash(userID)
+	if err != nil {
+		return err
+	}
+	hashes = append(hashes, current)
+
+	rows, err := store.Query("SELECT password_hash FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?", userID, policy.HistorySize)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if match, _ := CheckPassword(hash, password); match {
+			return &PasswordPolicyError{Violations: []PasswordViolation{{
+				Rule:    "history",
+				Message: fmt.Sprintf("şifre son %d şifreden biri olamaz", policy.HistorySize),
+			}}}
+		}
+	}
+
+	return nil
+}
+
+// Şifre hash'ini geçmişe ekler ve politikadaki sayıdan eski kayıtları siler
+func recordPasswordHistory(exec interface {
+	Exec(string, ...interface{}) (sql.Result, error)
+}, userID int, hash string) error {
+	if _, err := exec.Exec("INSERT INTO password_history (user_id, password_hash, created_at) VALUES (?, ?, ?)", userID, hash, time.Now().Unix()); err != nil {
+		return err
+	}
+
+	_, err := exec.Exec(`DELETE FROM password_history WHERE user_id = ? AND id NOT IN
+		(SELECT id FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?)`, userID, userID, currentPasswordPolicy().HistorySize)
+	return err
+}
+
+// Doğrulanmış yeni şifrenin hash'ini döner. Mevcut kullanıcılarda şifre geçmişi de kontrol edilir
+func preparePassword(users UserRepository, store *sql.DB, password string, user User) (string, error) {
+	if err := ValidatePassword(password, user); err != nil {
+		return "", err
+	}
+
+	if user.ID != 0 {
+		if err := checkPasswordHistory(users, store, user.ID, password); err != nil {
+			return "", err
+		}
+	}
+
+	return HashPassword(password)
+}
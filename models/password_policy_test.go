@@ -0,0 +1,102 @@
+package models_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"example.com/webservice/models"
+)
+
+func violatedRules(t *testing.T, err error) []string {
+	t.Helper()
+
+	if err == nil {
+		return nil
+	}
+
+	var policyErr *models.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Beklenmeyen hata: %v", err)
+	}
+
+	rules := make([]string, len(policyErr.Violations))
+	for i, v := range policyErr.Violations {
+		rules[i] = v.Rule
+	}
+	return rules
+}
+
+func TestValidatePassword(t *testing.T) {
+	models.SetPasswordPolicy(models.PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true, DisallowPersonalInfo: true, CheckBreached: true})
+	defer models.SetPasswordPolicy(models.DefaultPasswordPolicy)
+
+	user := models.User{Username: "ahmet", Email: "ayilmaz@example.com"}
+
+	tests := []struct {
+		password string
+		rules    string
+	}{
+		{"", "min_length uppercase lowercase digit symbol"},
+		{"kisa", "min_length uppercase digit symbol"},
+		{"Ahmet.2024!x", "personal_info"},
+		{"AYilmaz#2024", "personal_info"},
+		{"Qwerty1234", "symbol breached"},
+		{"Guclu.Sifre-2024", ""},
+		{"Şükrü.Ğüneş-2024", ""},
+		{strings.Repeat("Aa1.", 20), "max_length"},
+	}
+
+	for _, tt := range tests {
+		got := strings.Join(violatedRules(t, models.ValidatePassword(tt.password, user)), " ")
+		if got != tt.rules {
+			t.Errorf("%q için ihlal edilen kurallar yanlış. Beklenen: %q, Alınan: %q", tt.password, tt.rules, got)
+		}
+	}
+
+}
+
+func TestBreachedPasswordAndHistory(t *testing.T) {
+	openTestDB(t)
+	models.SetPasswordPolicy(models.PasswordPolicy{MinLength: 8, HistorySize: 2, CheckBreached: true})
+	defer models.SetPasswordPolicy(models.DefaultPasswordPolicy)
+
+	// 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8 = SHA-1("password")
+	if err := models.LoadBreachedPasswords(strings.NewReader("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\n")); err != nil {
+		t.Fatalf("Sızdırılmış şifre listesi yüklenemedi: %v", err)
+	}
+
+	if rules := violatedRules(t, models.ValidatePassword("password", models.User{})); len(rules) != 1 || rules[0] != "breached" {
+		t.Errorf("Sızdırılmış şifre kabul edildi: %v", rules)
+	}
+
+	if err := models.LoadBreachedPasswords(strings.NewReader("bozuk\n")); err == nil {
+		t.Errorf("Geçersiz sızdırılmış şifre listesi kabul edildi")
+	}
+
+	id, err := models.CreateUser(models.User{Username: "ayse", Password: "birinci-sifre", TenantID: models.DefaultTenantID})
+	if err != nil {
+		t.Fatalf("Kullanıcı eklenemedi: %v", err)
+	}
+
+	update := func(password string) error {
+		return models.UpdateUser(models.User{ID: int(id), Username: "ayse", Password: password, TenantID: models.DefaultTenantID})
+	}
+
+	for _, password := range []string{"ikinci-sifre", "ucuncu-sifre"} {
+		if err := update(password); err != nil {
+			t.Fatalf("Şifre değiştirilemedi: %v", err)
+		}
+	}
+
+	// Mevcut ve bir önceki şifre reddedilmeli, geçmişten düşen ilk şifre tekrar kullanılabilmeli
+	for _, password := range []string{"ucuncu-sifre", "ikinci-sifre"} {
+		if rules := violatedRules(t, update(password)); len(rules) != 1 || rules[0] != "history" {
+			t.Errorf("%q için geçmiş kontrolü yapılmadı: %v", password, rules)
+		}
+	}
+
+	if err := update("birinci-sifre"); err != nil {
+		t.Errorf("Geçmişten düşen şifre kabul edilmedi: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrPasswordResetNotFound = errors.New("şifre sıfırlama token'ı bulunamadı")
+
+// E-posta adresine kayıtlı kullanıcılar. Aynı adres farklı tenant'larda birden fazla hesapta kullanılabilir
+func GetUsersByEmail(email string) ([]User, error) {
+	return Users().GetByEmail(email)
+}
+
+// Şifre sıfırlama token'ı ekler. Token'ın kendisi değil yalnızca SHA-256 hash'i tutulur
+func CreatePasswordReset(userID int, tokenHash string, expiresAt time.Time) error {
+	_, err := DB.Exec("INSERT INTO password_reset (token_hash, user_id, expires_at, created_at) VALUES (?, ?, ?, ?)",
+		tokenHash, userID, expiresAt.Unix(), time.Now().Unix())
+	return err
+}
+
+// Token'ı kullanıldı olarak işaretleyip kullanıcının şifresini değiştirir ve kullanıcı ID'sini döner.
+// Token bulunamazsa, süresi dolduysa veya daha önce kullanıldıysa ErrPasswordResetNotFound döner. Yeni şifre politikaya
+// uymazsa token kullanılmadan *PasswordPolicyError döner. Kullanıcının kullanılmamış diğer token'ları silinir;
+// bağlantıya erişebildiği için e-posta adresi de doğrulanmış sayılır
+func ResetPassword(tokenHash, password string) (int, error) {
+	now := time.Now().Unix()
+
+	var userID int
+	err := DB.QueryRow("SELECT user_id FROM password_reset WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrPasswordResetNotFound
+		}
+		return 0, err
+	}
+
+	users := Users()
+
+	user, err := users.GetByID(userID, AllTenants)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrPasswordResetNotFound
+		}
+		return 0, err
+	}
+
+	hashedPassword, err := preparePassword(users, DB, password, user)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec("UPDATE password_reset SET used_at = ? WHERE token_hash = ? AND used_at IS NULL AND expires_at > ?", now, tokenHash, now)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if rowsAffected == 0 {
+		// Token eş zamanlı başka bir istekte kullanıldı
+		tx.Rollback()
+		return 0, ErrPasswordResetNotFound
+	}
+
+	if _, err := tx.Exec("DELETE FROM password_reset WHERE user_id = ? AND used_at IS NULL", user.ID); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	// Kullanıcı tablosu PostgreSQL'de olabileceği için şifre token transaction'ından sonra değiştirilir
+	if err := users.SetPassword(user.ID, hashedPassword); err != nil {
+		return 0, err
+	}
+
+	if _, err := users.VerifyEmail(user.ID, user.Email); err != nil {
+		return 0, err
+	}
+
+	return user.ID, recordPasswordHistory(DB, user.ID, hashedPassword)
+}
@@ -0,0 +1,74 @@
+package models_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"example.com/webservice/models"
+)
+
+func TestHashAndCheckPassword(t *testing.T) {
+	hash, err := models.HashPassword("gizli123")
+	if err != nil {
+		t.Fatalf("Şifre hashlenirken hata oluştu: %v", err)
+	}
+
+	if !models.IsPasswordHashed(hash) {
+		t.Errorf("Hash bcrypt formatında değil: %s", hash)
+	}
+
+	if match, legacy := models.CheckPassword(hash, "gizli123"); !match || legacy {
+		t.Errorf("Doğru şifre kabul edilmedi. match: %v, legacy: %v", match, legacy)
+	}
+
+	if match, _ := models.CheckPassword(hash, "yanlis"); match {
+		t.Errorf("Yanlış şifre kabul edildi")
+	}
+
+	// Düz metin (eski) kayıt
+	if match, legacy := models.CheckPassword("admin", "admin"); !match || !legacy {
+		t.Errorf("Düz metin şifre legacy olarak tanınmadı. match: %v, legacy: %v", match, legacy)
+	}
+}
+
+func TestLegacyPasswordIsRehashedOnLogin(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("Test veritabanı açılamadı: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE user (id INTEGER PRIMARY KEY, username TEXT UNIQUE, email TEXT, password TEXT NOT NULL, role TEXT NOT NULL DEFAULT 'user', tenant_id INTEGER NOT NULL DEFAULT 1)`)
+	if err != nil {
+		t.Fatalf("Tablo oluşturulamadı: %v", err)
+	}
+
+	_, err = db.Exec("INSERT INTO user (username, email, password, role) VALUES ('admin', 'admin@test.com', 'admin', 'admin')")
+	if err != nil {
+		t.Fatalf("Kullanıcı eklenemedi: %v", err)
+	}
+
+	models.DB = db
+
+	if _, err := models.GetUserByUsernameAndPassword("admin", "yanlis"); err == nil {
+		t.Errorf("Yanlış şifre ile giriş yapılabildi")
+	}
+
+	if _, err := models.GetUserByUsernameAndPassword("admin", "admin"); err != nil {
+		t.Fatalf("Legacy şifre ile giriş yapılamadı: %v", err)
+	}
+
+	var stored string
+	if err := db.QueryRow("SELECT password FROM user WHERE username = 'admin'").Scan(&stored); err != nil {
+		t.Fatalf("Şifre okunamadı: %v", err)
+	}
+
+	if !models.IsPasswordHashed(stored) {
+		t.Errorf("Legacy şifre yeniden hashlenmedi: %s", stored)
+	}
+
+	if _, err := models.GetUserByUsernameAndPassword("admin", "admin"); err != nil {
+		t.Errorf("Hashlenmiş şifre ile giriş yapılamadı: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Kişi listesinin filtreleri ve sıralaması. Boş alanlar filtre uygulamaz; metin karşılaştırmaları büyük/küçük harf duyarsızdır
+// ve Türkçe harfler için de iki veritabanında aynı sonucu verir (bkz. fold.go)
+type PersonQuery struct {
+	FirstName   string
+	LastName    string
+	Email       string
+	IpAddress   string
+	EmailDomain string
+	// Ad, soyad veya e-posta adresinde geçen metin
+	Search string
+	Sort   []PersonSort
+}
+
+type PersonSort struct {
+	Column string
+	Desc   bool
+}
+
+// Tam eşleşmeyle filtrelenebilen kolonlar (sorgu parametresi kolon adıyla aynıdır)
+var personFilterColumns = []string{"first_name", "last_name", "email", "ip_address"}
+
+// Sıralanabilen kolonlar
+var personSortColumns = map[string]bool{
+	"id":         true,
+	"first_name": true,
+	"last_name":  true,
+	"email":      true,
+	"ip_address": true,
+}
+
+// Sorgu parametrelerini okur, örn. ?last_name=Yılmaz&email_domain=example.com&q=ali&sort=-last_name,first_name.
+// sort'taki alanlar virgülle ayrılır, başındaki - azalan sıralama demektir. Listede olmayan alanlarla sıralama hata döner;
+// tanınmayan parametreler (page, pageSize vb.) yok sayılır
+func ParsePersonQuery(values url.Values) (PersonQuery, error) {
+	query := PersonQuery{
+		FirstName:   strings.TrimSpace(values.Get("first_name")),
+		LastName:    strings.TrimSpace(values.Get("last_name")),
+		Email:       strings.TrimSpace(values.Get("email")),
+		IpAddress:   strings.TrimSpace(values.Get("ip_address")),
+		EmailDomain: strings.TrimPrefix(strings.TrimSpace(values.Get("email_domain")), "@"),
+		Search:      strings.TrimSpace(values.Get("q")),
+	}
+
+	seen := make(map[string]bool)
+
+	for _, field := range strings.Split(values.Get("sort"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		sort := PersonSort{Column: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		if !personSortColumns[sort.Column] {
+			return PersonQuery{}, fmt.Errorf("%s alanına göre sıralanamaz", sort.Column)
+		}
+
+		if seen[sort.Column] {
+			continue
+		}
+		seen[sort.Column] = true
+
+		query.Sort = append(query.Sort, sort)
+	}
+
+	return query, nil
+}
+
+// Filtre veya arama metni verildiyse true döner
+func (q PersonQuery) HasFilters() bool {
+	return q.FirstName != "" || q.LastName != "" || q.Email != "" || q.IpAddress != "" || q.EmailDomain != "" || q.Search != ""
+}
+
+// LIKE desenindeki özel karakterleri kaçırır
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// Filtrelerin " AND ..." ile başlayan SQL koşulu. bind parametreyi sorgunun parametrelerine ekleyip yer tutucusunu döner
+func (q PersonQuery) where(bind func(interface{}) string) string {
+	var b strings.Builder
+
+	values := map[string]string{"first_name": q.FirstName, "last_name": q.LastName, "email": q.Email, "ip_address": q.IpAddress}
+	for _, column := range personFilterColumns {
+		if value := values[column]; value != "" {
+			fmt.Fprintf(&b, " AND fold(%s) = %s", column, bind(foldText(value)))
+		}
+	}
+
+	if q.EmailDomain != "" {
+		fmt.Fprintf(&b, ` AND fold(email) LIKE %s ESCAPE '\'`, bind("%@"+escapeLike(foldText(q.EmailDomain))))
+	}
+
+	if q.Search != "" {
+		pattern := "%" + escapeLike(foldText(q.Search)) + "%"
+		fmt.Fprintf(&b, ` AND (fold(first_name) LIKE %s ESCAPE '\' OR fold(last_name) LIKE %s ESCAPE '\' OR fold(email) LIKE %s ESCAPE '\')`,
+			bind(pattern), bind(pattern), bind(pattern))
+	}
+
+	return b.String()
+}
+
+// ORDER BY ifadesi. Aynı değerli kayıtların sayfalar arasında yer değiştirmemesi için her zaman id ile biter
+func (q PersonQuery) orderBy() string {
+	terms := make([]string, 0, len(q.Sort)+1)
+
+	for _, sort := range q.Sort {
+		// Kolon adları sadece personSortColumns'tan gelir
+		if !personSortColumns[sort.Column] {
+			continue
+		}
+
+		direction := "ASC"
+		if sort.Desc {
+			direction = "DESC"
+		}
+		terms = append(terms, sort.Column+" "+direction)
+
+		if sort.Column == "id" {
+			return " ORDER BY " + strings.Join(terms, ", ")
+		}
+	}
+
+	terms = append(terms, "id ASC")
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Tam metin aramasının bir sonucu. Snippet ad, soyad ve e-postayı eşleşen kelimeler <mark> ile işaretlenmiş olarak içerir
+// (HTML olarak gösterilebilir, kayıttaki metin kaçırılmıştır). Rank büyükse sonuç daha ilgilidir
+type PersonMatch struct {
+	Person
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// Tam metin aramasını destekleyen kişi depoları. Şu an sadece SQLite (FTS5) deposu destekler
+type PersonSearcher interface {
+	// Sonuçlar ilgiye göre sıralanır, toplam eşleşme sayısı ile birlikte döner
+	Search(text string, limit, offset int, scope PersonScope) ([]PersonMatch, int, error)
+}
+
+var ErrSearchNotSupported = errors.New("kullanılan veritabanı tam metin aramasını desteklemiyor")
+
+// Snippet'teki eşleşme işaretleri. highlight() çıktısındaki metin kaçırıldıktan sonra <mark> etiketlerine çevrilir
+const (
+	matchStart = "\x01"
+	matchEnd   = "\x02"
+)
+
+// Arama metnini FTS5 sorgusuna çevirir: her kelime önek olarak aranır ve tüm kelimeler eşleşmelidir, örn. "şük yıl" ->
+// "şük"* "yil"*. Harf ve rakam dışındaki karakterler ayırıcıdır, böylece kullanıcı FTS5 sözdizimi kullanamaz. Aksanları
+// tokenizer yok sayar; aksan sayılmayan ı/İ, people_fts.folded ve folded_email kolonlarındaki gibi i/I'ya çevrilir
+func ftsQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+strings.NewReplacer("ı", "i", "İ", "I").Replace(word)+`"*`)
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// highlight() çıktısındaki işaretleri HTML'e çevirir
+func markMatches(highlighted string) string {
+	return strings.NewReplacer(matchStart, "<mark>", matchEnd, "</mark>").Replace(html.EscapeString(highlighted))
+}
+
+// folded/folded_email kolonunun highlight() çıktısındaki işaretleri özgün metne taşır. Kolon özgün metnin ı/İ harfleri değiştirilmiş
+// halidir ve aynı sayıda karakter içerir
+func unfoldHighlight(highlighted, original string) string {
+	var b strings.Builder
+	source := []rune(original)
+
+	i := 0
+	for _, r := range highlighted {
+		if string(r) == matchStart || string(r) == matchEnd || i >= len(source) {
+			b.WriteRune(r)
+			continue
+		}
+		b.WriteRune(source[i])
+		i++
+	}
+
+	return b.String()
+}
+
+func (r *SQLitePersonRepository) Search(text string, limit, offset int, scope PersonScope) ([]PersonMatch, int, error) {
+	match := ftsQuery(text)
+	if match == "" {
+		return []PersonMatch{}, 0, nil
+	}
+
+	// people_fts'te id kolonu olmadığı için personReadFilter'daki kolonlar people tablosuna aittir
+	from := " FROM people_fts JOIN people ON people.id = people_fts.rowid WHERE people_fts MATCH ? AND tenant_id = ? AND " + personReadFilter
+	args := append([]interface{}{match}, scope.tenantArgs()...)
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*)"+from, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Ad ve soyad eşleşmeleri (folded dahil) e-postadakilerden (folded_email dahil) daha ilgili sayılır. bm25 küçükse sonuç daha ilgilidir
+	rows, err := r.db.Query(`SELECT people.id, people.first_name, people.last_name, people.email, people.ip_address, COALESCE(people.owner_id, 0),
+		highlight(people_fts, 0, ?, ?), highlight(people_fts, 1, ?, ?), highlight(people_fts, 2, ?, ?),
+		COALESCE(highlight(people_fts, 3, ?, ?), ''), COALESCE(highlight(people_fts, 4, ?, ?), ''),
+		bm25(people_fts, 10.0, 10.0, 5.0, 10.0, 5.0) AS score`+from+` ORDER BY score, people.id LIMIT ? OFFSET ?`,
+		append(append([]interface{}{matchStart, matchEnd, matchStart, matchEnd, matchStart, matchEnd, matchStart, matchEnd, matchStart, matchEnd}, args...), limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	matches := make([]PersonMatch, 0)
+
+	for rows.Next() {
+		var m PersonMatch
+		var firstName, lastName, email, folded, foldedEmail string
+		if err := rows.Scan(&m.Id, &m.FirstName, &m.LastName, &m.Email, &m.IpAddress, &m.OwnerID, &firstName, &lastName, &email, &folded, &foldedEmail, &m.Rank); err != nil {
+			return nil, 0, err
+		}
+
+		// folded ve folded_email doluysa (ı/İ içeren ad veya e-posta) o kısmın tüm eşleşmelerini içerir
+		names := firstName + " " + lastName
+		if folded != "" {
+			names = unfoldHighlight(folded, m.FirstName+" "+m.LastName)
+		}
+		if foldedEmail != "" {
+			email = unfoldHighlight(foldedEmail, m.Email)
+		}
+
+		m.Snippet = markMatches(names + " " + email)
+		m.Rank = -m.Rank
+		matches = append(matches, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return matches, total, nil
+}
+
+// @Summary Search persons
+// @Description Full-text search over first name, last name and e-mail address. Every word is matched as a prefix, case and diacritics are ignored (sukru finds Şükrü). Results are ordered by relevance
+// @Tags person
+// @Accept json
+// @Produce json
+// @Param q query string true "Search text"
+// @Param page query int false "Page number for pagination (default is 1)"
+// @Param pageSize query int false "Number of items per page (default is 20)"
+// @Success 200 {object} PersonMatch
+// @Router /api/v1/person/search [get]
+func SearchPersons(text string, limit, offset int, scope PersonScope) ([]PersonMatch, int, error) {
+	searcher, ok := Persons().(PersonSearcher)
+	if !ok {
+		return nil, 0, ErrSearchNotSupported
+	}
+	return searcher.Search(text, limit, offset, scope)
+}
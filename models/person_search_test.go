@@ -0,0 +1,91 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"example.com/webservice/models"
+)
+
+func TestPersonSearch(t *testing.T) {
+	openTestDB(t)
+
+	owner := models.PersonScope{UserID: 1, TenantID: 1}
+	other := models.PersonScope{UserID: 2, TenantID: 1}
+
+	for _, p := range []models.Person{
+		{FirstName: "Şükrü", LastName: "Yılmaz", Email: "sukru@test.com", IpAddress: "127.0.0.1"},
+		{FirstName: "Ayşe", LastName: "Şükrüoğlu", Email: "ayse@test.com", IpAddress: "127.0.0.2"},
+		{FirstName: "Mehmet", LastName: "Kaya", Email: "sukrukaya@test.com", IpAddress: "127.0.0.3"},
+		{FirstName: "<b>Ali</b>", LastName: "Demir", Email: "ali@test.com", IpAddress: "127.0.0.4"},
+	} {
+		if _, err := models.AddPerson(p, owner); err != nil {
+			t.Fatalf("Kişi eklenemedi: %v", err)
+		}
+	}
+
+	search := func(text string, scope models.PersonScope) ([]models.PersonMatch, int) {
+		t.Helper()
+		matches, total, err := models.SearchPersons(text, 20, 0, scope)
+		if err != nil {
+			t.Fatalf("Arama yapılamadı (%q): %v", text, err)
+		}
+		return matches, total
+	}
+
+	// Aksan ve büyük/küçük harf duyarsız önek araması; ad eşleşmesi e-posta eşleşmesinden önce gelir
+	matches, total := search("sukru", owner)
+	if total != 3 || len(matches) != 3 || matches[0].FirstName != "Şükrü" || matches[2].FirstName != "Mehmet" {
+		t.Fatalf("Beklenmeyen sonuçlar: %d, %+v", total, matches)
+	}
+	if matches[0].Snippet != "<mark>Şükrü</mark> Yılmaz <mark>sukru</mark>@test.com" || matches[0].Rank <= matches[2].Rank {
+		t.Errorf("Beklenmeyen snippet veya sıralama: %q, %v", matches[0].Snippet, matches[0].Rank)
+	}
+
+	// Aksan sayılmayan ı harfi her iki yönde eşleşmeli, snippet özgün yazımı korumalı
+	for _, text := range []string{"yilmaz", "YILMAZ", "Şük yıl"} {
+		if matches, _ := search(text, owner); len(matches) != 1 || !strings.Contains(matches[0].Snippet, "<mark>Yılmaz</mark>") {
+			t.Errorf("%q araması Yılmaz'ı bulamadı: %+v", text, matches)
+		}
+	}
+
+	// ı içeren kayıtta e-posta eşleşmesi ad eşleşmesi kadar ağırlık almaz
+	for _, p := range []models.Person{
+		{FirstName: "Işık", LastName: "Kara", Email: "keremcan@test.com", IpAddress: "127.0.0.5"},
+		{FirstName: "Keremcan", LastName: "Demir", Email: "md@test.com", IpAddress: "127.0.0.6"},
+	} {
+		if _, err := models.AddPerson(p, owner); err != nil {
+			t.Fatalf("Kişi eklenemedi: %v", err)
+		}
+	}
+	if matches, _ := search("keremcan", owner); len(matches) != 2 || matches[0].FirstName != "Keremcan" || matches[1].Snippet != "Işık Kara <mark>keremcan</mark>@test.com" {
+		t.Errorf("E-posta eşleşmesi ad eşleşmesinin önüne geçti: %+v", matches)
+	}
+
+	// Kayıttaki HTML kaçırılmalı, FTS5 sözdizimi arama metni olarak yorumlanmamalı
+	if matches, _ := search("ali", owner); len(matches) != 1 || !strings.HasPrefix(matches[0].Snippet, "&lt;b&gt;<mark>Ali</mark>&lt;/b&gt;") {
+		t.Errorf("Snippet kaçırılmadı: %+v", matches)
+	}
+	if matches, _ := search(`ali" OR "kaya NEAR(`, owner); len(matches) != 0 {
+		t.Errorf("Arama metni sorgu olarak yorumlandı: %+v", matches)
+	}
+
+	// Sadece görülebilen kişiler aranır
+	if _, total := search("sukru", other); total != 0 {
+		t.Errorf("Başka kullanıcının kişileri arandı: %d", total)
+	}
+
+	// Dizin kişi güncellendiğinde ve silindiğinde güncellenmeli
+	if updated, err := models.UpdatePerson(models.Person{FirstName: "Mehmet", LastName: "Kaya", Email: "mehmet@test.com", IpAddress: "127.0.0.3"}, 3, owner); !updated || err != nil {
+		t.Fatalf("Kişi güncellenemedi: %v", err)
+	}
+	if deleted, err := models.DeletePerson(2, owner); !deleted || err != nil {
+		t.Fatalf("Kişi silinemedi: %v", err)
+	}
+	if matches, total := search("sukru", owner); total != 1 || matches[0].FirstName != "Şükrü" {
+		t.Errorf("Dizin güncellenmedi: %d, %+v", total, matches)
+	}
+	if _, total := search("mehmet", owner); total != 1 {
+		t.Errorf("Güncellenen kişi bulunamadı: %d", total)
+	}
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+)
+
+// Kişinin sahibine, admin'e veya kişinin doğrudan ya da grup üzerinden paylaşıldığı kullanıcılara görünür olmasını sağlayan koşul.
+// Parametreler PersonScope.tenantArgs() ile verilir
+const personReadFilter = `(? OR owner_id = ? OR id IN (
+	SELECT person_id FROM person_share
+	WHERE user_id = ? OR group_id IN (SELECT group_id FROM user_group_member WHERE user_id = ?)))`
+
+// personReadFilter ile aynı, ancak paylaşımlardan sadece düzenleme izni verilenler dikkate alınır
+const personWriteFilter = `(? OR owner_id = ? OR id IN (
+	SELECT person_id FROM person_share
+	WHERE can_edit = 1 AND (user_id = ? OR group_id IN (SELECT group_id FROM user_group_member WHERE user_id = ?))))`
+
+// "tenant_id = ? AND " + personReadFilter/personWriteFilter koşulunun parametreleri
+func (s PersonScope) tenantArgs() []interface{} {
+	return []interface{}{s.TenantID, s.Admin, s.UserID, s.UserID, s.UserID}
+}
+
+var ErrPersonNotFound = errors.New("kişi bulunamadı")
+
+// Bir kişinin bir kullanıcı veya grupla paylaşımı. UserID ve GroupID'den sadece biri dolu olur
+type PersonShare struct {
+	ID       int  `json:"id" swaggerignore:"true"`
+	PersonID int  `json:"person_id" swaggerignore:"true"`
+	UserID   *int `json:"user_id,omitempty"`
+	GroupID  *int `json:"group_id,omitempty"`
+	CanEdit  bool `json:"can_edit"`
+}
+
+// Kişinin sahibi veya admin ise true döner. Kişi yoksa veya başka bir tenant'a aitse ErrPersonNotFound döner
+func CanManagePerson(personID int, scope PersonScope) (bool, error) {
+	person, err := Persons().Get(strconv.Itoa(personID), PersonScope{TenantID: scope.TenantID, Admin: true})
+	if err != nil {
+		return false, err
+	}
+
+	if person.Id == 0 {
+		return false, ErrPersonNotFound
+	}
+
+	return scope.Admin || (person.OwnerID != 0 && person.OwnerID == scope.UserID), nil
+}
+
+// Kullanıcıyla doğrudan veya grupları üzerinden paylaşılan kişilerin ID'leri. editable true ise sadece düzenleme izni
+// verilen paylaşımlar döner. Paylaşımlar SQLite'ta tutulduğu için PostgreSQL deposu filtreyi bu listeyle uygular.
+// Liste ile kişiler aynı işlemde okunmaz; PostgreSQL kişi ID'lerini yeniden kullanmadığı için silinen bir kişiden kalan
+// paylaşım başka bir kişiye erişim vermez
+func sharedPersonIDs(store *sql.DB, userID int, editable bool) ([]int64, error) {
+	rows, err := store.Query(`SELECT DISTINCT person_id FROM person_share
+		WHERE (? = 0 OR can_edit = 1) AND (user_id = ? OR group_id IN (SELECT group_id FROM user_group_member WHERE user_id = ?))`,
+		editable, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func SharePerson(share PersonShare) (int64, error) {
+	result, err := DB.Exec("INSERT INTO person_share (person_id, user_id, group_id, can_edit) VALUES (?, ?, ?, ?)",
+		share.PersonID, share.UserID, share.GroupID, share.CanEdit)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func GetPersonShares(personID int) ([]PersonShare, error) {
+	rows, err := DB.Query("SELECT id, person_id, user_id, group_id, can_edit FROM person_share WHERE person_id = ?", personID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	shares := make([]PersonShare, 0)
+
+	for rows.Next() {
+		var share PersonShare
+		var userID, groupID sql.NullInt64
+		if err := rows.Scan(&share.ID, &share.PersonID, &userID, &groupID, &share.CanEdit); err != nil {
+			return nil, err
+		}
+
+		share.UserID = nullInt(userID)
+		share.GroupID = nullInt(groupID)
+		shares = append(shares, share)
+	}
+
+	return shares, rows.Err()
+}
+
+func DeletePersonShare(personID, shareID int) error {
+	result, err := DB.Exec("DELETE FROM person_share WHERE id = ? AND person_id = ?", shareID, personID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("paylaşım bulunamadı")
+	}
+
+	return nil
+}
+
+func nullInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+
+	v := int(value.Int64)
+	return &v
+}
@@ -0,0 +1,147 @@
+package models_test
+
+import (
+	"testing"
+
+	"example.com/webservice/models"
+)
+
+// Gerçek şemayla bellekte bir veritabanı açar
+func openTestDB(t *testing.T) {
+	t.Helper()
+
+	if err := models.OpenDatabase(":memory:"); err != nil {
+		t.Fatalf("Test veritabanı açılamadı: %v", err)
+	}
+	models.DB.SetMaxOpenConns(1)
+	t.Cleanup(func() { models.DB.Close() })
+}
+
+func TestPersonOwnershipAndSharing(t *testing.T) {
+	openTestDB(t)
+
+	owner := models.PersonScope{UserID: 1, TenantID: 1}
+	other := models.PersonScope{UserID: 2, TenantID: 1}
+	admin := models.PersonScope{UserID: 3, TenantID: 1, Admin: true}
+
+	if _, err := models.AddPerson(models.Person{FirstName: "Ali", LastName: "Veli", Email: "ali@test.com", IpAddress: "127.0.0.1"}, owner); err != nil {
+		t.Fatalf("Kişi eklenemedi: %v", err)
+	}
+
+	count := func(scope models.PersonScope) int {
+		persons, err := models.GetPersons(20, 0, scope, models.PersonQuery{})
+		if err != nil {
+			t.Fatalf("Kişiler alınamadı: %v", err)
+		}
+		return len(persons)
+	}
+
+	if count(owner) != 1 || count(admin) != 1 || count(other) != 0 {
+		t.Fatalf("Kişi görünürlüğü hatalı. Sahip: %d, Admin: %d, Diğer: %d", count(owner), count(admin), count(other))
+	}
+
+	if updated, _ := models.UpdatePerson(models.Person{FirstName: "X"}, 1, other); updated {
+		t.Errorf("Başka kullanıcının kişisi güncellendi")
+	}
+
+	// Okuma izniyle paylaşım
+	userID := other.UserID
+	if _, err := models.SharePerson(models.PersonShare{PersonID: 1, UserID: &userID}); err != nil {
+		t.Fatalf("Kişi paylaşılamadı: %v", err)
+	}
+
+	if count(other) != 1 {
+		t.Errorf("Paylaşılan kişi görünmüyor")
+	}
+
+	if total, _ := models.GetTotalPersonsCount(other, models.PersonQuery{}); total != 1 {
+		t.Errorf("Paylaşılan kişi sayıma dahil edilmedi: %d", total)
+	}
+
+	if updated, _ := models.UpdatePerson(models.Person{FirstName: "X"}, 1, other); updated {
+		t.Errorf("Sadece okuma izni olan kullanıcı kişiyi güncelledi")
+	}
+
+	// Grup üzerinden düzenleme izniyle paylaşım
+	groupID, err := models.CreateGroup("destek", 1)
+	if err != nil {
+		t.Fatalf("Grup eklenemedi: %v", err)
+	}
+
+	if err := models.AddGroupMember(int(groupID), other.UserID, 1); err != nil {
+		t.Fatalf("Üye eklenemedi: %v", err)
+	}
+
+	gid := int(groupID)
+	if _, err := models.SharePerson(models.PersonShare{PersonID: 1, GroupID: &gid, CanEdit: true}); err != nil {
+		t.Fatalf("Kişi grupla paylaşılamadı: %v", err)
+	}
+
+	if updated, err := models.UpdatePerson(models.Person{FirstName: "Harry", LastName: "Potter"}, 1, other); !updated || err != nil {
+		t.Errorf("Düzenleme izni olan grup üyesi kişiyi güncelleyemedi: %v", err)
+	}
+
+	person, _ := models.GetPersonById("1", owner)
+	if person.FirstName != "Harry" {
+		t.Errorf("Güncelleme kaydedilmedi: %+v", person)
+	}
+
+	// Silme sadece sahibe veya admine açık
+	if deleted, _ := models.DeletePerson(1, other); deleted {
+		t.Errorf("Paylaşılan kullanıcı kişiyi sildi")
+	}
+
+	if deleted, err := models.DeletePerson(1, owner); !deleted || err != nil {
+		t.Errorf("Sahip kişiyi silemedi: %v", err)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	openTestDB(t)
+
+	tenantID, adminID, err := models.CreateTenant("acme", models.User{Username: "acme-admin", Password: "acme1234"})
+	if err != nil {
+		t.Fatalf("Tenant oluşturulamadı: %v", err)
+	}
+
+	if _, _, err := models.CreateTenant("acme", models.User{Username: "acme-admin2", Password: "acme1234"}); err != models.ErrTenantExists {
+		t.Errorf("Aynı isimde ikinci tenant oluşturuldu: %v", err)
+	}
+
+	defaultAdmin := models.PersonScope{UserID: 100, TenantID: models.DefaultTenantID, Admin: true}
+	acmeAdmin := models.PersonScope{UserID: int(adminID), TenantID: int(tenantID), Admin: true}
+
+	if _, err := models.AddPerson(models.Person{FirstName: "Ali", LastName: "Veli", Email: "ali@test.com", IpAddress: "127.0.0.1"}, defaultAdmin); err != nil {
+		t.Fatalf("Kişi eklenemedi: %v", err)
+	}
+
+	// Diğer tenant'ın admin'i kişiyi ID ile bile göremez, güncelleyemez ve silemez
+	if person, _ := models.GetPersonById("1", acmeAdmin); person.FirstName != "" {
+		t.Errorf("Başka tenant'ın kişisi görüntülendi: %+v", person)
+	}
+
+	if total, _ := models.GetTotalPersonsCount(acmeAdmin, models.PersonQuery{}); total != 0 {
+		t.Errorf("Başka tenant'ın kişisi sayıldı: %d", total)
+	}
+
+	if updated, _ := models.UpdatePerson(models.Person{FirstName: "X"}, 1, acmeAdmin); updated {
+		t.Errorf("Başka tenant'ın kişisi güncellendi")
+	}
+
+	if deleted, _ := models.DeletePerson(1, acmeAdmin); deleted {
+		t.Errorf("Başka tenant'ın kişisi silindi")
+	}
+
+	if _, err := models.GetUserByID(int(adminID), models.DefaultTenantID); err == nil {
+		t.Errorf("Başka tenant'ın kullanıcısı görüntülendi")
+	}
+
+	if err := models.DeleteUser(int(adminID), models.DefaultTenantID); err == nil {
+		t.Errorf("Başka tenant'ın kullanıcısı silindi")
+	}
+
+	user, err := models.GetUserByUsernameAndPassword("acme-admin", "acme1234")
+	if err != nil || user.TenantID != int(tenantID) || user.Role != "admin" {
+		t.Errorf("Tenant admin'i beklenen tenant/rol ile giriş yapamadı. Kullanıcı: %+v, Hata: %v", user, err)
+	}
+}
@@ -0,0 +1,200 @@
+package models
+
+import (
+	"errors"
+)
+
+var ErrRoleNotFound = errors.New("rol bulunamadı")
+
+type Role struct {
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	RequireTwoFactor bool   `json:"require_2fa"`
+}
+
+// Bir rolün belirli bir route ve HTTP metodu için yetkisi. Route ve Method "*" olabilir.
+// Condition boş değilse yetki sadece koşul sağlandığında geçerlidir (örn. "self": kullanıcı sadece kendi kaydına erişebilir)
+type Permission struct {
+	ID        int    `json:"id" swaggerignore:"true"`
+	Role      string `json:"role"`
+	Route     string `json:"route"`
+	Method    string `json:"method"`
+	Condition string `json:"condition"`
+}
+
+func GetRoles() ([]Role, error) {
+	rows, err := DB.Query("SELECT name, description, require_2fa FROM role ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	roles := make([]Role, 0)
+
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(&role.Name, &role.Description, &role.RequireTwoFactor); err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func RoleExists(name string) (bool, error) {
+	var count int
+	err := DB.QueryRow("SELECT COUNT(*) FROM role WHERE name = ?", name).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func CreateRole(role Role) error {
+	_, err := DB.Exec("INSERT INTO role (name, description, require_2fa) VALUES (?, ?, ?)", role.Name, role.Description, role.RequireTwoFactor)
+	return err
+}
+
+// Rolü ve rolün tüm yetkilerini siler
+func DeleteRole(name string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec("DELETE FROM role WHERE name = ?", name)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if rowsAffected == 0 {
+		tx.Rollback()
+		return ErrRoleNotFound
+	}
+
+	if _, err := tx.Exec("DELETE FROM role_permission WHERE role = ?", name); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func GetPermissions() ([]Permission, error) {
+	rows, err := DB.Query("SELECT id, role, route, method, condition FROM role_permission ORDER BY role, route, method")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	permissions := make([]Permission, 0)
+
+	for rows.Next() {
+		var p Permission
+		if err := rows.Scan(&p.ID, &p.Role, &p.Route, &p.Method, &p.Condition); err != nil {
+			return nil, err
+		}
+
+		permissions = append(permissions, p)
+	}
+
+	return permissions, rows.Err()
+}
+
+func AddPermission(p Permission) (int64, error) {
+	exists, err := RoleExists(p.Role)
+	if err != nil {
+		return 0, err
+	}
+
+	if !exists {
+		return 0, ErrRoleNotFound
+	}
+
+	result, err := DB.Exec("INSERT INTO role_permission (role, route, method, condition) VALUES (?, ?, ?, ?)", p.Role, p.Route, p.Method, p.Condition)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+func DeletePermission(id int) error {
+	result, err := DB.Exec("DELETE FROM role_permission WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("yetki bulunamadı")
+	}
+
+	return nil
+}
+
+// Varsayılan politikayı veritabanına uygular. Yetki tablosu boşsa verilen rolleri ve yetkileri ekler. Tablo doluysa sadece
+// daha önce hiç eklenmemiş (policy_default'ta kaydı olmayan) yetkileri, rolü varsa ekler. Böylece yeni sürümlerin varsayılan
+// yetkileri mevcut veritabanlarına da ulaşır, admin endpoint'leri ile silinen veya eklenen yetkiler ise yeniden başlatmada ezilmez
+func SyncPolicy(roles []Role, permissions []Permission) error {
+	var count int
+	if err := DB.QueryRow("SELECT COUNT(*) FROM role_permission").Scan(&count); err != nil {
+		return err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		for _, role := range roles {
+			if _, err := tx.Exec("INSERT OR IGNORE INTO role (name, description, require_2fa) VALUES (?, ?, ?)", role.Name, role.Description, role.RequireTwoFactor); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
+	for _, p := range permissions {
+		result, err := tx.Exec(`INSERT OR IGNORE INTO policy_default (role, route, method, condition)
+			SELECT ?, ?, ?, ? WHERE EXISTS (SELECT 1 FROM role WHERE name = ?)`, p.Role, p.Route, p.Method, p.Condition, p.Role)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		added, err := result.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		if added == 0 {
+			continue
+		}
+
+		if _, err := tx.Exec("INSERT OR IGNORE INTO role_permission (role, route, method, condition) VALUES (?, ?, ?, ?)", p.Role, p.Route, p.Method, p.Condition); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
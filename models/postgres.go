@@ -0,0 +1,485 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL'de sadece kişi ve kullanıcı tabloları tutulur (bkz. migrations/postgres)
+var postgresDB *sql.DB
+
+func isPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") || strings.Contains(dsn, "host=")
+}
+
+// Kişi ve kullanıcı depolarını PostgreSQL'e taşır. Diğer tablolar (paylaşımlar, gruplar, oturumlar vb.) global DB'de
+// kalır, bu yüzden OpenDatabase'den sonra çağrılmalıdır
+func OpenPostgres(dsn string) error {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return err
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return err
+	}
+
+	if autoMigrate {
+		migrator, err := newPostgresMigrator(db)
+		if err != nil {
+			db.Close()
+			return err
+		}
+
+		if err := migrator.Up(); err != nil {
+			db.Close()
+			return err
+		}
+	}
+
+	postgresDB = db
+	personStore = NewPostgresPersonRepository(db, DB)
+	userStore = NewPostgresUserRepository(db, DB)
+
+	return nil
+}
+
+// PostgreSQL'in unique_violation hatasını verilen hataya çevirir
+func postgresUniqueError(err, unique error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return unique
+	}
+
+	return err
+}
+
+// PersonRepository'nin PostgreSQL gerçeklemesi. Paylaşımlar store'daki (SQLite) person_share tablosundan okunur
+type PostgresPersonRepository struct {
+	db    *sql.DB
+	store *sql.DB
+}
+
+func NewPostgresPersonRepository(db, store *sql.DB) *PostgresPersonRepository {
+	return &PostgresPersonRepository{db: db, store: store}
+}
+
+// UserRepository'nin PostgreSQL gerçeklemesi. Şifre geçmişi ve kullanıcıya bağlı diğer kayıtlar store'da tutulur
+type PostgresUserRepository struct {
+	db    *sql.DB
+	store *sql.DB
+}
+
+func NewPostgresUserRepository(db, store *sql.DB) *PostgresUserRepository {
+	return &PostgresUserRepository{db: db, store: store}
+}
+
+// personReadFilter/personWriteFilter'ın PostgreSQL karşılığı. Koşul args'tan sonraki parametre numaralarını kullanır
+func (r *PostgresPersonRepository) scopeFilter(scope PersonScope, editable bool, args []interface{}) (string, []interface{}, error) {
+	shared := []int64{}
+	if !scope.Admin {
+		ids, err := sharedPersonIDs(r.store, scope.UserID, editable)
+		if err != nil {
+			return "", nil, err
+		}
+		shared = ids
+	}
+
+	n := len(args)
+	filter := fmt.Sprintf("tenant_id = $%d AND ($%d OR owner_id = $%d OR id = ANY($%d))", n+1, n+2, n+3, n+4)
+
+	return filter, append(args, scope.TenantID, scope.Admin, scope.UserID, pq.Array(shared)), nil
+}
+
+// PersonQuery.where için parametreyi args'a ekleyip PostgreSQL yer tutucusunu ($n) döner
+func postgresBind(args *[]interface{}) func(interface{}) string {
+	return func(value interface{}) string {
+		*args = append(*args, value)
+		return "$" + strconv.Itoa(len(*args))
+	}
+}
+
+func (r *PostgresPersonRepository) List(limit, offset int, scope PersonScope, filters PersonQuery) ([]Person, error) {
+	filter, args, err := r.scopeFilter(scope, false, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT id, first_name, last_name, email, ip_address, COALESCE(owner_id, 0) FROM people WHERE %s%s%s LIMIT %d OFFSET %d",
+		filter, filters.where(postgresBind(&args)), filters.orderBy(), limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	people := make([]Person, 0)
+
+	for rows.Next() {
+		var person Person
+		if err := rows.Scan(&person.Id, &person.FirstName, &person.LastName, &person.Email, &person.IpAddress, &person.OwnerID); err != nil {
+			return nil, err
+		}
+
+		people = append(people, person)
+	}
+
+	return people, rows.Err()
+}
+
+func (r *PostgresPersonRepository) Count(scope PersonScope, filters PersonQuery) (int, error) {
+	filter, args, err := r.scopeFilter(scope, false, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM people WHERE "+filter+filters.where(postgresBind(&args)), args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PostgresPersonRepository) Get(id string, scope PersonScope) (Person, error) {
+	personID, err := strconv.Atoi(id)
+	if err != nil {
+		return Person{}, nil
+	}
+
+	filter, args, err := r.scopeFilter(scope, false, []interface{}{personID})
+	if err != nil {
+		return Person{}, err
+	}
+
+	var person Person
+	err = r.db.QueryRow("SELECT id, first_name, last_name, email, ip_address, COALESCE(owner_id, 0) FROM people WHERE id = $1 AND "+filter, args...).
+		Scan(&person.Id, &person.FirstName, &person.LastName, &person.Email, &person.IpAddress, &person.OwnerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Person{}, nil
+		}
+		return Person{}, err
+	}
+
+	return person, nil
+}
+
+func (r *PostgresPersonRepository) Add(newPerson Person, scope PersonScope) (bool, error) {
+	var id int
+	err := r.db.QueryRow("INSERT INTO people (first_name, last_name, email, ip_address, owner_id, tenant_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		newPerson.FirstName, newPerson.LastName, newPerson.Email, newPerson.IpAddress, scope.UserID, scope.TenantID).Scan(&id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *PostgresPersonRepository) Update(ourPerson Person, id int, scope PersonScope) (bool, error) {
+	args := []interface{}{ourPerson.FirstName, ourPerson.LastName, ourPerson.Email, ourPerson.IpAddress, id}
+	filter, args, err := r.scopeFilter(scope, true, args)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := r.db.Exec("UPDATE people SET first_name = $1, last_name = $2, email = $3, ip_address = $4 WHERE id = $5 AND "+filter, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *PostgresPersonRepository) Delete(personId int, scope PersonScope) (bool, error) {
+	// Paylaşılan kişiler sadece sahibi veya admin tarafından silinebilir
+	result, err := r.db.Exec("DELETE FROM people WHERE id = $1 AND tenant_id = $2 AND ($3 OR owner_id = $4)", personId, scope.TenantID, scope.Admin, scope.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	if _, err := r.store.Exec("DELETE FROM person_share WHERE person_id = ?", personId); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func scanPostgresUsers(rows *sql.Rows) ([]User, error) {
+	defer rows.Close()
+
+	users := make([]User, 0)
+
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.TenantID); err != nil {
+			return nil, err
+		}
+
+		user.Password = "*****"
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *PostgresUserRepository) List(limit, offset, tenantID int) ([]User, error) {
+	rows, err := r.db.Query("SELECT id, username, email, role, tenant_id FROM users WHERE tenant_id = $1 ORDER BY id LIMIT $2 OFFSET $3", tenantID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPostgresUsers(rows)
+}
+
+func (r *PostgresUserRepository) Count(tenantID int) (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM users WHERE tenant_id = $1", tenantID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *PostgresUserRepository) GetByID(userID, tenantID int) (User, error) {
+	var user User
+	err := r.db.QueryRow("SELECT id, username, email, role, tenant_id FROM users WHERE id = $1 AND ($2 = 0 OR tenant_id = $2)", userID, tenantID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.TenantID)
+	if err != nil {
+		return User{}, err
+	}
+
+	user.Password = "*****"
+	return user, nil
+}
+
+func (r *PostgresUserRepository) GetByUsername(username string) (User, error) {
+	var user User
+	err := r.db.QueryRow("SELECT id, username, email, role, tenant_id FROM users WHERE username = $1", username).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.TenantID)
+	if err != nil {
+		return User{}, err
+	}
+
+	user.Password = "*****"
+	return user, nil
+}
+
+func (r *PostgresUserRepository) GetByEmail(email string) ([]User, error) {
+	rows, err := r.db.Query("SELECT id, username, email, role, tenant_id FROM users WHERE lower(email) = lower($1)", email)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanPostgresUsers(rows)
+}
+
+func (r *PostgresUserRepository) Create(newUser User) (int64, error) {
+	return createUser(r, r.store, newUser)
+}
+
+func (r *PostgresUserRepository) Update(updatedUser User) error {
+	if updatedUser.Role == "" {
+		updatedUser.Role = "user"
+	}
+
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM users WHERE id = $1 AND tenant_id = $2", updatedUser.ID, updatedUser.TenantID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("kullanici bulunamadi")
+	}
+
+	query := "UPDATE users SET username = $1, email = $2, role = $3"
+	args := []interface{}{updatedUser.Username, updatedUser.Email, updatedUser.Role}
+
+	var hashedPassword string
+	if updatedUser.Password != "" {
+		hashedPassword, err = preparePassword(r, r.store, updatedUser.Password, updatedUser)
+		if err != nil {
+			return err
+		}
+
+		args = append(args, hashedPassword)
+		query += fmt.Sprintf(", password = $%d", len(args))
+	}
+
+	args = append(args, updatedUser.ID)
+	query += fmt.Sprintf(" WHERE id = $%d", len(args))
+
+	if _, err := r.db.Exec(query, args...); err != nil {
+		return postgresUniqueError(err, ErrUsernameTaken)
+	}
+
+	if hashedPassword != "" {
+		return recordPasswordHistory(r.store, updatedUser.ID, hashedPassword)
+	}
+
+	return nil
+}
+
+func (r *PostgresUserRepository) Delete(userID, tenantID int) error {
+	deleted, err := r.deleteUsers("DELETE FROM users WHERE id = $1 AND tenant_id = $2 RETURNING id", userID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errors.New("kullanici bulunamadi")
+	}
+
+	return nil
+}
+
+// Kullanıcı satırları PostgreSQL'de, bağlı kayıtlar store'da olduğu için iki veritabanını kapsayan bir işlem yoktur.
+// Kullanıcılar önce PostgreSQL işleminde silinip kişileri sahipsiz bırakılır (satırlar commit'e kadar kilitli kalır),
+// bağlı kayıtlar store işleminde silinip commit edilir, en son PostgreSQL işlemi commit edilir. Store tarafı başarısız
+// olursa kullanıcılar silinmez.
+// Sadece son commit başarısız olursa kullanıcı kalır ama oturumları, anahtarları ve paylaşımları silinmiş olur;
+// kullanıcı tekrar giriş yapabilir ve silme tekrar denenebilir
+func (r *PostgresUserRepository) deleteUsers(query string, args ...interface{}) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	ids, err := scanIDs(rows)
+	if err != nil || len(ids) == 0 {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Silinen kullanıcıların kişileri sahipsiz kalır; sadece adminler ve paylaşılan kullanıcılar görür
+	if _, err := tx.Exec("UPDATE people SET owner_id = NULL WHERE owner_id = ANY($1)", pq.Array(ids)); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	storeTx, err := r.store.Begin()
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := deleteUserData(storeTx, ids); err != nil {
+		storeTx.Rollback()
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := storeTx.Commit(); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	return len(ids), tx.Commit()
+}
+
+func (r *PostgresUserRepository) Authenticate(username, password string) (User, error) {
+	var user User
+	err := r.db.QueryRow("SELECT id, username, email, password, role, tenant_id FROM users WHERE username = $1 LIMIT 1", username).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.TenantID)
+	return checkLogin(r, user, err, password)
+}
+
+func (r *PostgresUserRepository) UsernameExists(username string) (bool, error) {
+	var exists bool
+	if err := r.db.QueryRow("SELECT EXISTS (SELECT 1 FROM users WHERE username = $1)", username).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *PostgresUserRepository) Insert(user User, hashedPassword string, emailVerified bool) (int64, error) {
+	var id int64
+	err := r.db.QueryRow("INSERT INTO users (username, email, password, role, tenant_id, email_verified, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		user.Username, user.Email, hashedPassword, user.Role, user.TenantID, emailVerified, time.Now().Unix()).Scan(&id)
+	if err != nil {
+		return 0, postgresUniqueError(err, ErrUsernameTaken)
+	}
+
+	return id, nil
+}
+
+func (r *PostgresUserRepository) PasswordHash(userID int) (string, error) {
+	var hash string
+	err := r.db.QueryRow("SELECT password FROM users WHERE id = $1", userID).Scan(&hash)
+	return hash, err
+}
+
+func (r *PostgresUserRepository) SetPassword(userID int, hashedPassword string) error {
+	result, err := r.db.Exec("UPDATE users SET password = $1 WHERE id = $2", hashedPassword, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, errors.New("kullanici bulunamadi"))
+}
+
+func (r *PostgresUserRepository) SetRole(userID int, role string) error {
+	result, err := r.db.Exec("UPDATE users SET role = $1 WHERE id = $2", role, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, errors.New("kullanici bulunamadi"))
+}
+
+func (r *PostgresUserRepository) IsEmailVerified(userID int) (bool, error) {
+	var verified bool
+	if err := r.db.QueryRow("SELECT email_verified FROM users WHERE id = $1", userID).Scan(&verified); err != nil {
+		return false, err
+	}
+
+	return verified, nil
+}
+
+func (r *PostgresUserRepository) VerifyEmail(userID int, email string) (bool, error) {
+	result, err := r.db.Exec("UPDATE users SET email_verified = TRUE WHERE id = $1 AND email = $2", userID, email)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *PostgresUserRepository) DeleteUnverified(before time.Time) (int, error) {
+	return r.deleteUsers("DELETE FROM users WHERE NOT email_verified AND created_at < $1 RETURNING id", before.Unix())
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrRefreshTokenNotFound = errors.New("refresh token bulunamadı")
+
+// Sunucu tarafında saklanan refresh token. Token'ın kendisi değil yalnızca SHA-256 hash'i tutulur
+type RefreshToken struct {
+	ID        int
+	TokenHash string
+	UserID    int
+	FamilyID  string
+	Scopes    []string
+	ClientID  string // OAuth istemcisine verilen token'larda istemcinin ID'si
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+func CreateRefreshToken(token RefreshToken) error {
+	_, err := DB.Exec("INSERT INTO refresh_token (token_hash, user_id, family_id, scopes, client_id, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		token.TokenHash, token.UserID, token.FamilyID, strings.Join(token.Scopes, " "), token.ClientID, token.ExpiresAt.Unix(), time.Now().Unix())
+	return err
+}
+
+func GetRefreshTokenByHash(tokenHash string) (RefreshToken, error) {
+	var token RefreshToken
+	var scopes string
+	var expiresAt, createdAt int64
+	var usedAt, revokedAt sql.NullInt64
+
+	err := DB.QueryRow("SELECT id, token_hash, user_id, family_id, scopes, client_id, expires_at, created_at, used_at, revoked_at FROM refresh_token WHERE token_hash = ?", tokenHash).
+		Scan(&token.ID, &token.TokenHash, &token.UserID, &token.FamilyID, &scopes, &token.ClientID, &expiresAt, &createdAt, &usedAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return RefreshToken{}, ErrRefreshTokenNotFound
+		}
+		return RefreshToken{}, err
+	}
+
+	token.Scopes = strings.Fields(scopes)
+	token.ExpiresAt = time.Unix(expiresAt, 0)
+	token.CreatedAt = time.Unix(createdAt, 0)
+	token.UsedAt = nullUnixTime(usedAt)
+	token.RevokedAt = nullUnixTime(revokedAt)
+
+	return token, nil
+}
+
+// Token'ı kullanıldı olarak işaretler. Token daha önce kullanılmışsa veya iptal edildiyse false döner;
+// aynı token ile eş zamanlı iki yenileme isteğinden yalnızca biri başarılı olur
+func MarkRefreshTokenUsed(id int) (bool, error) {
+	result, err := DB.Exec("UPDATE refresh_token SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL", time.Now().Unix(), id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// Aynı login'den türeyen tüm refresh token'ları ve login'in oturum kaydını iptal eder
+func RevokeRefreshTokenFamily(familyID string) error {
+	now := time.Now().Unix()
+
+	if _, err := DB.Exec("UPDATE refresh_token SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL", now, familyID); err != nil {
+		return err
+	}
+
+	_, err := DB.Exec("UPDATE user_session SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", now, familyID)
+	return err
+}
+
+func nullUnixTime(value sql.NullInt64) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	t := time.Unix(value.Int64, 0)
+	return &t
+}
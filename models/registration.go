@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrUsernameTaken = errors.New("kullanıcı adı alınmış")
+
+// Kendi kendine kayıt olan kullanıcıyı e-posta adresi doğrulanmamış (pasif) olarak oluşturur. Ön kontrol şifre hash'lenmeden
+// önce alınmış kullanıcı adlarını eler; aynı adla eş zamanlı kayıtlarda UNIQUE kısıtı ihlali de ErrUsernameTaken döner
+func RegisterUser(newUser User) (int64, error) {
+	if newUser.TenantID == AllTenants {
+		return 0, errors.New("kullanıcı için tenant belirtilmedi")
+	}
+
+	users := Users()
+
+	exists, err := users.UsernameExists(newUser.Username)
+	if err != nil {
+		return 0, err
+	}
+
+	if exists {
+		return 0, ErrUsernameTaken
+	}
+
+	newUser.ID = 0
+	hashedPassword, err := preparePassword(users, DB, newUser.Password, newUser)
+	if err != nil {
+		return 0, err
+	}
+
+	newUser.Role = "user"
+	id, err := users.Insert(newUser, hashedPassword, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, recordPasswordHistory(DB, int(id), hashedPassword)
+}
+
+func IsEmailVerified(userID int) (bool, error) {
+	return Users().IsEmailVerified(userID)
+}
+
+// Kullanıcıyı etkinleştirir. E-posta adresi doğrulama bağlantısı gönderildikten sonra değiştiyse false döner
+func VerifyUserEmail(userID int, email string) (bool, error) {
+	return Users().VerifyEmail(userID, email)
+}
+
+// before'dan önce kayıt olup e-posta adresini doğrulamayan hesapları siler, böylece kullanıcı adları süresiz tutulamaz
+func DeleteExpiredRegistrations(before time.Time) (int, error) {
+	return Users().DeleteUnverified(before)
+}
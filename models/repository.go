@@ -0,0 +1,668 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Kişi kayıtlarının deposu. Tüm işlemler PersonScope ile isteği yapan kullanıcının görebildiği kayıtlarla sınırlandırılır
+type PersonRepository interface {
+	// filters boşsa tüm kayıtlar id sırasıyla döner
+	List(limit, offset int, scope PersonScope, filters PersonQuery) ([]Person, error)
+	Count(scope PersonScope, filters PersonQuery) (int, error)
+	// Kişi bulunamazsa boş Person ve nil hata döner
+	Get(id string, scope PersonScope) (Person, error)
+	Add(person Person, scope PersonScope) (bool, error)
+	// Kişi bulunamazsa veya kullanıcının düzenleme izni yoksa false döner
+	Update(person Person, id int, scope PersonScope) (bool, error)
+	// Kişi bulunamazsa veya kullanıcı sahibi (ya da admin) değilse false döner
+	Delete(id int, scope PersonScope) (bool, error)
+}
+
+// Kullanıcı kayıtlarının deposu. Tüm işlemler verilen tenant ile sınırlandırılır; GetByID'de AllTenants tüm tenant'larda arar.
+// Kullanıcı döndüren sorgularda şifre alanı maskelenir
+type UserRepository interface {
+	List(limit, offset, tenantID int) ([]User, error)
+	Count(tenantID int) (int, error)
+	// Kullanıcı bulunamazsa sql.ErrNoRows döner
+	GetByID(userID, tenantID int) (User, error)
+	GetByUsername(username string) (User, error)
+	// E-posta adresi büyük/küçük harf duyarsız karşılaştırılır
+	GetByEmail(email string) ([]User, error)
+	// Şifre politikaya uymazsa *PasswordPolicyError, kullanıcı adı alınmışsa ErrUsernameTaken döner
+	Create(user User) (int64, error)
+	Update(user User) error
+	Delete(userID, tenantID int) error
+
+	// Giriş ve hesap akışlarının kullandığı işlemler
+	Authenticate(username, password string) (User, error)
+	UsernameExists(username string) (bool, error)
+	// Hash'lenmiş şifreyle kullanıcıyı rolüyle birlikte ekler, şifre politikası uygulanmaz
+	Insert(user User, hashedPassword string, emailVerified bool) (int64, error)
+	PasswordHash(userID int) (string, error)
+	SetPassword(userID int, hashedPassword string) error
+	SetRole(userID int, role string) error
+	IsEmailVerified(userID int) (bool, error)
+	// Adres doğrulama bağlantısı gönderildikten sonra değiştiyse false döner
+	VerifyEmail(userID int, email string) (bool, error)
+	// before'dan önce kayıt olup e-posta adresini doğrulamayan kullanıcıları verileriyle birlikte siler, silinen sayısını döner
+	DeleteUnverified(before time.Time) (int, error)
+}
+
+// PersonRepository'nin SQLite (people tablosu) gerçeklemesi
+type SQLitePersonRepository struct {
+	db *sql.DB
+}
+
+func NewSQLitePersonRepository(db *sql.DB) *SQLitePersonRepository {
+	return &SQLitePersonRepository{db: db}
+}
+
+// UserRepository'nin SQLite (user tablosu) gerçeklemesi
+type SQLiteUserRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteUserRepository(db *sql.DB) *SQLiteUserRepository {
+	return &SQLiteUserRepository{db: db}
+}
+
+func (r *SQLitePersonRepository) List(limit, offset int, scope PersonScope, filters PersonQuery) ([]Person, error) {
+	args := scope.tenantArgs()
+	query := fmt.Sprintf("SELECT id, first_name, last_name, email, ip_address, COALESCE(owner_id, 0) FROM people WHERE tenant_id = ? AND %s%s%s LIMIT %d OFFSET %d",
+		personReadFilter, filters.where(sqliteBind(&args)), filters.orderBy(), limit, offset)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	people := make([]Person, 0)
+
+	for rows.Next() {
+		singlePerson := Person{}
+		err = rows.Scan(&singlePerson.Id, &singlePerson.FirstName, &singlePerson.LastName, &singlePerson.Email, &singlePerson.IpAddress, &singlePerson.OwnerID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		people = append(people, singlePerson)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return people, err
+}
+
+func (r *SQLitePersonRepository) Count(scope PersonScope, filters PersonQuery) (int, error) {
+	var count int
+	args := scope.tenantArgs()
+	query := "SELECT COUNT(*) FROM people WHERE tenant_id = ? AND " + personReadFilter + filters.where(sqliteBind(&args))
+
+	err := r.db.QueryRow(query, args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *SQLitePersonRepository) Get(id string, scope PersonScope) (Person, error) {
+	stmt, err := r.db.Prepare("SELECT id, first_name, last_name, email, ip_address, COALESCE(owner_id, 0) FROM people WHERE id = ? AND tenant_id = ? AND " + personReadFilter)
+
+	if err != nil {
+		return Person{}, err
+	}
+
+	defer stmt.Close()
+
+	person := Person{}
+
+	args := append([]interface{}{id}, scope.tenantArgs()...)
+	sqlErr := stmt.QueryRow(args...).Scan(&person.Id, &person.FirstName, &person.LastName, &person.Email, &person.IpAddress, &person.OwnerID)
+
+	if sqlErr != nil {
+		if sqlErr == sql.ErrNoRows {
+			return Person{}, nil
+		}
+		return Person{}, sqlErr
+	}
+	return person, nil
+}
+
+func (r *SQLitePersonRepository) Add(newPerson Person, scope PersonScope) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO people (first_name, last_name, email, ip_address, owner_id, tenant_id) VALUES (?, ?, ?, ?, ?, ?)")
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(newPerson.FirstName, newPerson.LastName, newPerson.Email, newPerson.IpAddress, scope.UserID, scope.TenantID)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func (r *SQLitePersonRepository) Update(ourPerson Person, id int, scope PersonScope) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	args := append([]interface{}{id}, scope.tenantArgs()...)
+	err = tx.QueryRow("SELECT COUNT(*) FROM people WHERE id = ? AND tenant_id = ? AND "+personWriteFilter, args...).Scan(&count)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if count == 0 {
+		tx.Rollback()
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("UPDATE people SET first_name = ?, last_name = ?, email = ?, ip_address = ? WHERE Id = ?")
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(ourPerson.FirstName, ourPerson.LastName, ourPerson.Email, ourPerson.IpAddress, id)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func (r *SQLitePersonRepository) Delete(personId int, scope PersonScope) (bool, error) {
+	tx, err := r.db.Begin()
+
+	if err != nil {
+		return false, err
+	}
+
+	// Paylaşılan kişiler sadece sahibi veya admin tarafından silinebilir
+	var count int
+	err = tx.QueryRow("SELECT COUNT(*) FROM people WHERE id = ? AND tenant_id = ? AND (? OR owner_id = ?)", personId, scope.TenantID, scope.Admin, scope.UserID).Scan(&count)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if count == 0 {
+		tx.Rollback()
+		return false, err
+	}
+
+	stmt, err := tx.Prepare("DELETE from people WHERE id = ?")
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	defer stmt.Close()
+
+	_, err = stmt.Exec(personId)
+
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM person_share WHERE person_id = ?", personId); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	tx.Commit()
+
+	return true, nil
+}
+
+func (r *SQLiteUserRepository) List(limit, offset, tenantID int) ([]User, error) {
+
+	query := fmt.Sprintf("SELECT id, username, email, '*****' AS password, role, tenant_id FROM user WHERE tenant_id = ? LIMIT %d OFFSET %d", limit, offset)
+
+	rows, err := r.db.Query(query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users := make([]User, 0)
+
+	for rows.Next() {
+		var user User
+		err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.TenantID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *SQLiteUserRepository) Count(tenantID int) (int, error) {
+	var count int
+	query := "SELECT COUNT(*) FROM user WHERE tenant_id = ?"
+
+	err := r.db.QueryRow(query, tenantID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *SQLiteUserRepository) GetByID(userID, tenantID int) (User, error) {
+	var user User
+	err := r.db.QueryRow("SELECT id, username, email, '*****' AS password, role, tenant_id FROM user WHERE id = ? AND (? = 0 OR tenant_id = ?)", userID, tenantID, tenantID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.TenantID)
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+func (r *SQLiteUserRepository) GetByUsername(username string) (User, error) {
+	var user User
+	err := r.db.QueryRow("SELECT id, username, email, '*****' AS password, role, tenant_id FROM user WHERE username = ?", username).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.TenantID)
+	if err != nil {
+		return User{}, err
+	}
+	return user, nil
+}
+
+func (r *SQLiteUserRepository) GetByEmail(email string) ([]User, error) {
+	rows, err := r.db.Query("SELECT id, username, email, '*****' AS password, role, tenant_id FROM user WHERE email = ? COLLATE NOCASE", email)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users := make([]User, 0)
+
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.TenantID); err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *SQLiteUserRepository) Create(newUser User) (int64, error) {
+	return createUser(r, r.db, newUser)
+}
+
+func (r *SQLiteUserRepository) Update(updatedUser User) error {
+	if updatedUser.Role == "" {
+		updatedUser.Role = "user"
+	}
+
+	var count int
+	err := r.db.QueryRow("SELECT COUNT(*) FROM user WHERE id = ? AND tenant_id = ?", updatedUser.ID, updatedUser.TenantID).Scan(&count)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("kullanici bulunamadi")
+	}
+
+	query := "UPDATE user SET username = ?, email = ?, role = ?"
+	var args []interface{}
+	args = append(args, updatedUser.Username, updatedUser.Email, updatedUser.Role)
+
+	var hashedPassword string
+	if updatedUser.Password != "" {
+		hashedPassword, err = preparePassword(r, r.db, updatedUser.Password, updatedUser)
+		if err != nil {
+			return err
+		}
+
+		query += ", password = ?"
+		args = append(args, hashedPassword)
+	}
+
+	query += " WHERE id = ?"
+	args = append(args, updatedUser.ID)
+
+	_, err = r.db.Exec(query, args...)
+	if err != nil {
+		return sqliteUniqueError(err, ErrUsernameTaken)
+	}
+
+	if hashedPassword != "" {
+		return recordPasswordHistory(r.db, updatedUser.ID, hashedPassword)
+	}
+
+	return nil
+}
+
+func (r *SQLiteUserRepository) Delete(userID, tenantID int) error {
+	deleted, err := r.deleteUsers("DELETE FROM user WHERE id = ? AND tenant_id = ? RETURNING id", userID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errors.New("kullanici bulunamadi")
+	}
+
+	return nil
+}
+
+// Kullanıcıları ve bağlı kayıtlarını tek işlemde siler, silinen kullanıcı sayısını döner
+func (r *SQLiteUserRepository) deleteUsers(query string, args ...interface{}) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	ids, err := scanIDs(rows)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err := deleteUserData(tx, ids); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	// Silinen kullanıcıların kişileri sahipsiz kalır; sadece adminler ve paylaşılan kullanıcılar görür
+	for _, id := range ids {
+		if _, err := tx.Exec("UPDATE people SET owner_id = NULL WHERE owner_id = ?", id); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+
+	return len(ids), tx.Commit()
+}
+
+func (r *SQLiteUserRepository) Authenticate(username, password string) (User, error) {
+	var user User
+	query := "SELECT id, username, email, password, role, tenant_id FROM user WHERE username = ? LIMIT 1"
+
+	err := r.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.TenantID)
+	return checkLogin(r, user, err, password)
+}
+
+func (r *SQLiteUserRepository) UsernameExists(username string) (bool, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM user WHERE username = ?", username).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *SQLiteUserRepository) Insert(user User, hashedPassword string, emailVerified bool) (int64, error) {
+	// email_verified kolonunun varsayılanı doğrulanmıştır
+	query := "INSERT INTO user (username, email, password, role, tenant_id, created_at) VALUES (?, ?, ?, ?, ?, ?)"
+	if !emailVerified {
+		query = "INSERT INTO user (username, email, password, role, tenant_id, created_at, email_verified) VALUES (?, ?, ?, ?, ?, ?, 0)"
+	}
+
+	result, err := r.db.Exec(query, user.Username, user.Email, hashedPassword, user.Role, user.TenantID, time.Now().Unix())
+	if err != nil {
+		return 0, sqliteUniqueError(err, ErrUsernameTaken)
+	}
+
+	return result.LastInsertId()
+}
+
+func (r *SQLiteUserRepository) PasswordHash(userID int) (string, error) {
+	var hash string
+	err := r.db.QueryRow("SELECT password FROM user WHERE id = ?", userID).Scan(&hash)
+	return hash, err
+}
+
+func (r *SQLiteUserRepository) SetPassword(userID int, hashedPassword string) error {
+	result, err := r.db.Exec("UPDATE user SET password = ? WHERE id = ?", hashedPassword, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, errors.New("kullanici bulunamadi"))
+}
+
+func (r *SQLiteUserRepository) SetRole(userID int, role string) error {
+	result, err := r.db.Exec("UPDATE user SET role = ? WHERE id = ?", role, userID)
+	if err != nil {
+		return err
+	}
+
+	return expectOneRow(result, errors.New("kullanici bulunamadi"))
+}
+
+func (r *SQLiteUserRepository) IsEmailVerified(userID int) (bool, error) {
+	var verified bool
+	if err := r.db.QueryRow("SELECT email_verified FROM user WHERE id = ?", userID).Scan(&verified); err != nil {
+		return false, err
+	}
+
+	return verified, nil
+}
+
+func (r *SQLiteUserRepository) VerifyEmail(userID int, email string) (bool, error) {
+	result, err := r.db.Exec("UPDATE user SET email_verified = 1 WHERE id = ? AND email = ?", userID, email)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Create'in ortak kısmı: rol her zaman "user" olur, şifre politikası uygulanır ve şifre geçmişi store'a yazılır
+func createUser(users UserRepository, store *sql.DB, newUser User) (int64, error) {
+	newUser.Role = "user"
+
+	if newUser.TenantID == AllTenants {
+		return 0, errors.New("kullanıcı için tenant belirtilmedi")
+	}
+
+	newUser.ID = 0
+	hashedPassword, err := preparePassword(users, store, newUser.Password, newUser)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := users.Insert(newUser, hashedPassword, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := recordPasswordHistory(store, int(id), hashedPassword); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Authenticate'in ortak kısmı. user ve err kullanıcı adıyla yapılan sorgunun sonucudur
+func checkLogin(users UserRepository, user User, err error, password string) (User, error) {
+	if err != nil {
+		if err == sql.ErrNoRows {
+			checkDummyPassword(password)
+			return user, errors.New("kullanıcı bulunamadı")
+		}
+		return user, fmt.Errorf("kullanıcı verileri alınırken hata oluştu: %v", err)
+	}
+
+	match, legacy := CheckPassword(user.Password, password)
+	if !match {
+		return user, errors.New("şifre yanlış")
+	}
+
+	if legacy {
+		// Düz metin olarak saklanan eski şifreyi başarılı girişte hashleyerek güncelle
+		if err := rehashPassword(users, user.ID, password); err != nil {
+			log.Println("Şifre yeniden hashlenemedi:", err)
+		}
+	}
+
+	return user, nil
+}
+
+// Eski düz metin şifreyi hash'leyerek kaydeder
+func rehashPassword(users UserRepository, userID int, password string) error {
+	hashedPassword, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	return users.SetPassword(userID, hashedPassword)
+}
+
+func (r *SQLiteUserRepository) DeleteUnverified(before time.Time) (int, error) {
+	return r.deleteUsers("DELETE FROM user WHERE email_verified = 0 AND created_at < ? RETURNING id", before.Unix())
+}
+
+// Tek kolonlu ID sorgusunun sonuçlarını okuyup satırları kapatır
+func scanIDs(rows *sql.Rows) ([]int, error) {
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// PersonQuery.where için parametreyi args'a ekleyip SQLite yer tutucusunu döner
+func sqliteBind(args *[]interface{}) func(interface{}) string {
+	return func(value interface{}) string {
+		*args = append(*args, value)
+		return "?"
+	}
+}
+
+// SQLite'ın UNIQUE kısıtı hatasını verilen hataya çevirir
+func sqliteUniqueError(err, unique error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return unique
+	}
+
+	return err
+}
+
+// Silinen kullanıcılara ait kayıtları kullanıcı tablosu dışındaki tablolardan temizler
+func deleteUserData(tx *sql.Tx, userIDs []int) error {
+	for _, userID := range userIDs {
+		if err := deleteOneUserData(tx, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deleteOneUserData(tx *sql.Tx, userID int) error {
+	// Kullanıcının OAuth istemcileri DeleteOAuthClient'taki gibi verdikleri oturum ve token'lar iptal edilerek silinir
+	clients := "SELECT id FROM oauth_client WHERE user_id = ?"
+	now := time.Now().Unix()
+
+	for _, stmt := range []string{
+		"UPDATE user_session SET revoked_at = ? WHERE client_id IN (" + clients + ") AND revoked_at IS NULL",
+		"UPDATE refresh_token SET revoked_at = ? WHERE client_id IN (" + clients + ") AND revoked_at IS NULL",
+	} {
+		if _, err := tx.Exec(stmt, now, userID); err != nil {
+			return err
+		}
+	}
+
+	for _, stmt := range []string{
+		"DELETE FROM oauth_code WHERE client_id IN (" + clients + ")",
+		"DELETE FROM oauth_client WHERE user_id = ?",
+		"DELETE FROM oauth_code WHERE user_id = ?",
+		"DELETE FROM refresh_token WHERE user_id = ?",
+		"DELETE FROM oidc_state WHERE link_user_id = ?",
+		"DELETE FROM user_group_member WHERE user_id = ?",
+		"DELETE FROM person_share WHERE user_id = ?",
+		"DELETE FROM user_totp WHERE user_id = ?",
+		"DELETE FROM user_recovery_code WHERE user_id = ?",
+		"DELETE FROM api_key WHERE user_id = ?",
+		"DELETE FROM user_identity WHERE user_id = ?",
+		"DELETE FROM password_reset WHERE user_id = ?",
+		"DELETE FROM password_history WHERE user_id = ?",
+		"DELETE FROM user_session WHERE user_id = ?",
+		"DELETE FROM partner WHERE user_id = ?",
+	} {
+		if _, err := tx.Exec(stmt, userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+)
+
+// Süresi dolmadan iptal edilen access token'ın jti değerini kaydeder
+func RevokeToken(jti string, expiresAt time.Time) error {
+	_, err := DB.Exec("INSERT OR IGNORE INTO revoked_token (jti, expires_at, revoked_at) VALUES (?, ?, ?)", jti, expiresAt.Unix(), time.Now().Unix())
+	return err
+}
+
+// Süresi dolmamış iptal edilmiş token'ları jti -> expires_at olarak döner. Süresi dolanlar tablodan temizlenir
+func GetRevokedTokens() (map[string]int64, error) {
+	if _, err := DB.Exec("DELETE FROM revoked_token WHERE expires_at < ?", time.Now().Unix()); err != nil {
+		return nil, err
+	}
+
+	return GetRevokedTokensSince(time.Unix(0, 0))
+}
+
+// since'ten sonra iptal edilen token'ları jti -> expires_at olarak döner
+func GetRevokedTokensSince(since time.Time) (map[string]int64, error) {
+	rows, err := DB.Query("SELECT jti, expires_at FROM revoked_token WHERE revoked_at >= ?", since.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tokens := make(map[string]int64)
+
+	for rows.Next() {
+		var jti string
+		var expiresAt int64
+		if err := rows.Scan(&jti, &expiresAt); err != nil {
+			return nil, err
+		}
+
+		tokens[jti] = expiresAt
+	}
+
+	return tokens, rows.Err()
+}
+
+// Kullanıcının oturum versiyonunu artırır. Daha eski versiyonla üretilmiş tüm token'lar geçersiz olur
+func IncrementSessionVersion(userID int) (int, error) {
+	_, err := DB.Exec("INSERT INTO user_session_version (user_id, version, updated_at) VALUES (?, 1, ?) ON CONFLICT(user_id) DO UPDATE SET version = version + 1, updated_at = excluded.updated_at",
+		userID, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	return GetSessionVersion(userID)
+}
+
+func GetSessionVersion(userID int) (int, error) {
+	var version int
+	err := DB.QueryRow("SELECT COALESCE(MAX(version), 0) FROM user_session_version WHERE user_id = ?", userID).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func GetSessionVersions() (map[int]int, error) {
+	return GetSessionVersionsSince(time.Unix(0, 0))
+}
+
+// since'ten sonra değişen oturum versiyonlarını kullanıcı ID -> versiyon olarak döner
+func GetSessionVersionsSince(since time.Time) (map[int]int, error) {
+	rows, err := DB.Query("SELECT user_id, version FROM user_session_version WHERE updated_at >= ?", since.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	versions := make(map[int]int)
+
+	for rows.Next() {
+		var userID, version int
+		if err := rows.Scan(&userID, &version); err != nil {
+			return nil, err
+		}
+
+		versions[userID] = version
+	}
+
+	return versions, rows.Err()
+}
+
+// Kullanıcıya ait tüm refresh token'ları ve oturum kayıtlarını iptal eder
+func RevokeUserRefreshTokens(userID int) error {
+	now := time.Now().Unix()
+
+	if _, err := DB.Exec("UPDATE refresh_token SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", now, userID); err != nil {
+		return err
+	}
+
+	_, err := DB.Exec("UPDATE user_session SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", now, userID)
+	return err
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Migrasyonlardan önce tablolara sonradan eklenen kolonlar. Migrasyon kaydı olmayan eski veritabanlarında migrasyonlar
+// uygulanmadan önce eksik kolonlar eklenir; yeni kolonlar bu listeye değil migrasyonlara eklenmelidir
+var legacyColumns = []struct {
+	table      string
+	column     string
+	definition string
+}{
+	{"people", "owner_id", "INTEGER"},
+	// Tenant'lar eklenmeden önceki kayıtlar varsayılan tenant'a aittir
+	{"people", "tenant_id", "INTEGER NOT NULL DEFAULT 1"},
+	{"user", "tenant_id", "INTEGER NOT NULL DEFAULT 1"},
+	// Kayıt özelliğinden önce oluşturulan ve admin tarafından eklenen kullanıcılar doğrulanmış sayılır
+	{"user", "email_verified", "INTEGER NOT NULL DEFAULT 1"},
+	// Kayıt zamanı sadece doğrulanmamış hesapların süresini belirler; eski kullanıcılar doğrulanmış sayıldığından bilinmemesi sorun değildir
+	{"user", "created_at", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// Eski user tablosunun id'si AUTOINCREMENT değildir ve SQLite silinen en büyük id'yi yeni kullanıcıya tekrar verir.
+// Tablo 0001'deki tanımla yeniden oluşturulur, böylece silinen kullanıcının id'si kullanılmaz
+var legacyUserTable = []string{
+	`CREATE TABLE user_autoincrement (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		username TEXT UNIQUE,
+		email TEXT,
+		password TEXT NOT NULL,
+		role TEXT NOT NULL DEFAULT 'user',
+		tenant_id INTEGER NOT NULL DEFAULT 1,
+		email_verified INTEGER NOT NULL DEFAULT 1,
+		created_at INTEGER NOT NULL DEFAULT 0
+	)`,
+	`INSERT INTO user_autoincrement (id, username, email, password, role, tenant_id, email_verified, created_at)
+		SELECT id, username, email, password, role, tenant_id, email_verified, created_at FROM user`,
+	"DROP TABLE user",
+	"ALTER TABLE user_autoincrement RENAME TO user",
+}
+
+// Eski veritabanını migrasyonların beklediği şemaya getirir
+func upgradeLegacySchema(db *sql.DB) error {
+	for _, col := range legacyColumns {
+		if err := addColumnIfMissing(db, col.table, col.column, col.definition); err != nil {
+			return err
+		}
+	}
+
+	var userTable string
+	err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'user'").Scan(&userTable)
+	if err == sql.ErrNoRows || strings.Contains(strings.ToUpper(userTable), "AUTOINCREMENT") {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range legacyUserTable {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%q)", table))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	tableExists := false
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return err
+		}
+
+		tableExists = true
+		if name == column {
+			return nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Tablo henüz yoksa migrasyon tabloyu bütün kolonlarıyla oluşturur
+	if !tableExists {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %q ADD COLUMN %q %s", table, column, definition))
+	return err
+}
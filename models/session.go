@@ -0,0 +1,148 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("oturum bulunamadı")
+
+// Bir girişle başlayan oturum. ID, oturumdaki refresh token ailesinin ID'sidir
+type Session struct {
+	ID        string     `json:"id"`
+	UserID    int        `json:"user_id"`
+	UserAgent string     `json:"user_agent"`
+	IPAddress string     `json:"ip_address"`
+	ClientID  string     `json:"client_id,omitempty"` // OAuth istemcisine verilen yetkilerde istemcinin ID'si
+	CreatedAt time.Time  `json:"created_at"`
+	LastSeen  time.Time  `json:"last_seen"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"-"`
+}
+
+func CreateSession(session Session) error {
+	now := time.Now().Unix()
+	_, err := DB.Exec("INSERT INTO user_session (id, user_id, user_agent, ip_address, client_id, created_at, last_seen, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		session.ID, session.UserID, session.UserAgent, session.IPAddress, session.ClientID, now, now, session.ExpiresAt.Unix())
+	return err
+}
+
+// Kullanıcının iptal edilmemiş ve süresi dolmamış oturumlarını son görülme zamanına göre sıralı döner
+func GetUserSessions(userID int) ([]Session, error) {
+	rows, err := DB.Query("SELECT id, user_id, user_agent, ip_address, client_id, created_at, last_seen, expires_at FROM user_session WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY last_seen DESC",
+		userID, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sessions := make([]Session, 0)
+
+	for rows.Next() {
+		var session Session
+		var createdAt, lastSeen, expiresAt int64
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress, &session.ClientID, &createdAt, &lastSeen, &expiresAt); err != nil {
+			return nil, err
+		}
+
+		session.CreatedAt = time.Unix(createdAt, 0)
+		session.LastSeen = time.Unix(lastSeen, 0)
+		session.ExpiresAt = time.Unix(expiresAt, 0)
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Refresh token yenilendiğinde oturumun süresini uzatır
+func ExtendSession(id string, expiresAt time.Time) error {
+	_, err := DB.Exec("UPDATE user_session SET last_seen = ?, expires_at = ? WHERE id = ?", time.Now().Unix(), expiresAt.Unix(), id)
+	return err
+}
+
+func TouchSession(id string, lastSeen time.Time) error {
+	_, err := DB.Exec("UPDATE user_session SET last_seen = ? WHERE id = ? AND last_seen < ?", lastSeen.Unix(), id, lastSeen.Unix())
+	return err
+}
+
+// Kullanıcının oturumunu ve oturumdaki tüm refresh token'ları iptal eder
+func RevokeSession(id string, userID int) error {
+	result, err := DB.Exec("UPDATE user_session SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL", time.Now().Unix(), id, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := expectOneRow(result, ErrSessionNotFound); err != nil {
+		return err
+	}
+
+	return RevokeRefreshTokenFamily(id)
+}
+
+// Kullanıcının keepID dışındaki tüm aktif oturumlarını ve bu oturumların refresh token'larını tek işlemde iptal eder,
+// iptal edilen oturumların ID'lerini döner
+func RevokeOtherSessions(userID int, keepID string) ([]string, error) {
+	now := time.Now().Unix()
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query("UPDATE user_session SET revoked_at = ? WHERE user_id = ? AND id != ? AND revoked_at IS NULL AND expires_at > ? RETURNING id", now, userID, keepID, now)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if _, err := tx.Exec("UPDATE refresh_token SET revoked_at = ? WHERE user_id = ? AND family_id != ? AND revoked_at IS NULL", now, userID, keepID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	return ids, tx.Commit()
+}
+
+// since'ten sonra iptal edilen oturumları ID -> iptal zamanı olarak döner. Daha önce iptal edilen oturumların
+// access token'larının süresi dolmuş olduğundan bunların tutulmasına gerek yoktur
+func GetRevokedSessions(since time.Time) (map[string]int64, error) {
+	rows, err := DB.Query("SELECT id, revoked_at FROM user_session WHERE revoked_at >= ?", since.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sessions := make(map[string]int64)
+
+	for rows.Next() {
+		var id string
+		var revokedAt sql.NullInt64
+		if err := rows.Scan(&id, &revokedAt); err != nil {
+			return nil, err
+		}
+
+		sessions[id] = revokedAt.Int64
+	}
+
+	return sessions, rows.Err()
+}
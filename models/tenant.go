@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Tenant'lar eklenmeden önce var olan tüm kayıtların ait olduğu tenant. Bu tenant'ın admin'leri platform yöneticisidir
+const DefaultTenantID = 1
+
+// GetUserByID için tenant filtresi uygulanmaması gerektiğini belirtir. Sadece kimlik doğrulama akışlarında kullanılmalı
+const AllTenants = 0
+
+var ErrTenantExists = errors.New("tenant zaten mevcut")
+
+type Tenant struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func GetTenants() ([]Tenant, error) {
+	rows, err := DB.Query("SELECT id, name, created_at FROM tenant ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tenants := make([]Tenant, 0)
+
+	for rows.Next() {
+		var tenant Tenant
+		var createdAt int64
+		if err := rows.Scan(&tenant.ID, &tenant.Name, &createdAt); err != nil {
+			return nil, err
+		}
+
+		tenant.CreatedAt = time.Unix(createdAt, 0)
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, rows.Err()
+}
+
+// Yeni bir tenant ve bu tenant'ın ilk admin kullanıcısını oluşturur. Admin oluşturulamazsa tenant da geri alınır
+func CreateTenant(name string, admin User) (int64, int64, error) {
+	hashedPassword, err := HashPassword(admin.Password)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var count int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM tenant WHERE name = ?", name).Scan(&count); err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+
+	if count > 0 {
+		tx.Rollback()
+		return 0, 0, ErrTenantExists
+	}
+
+	result, err := tx.Exec("INSERT INTO tenant (name, created_at) VALUES (?, ?)", name, time.Now().Unix())
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+
+	tenantID, err := result.LastInsertId()
+	if err != nil {
+		tx.Rollback()
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	// Kullanıcı tablosu PostgreSQL'de olabileceği için admin tenant transaction'ı dışında eklenir
+	admin.Role = "admin"
+	admin.TenantID = int(tenantID)
+	userID, err := Users().Insert(admin, hashedPassword, true)
+	if err != nil {
+		DB.Exec("DELETE FROM tenant WHERE id = ?", tenantID)
+		return 0, 0, err
+	}
+
+	return tenantID, userID, nil
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrTOTPNotFound = errors.New("2FA kaydı bulunamadı")
+
+// Kullanıcının TOTP (RFC 6238) kaydı. Enabled false ise kayıt onay bekliyordur
+type UserTOTP struct {
+	UserID       int
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+}
+
+func GetUserTOTP(userID int) (UserTOTP, error) {
+	var totp UserTOTP
+	err := DB.QueryRow("SELECT user_id, secret, enabled, last_used_step FROM user_totp WHERE user_id = ?", userID).
+		Scan(&totp.UserID, &totp.Secret, &totp.Enabled, &totp.LastUsedStep)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return UserTOTP{}, ErrTOTPNotFound
+		}
+		return UserTOTP{}, err
+	}
+
+	return totp, nil
+}
+
+// Onaylanmamış yeni bir TOTP anahtarı kaydeder. Etkin bir kayıt varsa üzerine yazılmaz
+func SavePendingTOTP(userID int, secret string) error {
+	result, err := DB.Exec(`INSERT INTO user_totp (user_id, secret, enabled, last_used_step) VALUES (?, ?, 0, 0)
+		ON CONFLICT(user_id) DO UPDATE SET secret = excluded.secret, last_used_step = 0 WHERE enabled = 0`, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("2FA zaten etkin")
+	}
+
+	return nil
+}
+
+// Kullanılan zaman adımını kaydeder. Aynı veya daha eski adım daha önce kullanıldıysa false döner (kod tekrar kullanılamaz)
+func UseTOTPStep(userID int, step int64) (bool, error) {
+	result, err := DB.Exec("UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?", step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+// TOTP kaydını etkinleştirir ve kullanıcının kurtarma kodlarını verilen hash'lerle değiştirir
+func EnableTOTP(userID int, recoveryCodeHashes []string) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE user_totp SET enabled = 1 WHERE user_id = ?", userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM user_recovery_code WHERE user_id = ?", userID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		if _, err := tx.Exec("INSERT INTO user_recovery_code (user_id, code_hash) VALUES (?, ?)", userID, hash); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func DisableTOTP(userID int) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range []string{"DELETE FROM user_totp WHERE user_id = ?", "DELETE FROM user_recovery_code WHERE user_id = ?"} {
+		if _, err := tx.Exec(stmt, userID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Kullanılmamış bir kurtarma kodunu kullanıldı olarak işaretler. Kod geçersizse veya daha önce kullanıldıysa false döner
+func UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	result, err := DB.Exec("UPDATE user_recovery_code SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL", time.Now().Unix(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func IsTwoFactorRequired(role string) (bool, error) {
+	var required bool
+	err := DB.QueryRow("SELECT COALESCE(MAX(require_2fa), 0) FROM role WHERE name = ?", role).Scan(&required)
+	if err != nil {
+		return false, err
+	}
+
+	return required, nil
+}
+
+func SetTwoFactorRequired(role string, required bool) error {
+	result, err := DB.Exec("UPDATE role SET require_2fa = ? WHERE name = ?", required, role)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrRoleNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+// Kişinin sahibi (veya admin) değilse isteği sonlandırır. Kişi ID'si geçerliyse ve işlem yapılabilirse ID'yi döner
+func managedPersonID(c *gin.Context, operation string) (int, bool) {
+	personID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"HATA": "GEÇERSİZ ID !"})
+		crudOperations.WithLabelValues(operation, "invalid_id").Inc()
+		return 0, false
+	}
+
+	canManage, err := models.CanManagePerson(personID, personScope(c))
+	if err != nil {
+		if err == models.ErrPersonNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Hata": "Kayıt bulunamadı"})
+			crudOperations.WithLabelValues(operation, "not_found").Inc()
+			return 0, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Kişi aranırken bir hata oluştu"})
+		crudOperations.WithLabelValues(operation, "error").Inc()
+		return 0, false
+	}
+
+	if !canManage {
+		// Başkasına ait kayıtların varlığı da gizlenir
+		c.JSON(http.StatusNotFound, gin.H{"Hata": "Kayıt bulunamadı"})
+		crudOperations.WithLabelValues(operation, "not_found").Inc()
+		return 0, false
+	}
+
+	return personID, true
+}
+
+// @Summary List shares of a person
+// @Description Lists the users and groups a person is shared with. Only the owner or an admin can see them
+// @Tags person
+// @Produce json
+// @Param id path int true "Person ID"
+// @Success 200 {object} models.PersonShare
+// @Router /api/v1/person/{id}/share [get]
+func getPersonShares(c *gin.Context) {
+	personID, ok := managedPersonID(c, "getPersonShares")
+	if !ok {
+		return
+	}
+
+	shares, err := models.GetPersonShares(personID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Paylaşımlar alınamadı"})
+		crudOperations.WithLabelValues("getPersonShares", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": shares})
+	crudOperations.WithLabelValues("getPersonShares", "success").Inc()
+}
+
+// @Summary Share a person
+// @Description Shares a person with a user or a group. Set can_edit to allow updates. Only the owner or an admin can share
+// @Tags person
+// @Accept json
+// @Produce json
+// @Param id path int true "Person ID"
+// @Param share body models.PersonShare true "Share target (either user_id or group_id)"
+// @Router /api/v1/person/{id}/share [post]
+func sharePerson(c *gin.Context) {
+	personID, ok := managedPersonID(c, "sharePerson")
+	if !ok {
+		return
+	}
+
+	var share models.PersonShare
+	if err := c.ShouldBindJSON(&share); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Hata": err.Error()})
+		crudOperations.WithLabelValues("sharePerson", "bad_request").Inc()
+		return
+	}
+
+	if (share.UserID == nil) == (share.GroupID == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"Hata": "user_id veya group_id alanlarından sadece biri verilmelidir"})
+		crudOperations.WithLabelValues("sharePerson", "invalid_data").Inc()
+		return
+	}
+
+	if share.UserID != nil {
+		if _, err := models.GetUserByID(*share.UserID, tenantID(c)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"Hata": "Kullanıcı Bulunamadı"})
+			crudOperations.WithLabelValues("sharePerson", "invalid_data").Inc()
+			return
+		}
+	} else {
+		exists, err := models.GroupExists(*share.GroupID, tenantID(c))
+		if err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"Hata": "Grup bulunamadı"})
+			crudOperations.WithLabelValues("sharePerson", "invalid_data").Inc()
+			return
+		}
+	}
+
+	share.PersonID = personID
+
+	id, err := models.SharePerson(share)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Kişi paylaşılamadı"})
+		crudOperations.WithLabelValues("sharePerson", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kişi başarıyla paylaşıldı", "id": id})
+	crudOperations.WithLabelValues("sharePerson", "success").Inc()
+}
+
+// @Summary Remove a share
+// @Description Stops sharing a person with a user or group. Only the owner or an admin can remove shares
+// @Tags person
+// @Produce json
+// @Param id path int true "Person ID"
+// @Param shareId path int true "Share ID"
+// @Router /api/v1/person/{id}/share/{shareId} [delete]
+func deletePersonShare(c *gin.Context) {
+	personID, ok := managedPersonID(c, "deletePersonShare")
+	if !ok {
+		return
+	}
+
+	shareID, err := strconv.Atoi(c.Param("shareId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"HATA": "GEÇERSİZ ID !"})
+		crudOperations.WithLabelValues("deletePersonShare", "invalid_id").Inc()
+		return
+	}
+
+	if err := models.DeletePersonShare(personID, shareID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"Hata": "Paylaşım bulunamadı"})
+		crudOperations.WithLabelValues("deletePersonShare", "not_found").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Paylaşım kaldırıldı"})
+	crudOperations.WithLabelValues("deletePersonShare", "success").Inc()
+}
+
+// @Summary List groups
+// @Description Lists user groups with their member IDs
+// @Tags group
+// @Produce json
+// @Success 200 {object} models.Group
+// @Router /api/v1/group [get]
+func getGroups(c *gin.Context) {
+	groups, err := models.GetGroups(tenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Gruplar alınamadı"})
+		crudOperations.WithLabelValues("getGroups", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": groups})
+	crudOperations.WithLabelValues("getGroups", "success").Inc()
+}
+
+// @Summary Create a group
+// @Description Creates a user group that persons can be shared with
+// @Tags group
+// @Accept json
+// @Produce json
+// @Param group body models.Group true "New group"
+// @Router /api/v1/group [post]
+func addGroup(c *gin.Context) {
+	var group models.Group
+	if err := c.ShouldBindJSON(&group); err != nil || group.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"Hata": "Geçersiz giriş verisi"})
+		crudOperations.WithLabelValues("addGroup", "bad_request").Inc()
+		return
+	}
+
+	id, err := models.CreateGroup(group.Name, tenantID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Grup eklenemedi"})
+		crudOperations.WithLabelValues("addGroup", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Grup başarıyla eklendi", "id": id})
+	crudOperations.WithLabelValues("addGroup", "success").Inc()
+}
+
+// @Summary Delete a group
+// @Description Deletes a group, its memberships and all shares made with it
+// @Tags group
+// @Produce json
+// @Param id path int true "Group ID"
+// @Router /api/v1/group/{id} [delete]
+func deleteGroup(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"HATA": "GEÇERSİZ ID !"})
+		crudOperations.WithLabelValues("deleteGroup", "invalid_id").Inc()
+		return
+	}
+
+	if err := models.DeleteGroup(groupID, tenantID(c)); err != nil {
+		if err == models.ErrGroupNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Hata": "Grup bulunamadı"})
+			crudOperations.WithLabelValues("deleteGroup", "not_found").Inc()
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Grup silinemedi"})
+		crudOperations.WithLabelValues("deleteGroup", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Grup başarıyla silindi"})
+	crudOperations.WithLabelValues("deleteGroup", "success").Inc()
+}
+
+type groupMemberRequest struct {
+	UserID int `json:"user_id"`
+}
+
+// @Summary Add a group member
+// @Description Adds a user to a group
+// @Tags group
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param member body groupMemberRequest true "User to add"
+// @Router /api/v1/group/{id}/member [post]
+func addGroupMember(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"HATA": "GEÇERSİZ ID !"})
+		crudOperations.WithLabelValues("addGroupMember", "invalid_id").Inc()
+		return
+	}
+
+	var req groupMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Hata": err.Error()})
+		crudOperations.WithLabelValues("addGroupMember", "bad_request").Inc()
+		return
+	}
+
+	if _, err := models.GetUserByID(req.UserID, tenantID(c)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Hata": "Kullanıcı Bulunamadı"})
+		crudOperations.WithLabelValues("addGroupMember", "invalid_data").Inc()
+		return
+	}
+
+	if err := models.AddGroupMember(groupID, req.UserID, tenantID(c)); err != nil {
+		if err == models.ErrGroupNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"Hata": "Grup bulunamadı"})
+			crudOperations.WithLabelValues("addGroupMember", "not_found").Inc()
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Üye eklenemedi"})
+		crudOperations.WithLabelValues("addGroupMember", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Üye başarıyla eklendi"})
+	crudOperations.WithLabelValues("addGroupMember", "success").Inc()
+}
+
+// @Summary Remove a group member
+// @Description Removes a user from a group
+// @Tags group
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param userId path int true "User ID"
+// @Router /api/v1/group/{id}/member/{userId} [delete]
+func removeGroupMember(c *gin.Context) {
+	groupID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"HATA": "GEÇERSİZ ID !"})
+		crudOperations.WithLabelValues("removeGroupMember", "invalid_id").Inc()
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"HATA": "GEÇERSİZ ID !"})
+		crudOperations.WithLabelValues("removeGroupMember", "invalid_id").Inc()
+		return
+	}
+
+	if err := models.RemoveGroupMember(groupID, userID, tenantID(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Üye çıkarılamadı"})
+		crudOperations.WithLabelValues("removeGroupMember", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Üye gruptan çıkarıldı"})
+	crudOperations.WithLabelValues("removeGroupMember", "success").Inc()
+}
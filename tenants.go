@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"example.com/webservice/models"
+)
+
+type tenantRequest struct {
+	Name  string      `json:"name"`
+	Admin models.User `json:"admin"`
+}
+
+// @Summary List tenants
+// @Description Lists all tenants (platform admin only)
+// @Tags tenant
+// @Produce json
+// @Success 200 {object} models.Tenant
+// @Router /api/v1/tenant [get]
+func getTenants(c *gin.Context) {
+	tenants, err := models.GetTenants()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Tenant'lar alınamadı"})
+		crudOperations.WithLabelValues("getTenants", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": tenants})
+	crudOperations.WithLabelValues("getTenants", "success").Inc()
+}
+
+// @Summary Provision a tenant
+// @Description Creates a new tenant together with its first admin user (platform admin only). The admin can then manage users, groups and persons of the new tenant only
+// @Tags tenant
+// @Accept json
+// @Produce json
+// @Param tenant body tenantRequest true "Tenant name and initial admin"
+// @Router /api/v1/tenant [post]
+func addTenant(c *gin.Context) {
+	var req tenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"Hata": err.Error()})
+		crudOperations.WithLabelValues("addTenant", "bad_request").Inc()
+		return
+	}
+
+	if req.Name == "" || req.Admin.Username == "" || req.Admin.Password == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"Hata": "Geçersiz giriş verisi"})
+		crudOperations.WithLabelValues("addTenant", "invalid_data").Inc()
+		return
+	}
+
+	tenantID, adminID, err := models.CreateTenant(req.Name, req.Admin)
+	if err != nil {
+		if err == models.ErrTenantExists {
+			c.JSON(http.StatusBadRequest, gin.H{"Hata": "Bu isimde bir tenant zaten mevcut"})
+			crudOperations.WithLabelValues("addTenant", "failed").Inc()
+			return
+		}
+		if err == models.ErrUsernameTaken {
+			c.JSON(http.StatusConflict, gin.H{"Hata": "Bu kullanıcı adı zaten alınmış"})
+			crudOperations.WithLabelValues("addTenant", "failed").Inc()
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"Hata": "Tenant oluşturulamadı"})
+		crudOperations.WithLabelValues("addTenant", "error").Inc()
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tenant başarıyla oluşturuldu", "id": tenantID, "admin_id": adminID})
+	crudOperations.WithLabelValues("addTenant", "success").Inc()
+}